@@ -21,23 +21,89 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	NedoVault_Authorize_FullMethodName             = "/api.NedoVault/Authorize"
+	NedoVault_Register_FullMethodName              = "/api.NedoVault/Register"
+	NedoVault_Login_FullMethodName                 = "/api.NedoVault/Login"
+	NedoVault_RefreshToken_FullMethodName          = "/api.NedoVault/RefreshToken"
+	NedoVault_VerifyTOTP_FullMethodName            = "/api.NedoVault/VerifyTOTP"
+	NedoVault_EnrollTOTP_FullMethodName            = "/api.NedoVault/EnrollTOTP"
+	NedoVault_ConfirmTOTP_FullMethodName           = "/api.NedoVault/ConfirmTOTP"
+	NedoVault_DisableTOTP_FullMethodName           = "/api.NedoVault/DisableTOTP"
+	NedoVault_ChangePassword_FullMethodName        = "/api.NedoVault/ChangePassword"
+	NedoVault_DeleteAccount_FullMethodName         = "/api.NedoVault/DeleteAccount"
+	NedoVault_ListSessions_FullMethodName          = "/api.NedoVault/ListSessions"
+	NedoVault_RevokeSession_FullMethodName         = "/api.NedoVault/RevokeSession"
+	NedoVault_GetKeyCheck_FullMethodName           = "/api.NedoVault/GetKeyCheck"
+	NedoVault_SetKeyCheck_FullMethodName           = "/api.NedoVault/SetKeyCheck"
+	NedoVault_Logout_FullMethodName                = "/api.NedoVault/Logout"
+	NedoVault_LogoutAll_FullMethodName             = "/api.NedoVault/LogoutAll"
 	NedoVault_AddSecret_FullMethodName             = "/api.NedoVault/AddSecret"
+	NedoVault_UpdateSecret_FullMethodName          = "/api.NedoVault/UpdateSecret"
 	NedoVault_DeleteSecret_FullMethodName          = "/api.NedoVault/DeleteSecret"
 	NedoVault_ListSecretsMeta_FullMethodName       = "/api.NedoVault/ListSecretsMeta"
 	NedoVault_ListSecretsMetaStream_FullMethodName = "/api.NedoVault/ListSecretsMetaStream"
 	NedoVault_GetSecret_FullMethodName             = "/api.NedoVault/GetSecret"
+	NedoVault_UploadSecret_FullMethodName          = "/api.NedoVault/UploadSecret"
+	NedoVault_DownloadSecret_FullMethodName        = "/api.NedoVault/DownloadSecret"
+	NedoVault_ListSecretVersions_FullMethodName    = "/api.NedoVault/ListSecretVersions"
+	NedoVault_GetSecretVersion_FullMethodName      = "/api.NedoVault/GetSecretVersion"
+	NedoVault_RestoreSecretVersion_FullMethodName  = "/api.NedoVault/RestoreSecretVersion"
+	NedoVault_ListTrash_FullMethodName             = "/api.NedoVault/ListTrash"
+	NedoVault_RestoreSecret_FullMethodName         = "/api.NedoVault/RestoreSecret"
+	NedoVault_PurgeSecret_FullMethodName           = "/api.NedoVault/PurgeSecret"
+	NedoVault_Sync_FullMethodName                  = "/api.NedoVault/Sync"
+	NedoVault_SyncStream_FullMethodName            = "/api.NedoVault/SyncStream"
 )
 
 // NedoVaultClient is the client API for NedoVault service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type NedoVaultClient interface {
+	// Deprecated: same as Login, kept for older clients
 	Authorize(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	Register(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	Login(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	// RefreshToken issues new tokens, the passed refresh token is invalidated.
+	// Reuse of the invalidated refresh token revokes all tokens of the login session
+	RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	// VerifyTOTP completes login of the account with two-factor authentication
+	VerifyTOTP(ctx context.Context, in *VerifyTOTPRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	// EnrollTOTP generates a new seed and recovery codes, they are required on login after ConfirmTOTP
+	EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, opts ...grpc.CallOption) (*EnrollTOTPResponse, error)
+	ConfirmTOTP(ctx context.Context, in *ConfirmTOTPRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DisableTOTP(ctx context.Context, in *DisableTOTPRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ChangePassword replaces the account password and revokes all tokens of the user, including the current one
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// DeleteAccount removes the user with all secrets
+	DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListSessions returns active login sessions of the user, ordered by the last request
+	ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	// RevokeSession revokes all tokens of the session, the device has to log in again
+	RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetKeyCheck(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*KeyCheck, error)
+	// SetKeyCheck saves the key check of the account, it is set once and AlreadyExists is returned afterwards
+	SetKeyCheck(ctx context.Context, in *KeyCheck, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// revokes token of the request
+	Logout(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// revokes all tokens of the user
+	LogoutAll(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	AddSecret(ctx context.Context, in *AddSecretRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	UpdateSecret(ctx context.Context, in *UpdateSecretRequest, opts ...grpc.CallOption) (*SecretMeta, error)
 	DeleteSecret(ctx context.Context, in *DeleteSecretRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	ListSecretsMeta(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSecretsMetaResponse, error)
 	ListSecretsMetaStream(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListSecretsMetaResponse], error)
 	GetSecret(ctx context.Context, in *GetSecretRequest, opts ...grpc.CallOption) (*GetSecretResponse, error)
+	UploadSecret(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadSecretRequest, emptypb.Empty], error)
+	DownloadSecret(ctx context.Context, in *DownloadSecretRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadSecretResponse], error)
+	ListSecretVersions(ctx context.Context, in *ListSecretVersionsRequest, opts ...grpc.CallOption) (*ListSecretVersionsResponse, error)
+	GetSecretVersion(ctx context.Context, in *GetSecretVersionRequest, opts ...grpc.CallOption) (*GetSecretResponse, error)
+	RestoreSecretVersion(ctx context.Context, in *RestoreSecretVersionRequest, opts ...grpc.CallOption) (*SecretMeta, error)
+	ListTrash(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSecretsMetaResponse, error)
+	RestoreSecret(ctx context.Context, in *RestoreSecretRequest, opts ...grpc.CallOption) (*SecretMeta, error)
+	PurgeSecret(ctx context.Context, in *PurgeSecretRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Sync returns changes of the secrets committed after since_seq
+	Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*SyncResponse, error)
+	// SyncStream sends changes after since_seq and then pushes new ones as they are committed
+	SyncStream(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SyncResponse], error)
 }
 
 type nedoVaultClient struct {
@@ -58,6 +124,156 @@ func (c *nedoVaultClient) Authorize(ctx context.Context, in *AuthRequest, opts .
 	return out, nil
 }
 
+func (c *nedoVaultClient) Register(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthResponse)
+	err := c.cc.Invoke(ctx, NedoVault_Register_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) Login(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthResponse)
+	err := c.cc.Invoke(ctx, NedoVault_Login_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*AuthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthResponse)
+	err := c.cc.Invoke(ctx, NedoVault_RefreshToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) VerifyTOTP(ctx context.Context, in *VerifyTOTPRequest, opts ...grpc.CallOption) (*AuthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthResponse)
+	err := c.cc.Invoke(ctx, NedoVault_VerifyTOTP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, opts ...grpc.CallOption) (*EnrollTOTPResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnrollTOTPResponse)
+	err := c.cc.Invoke(ctx, NedoVault_EnrollTOTP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) ConfirmTOTP(ctx context.Context, in *ConfirmTOTPRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, NedoVault_ConfirmTOTP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) DisableTOTP(ctx context.Context, in *DisableTOTPRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, NedoVault_DisableTOTP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, NedoVault_ChangePassword_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, NedoVault_DeleteAccount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, NedoVault_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, NedoVault_RevokeSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) GetKeyCheck(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*KeyCheck, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KeyCheck)
+	err := c.cc.Invoke(ctx, NedoVault_GetKeyCheck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) SetKeyCheck(ctx context.Context, in *KeyCheck, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, NedoVault_SetKeyCheck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) Logout(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, NedoVault_Logout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) LogoutAll(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, NedoVault_LogoutAll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *nedoVaultClient) AddSecret(ctx context.Context, in *AddSecretRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -68,6 +284,16 @@ func (c *nedoVaultClient) AddSecret(ctx context.Context, in *AddSecretRequest, o
 	return out, nil
 }
 
+func (c *nedoVaultClient) UpdateSecret(ctx context.Context, in *UpdateSecretRequest, opts ...grpc.CallOption) (*SecretMeta, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SecretMeta)
+	err := c.cc.Invoke(ctx, NedoVault_UpdateSecret_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *nedoVaultClient) DeleteSecret(ctx context.Context, in *DeleteSecretRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -117,16 +343,177 @@ func (c *nedoVaultClient) GetSecret(ctx context.Context, in *GetSecretRequest, o
 	return out, nil
 }
 
+func (c *nedoVaultClient) UploadSecret(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadSecretRequest, emptypb.Empty], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NedoVault_ServiceDesc.Streams[1], NedoVault_UploadSecret_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadSecretRequest, emptypb.Empty]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NedoVault_UploadSecretClient = grpc.ClientStreamingClient[UploadSecretRequest, emptypb.Empty]
+
+func (c *nedoVaultClient) DownloadSecret(ctx context.Context, in *DownloadSecretRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadSecretResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NedoVault_ServiceDesc.Streams[2], NedoVault_DownloadSecret_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadSecretRequest, DownloadSecretResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NedoVault_DownloadSecretClient = grpc.ServerStreamingClient[DownloadSecretResponse]
+
+func (c *nedoVaultClient) ListSecretVersions(ctx context.Context, in *ListSecretVersionsRequest, opts ...grpc.CallOption) (*ListSecretVersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSecretVersionsResponse)
+	err := c.cc.Invoke(ctx, NedoVault_ListSecretVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) GetSecretVersion(ctx context.Context, in *GetSecretVersionRequest, opts ...grpc.CallOption) (*GetSecretResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSecretResponse)
+	err := c.cc.Invoke(ctx, NedoVault_GetSecretVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) RestoreSecretVersion(ctx context.Context, in *RestoreSecretVersionRequest, opts ...grpc.CallOption) (*SecretMeta, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SecretMeta)
+	err := c.cc.Invoke(ctx, NedoVault_RestoreSecretVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) ListTrash(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSecretsMetaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSecretsMetaResponse)
+	err := c.cc.Invoke(ctx, NedoVault_ListTrash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) RestoreSecret(ctx context.Context, in *RestoreSecretRequest, opts ...grpc.CallOption) (*SecretMeta, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SecretMeta)
+	err := c.cc.Invoke(ctx, NedoVault_RestoreSecret_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) PurgeSecret(ctx context.Context, in *PurgeSecretRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, NedoVault_PurgeSecret_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*SyncResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncResponse)
+	err := c.cc.Invoke(ctx, NedoVault_Sync_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nedoVaultClient) SyncStream(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SyncResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NedoVault_ServiceDesc.Streams[3], NedoVault_SyncStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SyncRequest, SyncResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NedoVault_SyncStreamClient = grpc.ServerStreamingClient[SyncResponse]
+
 // NedoVaultServer is the server API for NedoVault service.
 // All implementations must embed UnimplementedNedoVaultServer
 // for forward compatibility.
 type NedoVaultServer interface {
+	// Deprecated: same as Login, kept for older clients
 	Authorize(context.Context, *AuthRequest) (*AuthResponse, error)
+	Register(context.Context, *AuthRequest) (*AuthResponse, error)
+	Login(context.Context, *AuthRequest) (*AuthResponse, error)
+	// RefreshToken issues new tokens, the passed refresh token is invalidated.
+	// Reuse of the invalidated refresh token revokes all tokens of the login session
+	RefreshToken(context.Context, *RefreshTokenRequest) (*AuthResponse, error)
+	// VerifyTOTP completes login of the account with two-factor authentication
+	VerifyTOTP(context.Context, *VerifyTOTPRequest) (*AuthResponse, error)
+	// EnrollTOTP generates a new seed and recovery codes, they are required on login after ConfirmTOTP
+	EnrollTOTP(context.Context, *EnrollTOTPRequest) (*EnrollTOTPResponse, error)
+	ConfirmTOTP(context.Context, *ConfirmTOTPRequest) (*emptypb.Empty, error)
+	DisableTOTP(context.Context, *DisableTOTPRequest) (*emptypb.Empty, error)
+	// ChangePassword replaces the account password and revokes all tokens of the user, including the current one
+	ChangePassword(context.Context, *ChangePasswordRequest) (*emptypb.Empty, error)
+	// DeleteAccount removes the user with all secrets
+	DeleteAccount(context.Context, *DeleteAccountRequest) (*emptypb.Empty, error)
+	// ListSessions returns active login sessions of the user, ordered by the last request
+	ListSessions(context.Context, *emptypb.Empty) (*ListSessionsResponse, error)
+	// RevokeSession revokes all tokens of the session, the device has to log in again
+	RevokeSession(context.Context, *RevokeSessionRequest) (*emptypb.Empty, error)
+	GetKeyCheck(context.Context, *emptypb.Empty) (*KeyCheck, error)
+	// SetKeyCheck saves the key check of the account, it is set once and AlreadyExists is returned afterwards
+	SetKeyCheck(context.Context, *KeyCheck) (*emptypb.Empty, error)
+	// revokes token of the request
+	Logout(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// revokes all tokens of the user
+	LogoutAll(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	AddSecret(context.Context, *AddSecretRequest) (*emptypb.Empty, error)
+	UpdateSecret(context.Context, *UpdateSecretRequest) (*SecretMeta, error)
 	DeleteSecret(context.Context, *DeleteSecretRequest) (*emptypb.Empty, error)
 	ListSecretsMeta(context.Context, *emptypb.Empty) (*ListSecretsMetaResponse, error)
 	ListSecretsMetaStream(*emptypb.Empty, grpc.ServerStreamingServer[ListSecretsMetaResponse]) error
 	GetSecret(context.Context, *GetSecretRequest) (*GetSecretResponse, error)
+	UploadSecret(grpc.ClientStreamingServer[UploadSecretRequest, emptypb.Empty]) error
+	DownloadSecret(*DownloadSecretRequest, grpc.ServerStreamingServer[DownloadSecretResponse]) error
+	ListSecretVersions(context.Context, *ListSecretVersionsRequest) (*ListSecretVersionsResponse, error)
+	GetSecretVersion(context.Context, *GetSecretVersionRequest) (*GetSecretResponse, error)
+	RestoreSecretVersion(context.Context, *RestoreSecretVersionRequest) (*SecretMeta, error)
+	ListTrash(context.Context, *emptypb.Empty) (*ListSecretsMetaResponse, error)
+	RestoreSecret(context.Context, *RestoreSecretRequest) (*SecretMeta, error)
+	PurgeSecret(context.Context, *PurgeSecretRequest) (*emptypb.Empty, error)
+	// Sync returns changes of the secrets committed after since_seq
+	Sync(context.Context, *SyncRequest) (*SyncResponse, error)
+	// SyncStream sends changes after since_seq and then pushes new ones as they are committed
+	SyncStream(*SyncRequest, grpc.ServerStreamingServer[SyncResponse]) error
 	mustEmbedUnimplementedNedoVaultServer()
 }
 
@@ -140,9 +527,57 @@ type UnimplementedNedoVaultServer struct{}
 func (UnimplementedNedoVaultServer) Authorize(context.Context, *AuthRequest) (*AuthResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Authorize not implemented")
 }
+func (UnimplementedNedoVaultServer) Register(context.Context, *AuthRequest) (*AuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedNedoVaultServer) Login(context.Context, *AuthRequest) (*AuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedNedoVaultServer) RefreshToken(context.Context, *RefreshTokenRequest) (*AuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshToken not implemented")
+}
+func (UnimplementedNedoVaultServer) VerifyTOTP(context.Context, *VerifyTOTPRequest) (*AuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyTOTP not implemented")
+}
+func (UnimplementedNedoVaultServer) EnrollTOTP(context.Context, *EnrollTOTPRequest) (*EnrollTOTPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollTOTP not implemented")
+}
+func (UnimplementedNedoVaultServer) ConfirmTOTP(context.Context, *ConfirmTOTPRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTOTP not implemented")
+}
+func (UnimplementedNedoVaultServer) DisableTOTP(context.Context, *DisableTOTPRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableTOTP not implemented")
+}
+func (UnimplementedNedoVaultServer) ChangePassword(context.Context, *ChangePasswordRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (UnimplementedNedoVaultServer) DeleteAccount(context.Context, *DeleteAccountRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAccount not implemented")
+}
+func (UnimplementedNedoVaultServer) ListSessions(context.Context, *emptypb.Empty) (*ListSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedNedoVaultServer) RevokeSession(context.Context, *RevokeSessionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedNedoVaultServer) GetKeyCheck(context.Context, *emptypb.Empty) (*KeyCheck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKeyCheck not implemented")
+}
+func (UnimplementedNedoVaultServer) SetKeyCheck(context.Context, *KeyCheck) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetKeyCheck not implemented")
+}
+func (UnimplementedNedoVaultServer) Logout(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
+}
+func (UnimplementedNedoVaultServer) LogoutAll(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LogoutAll not implemented")
+}
 func (UnimplementedNedoVaultServer) AddSecret(context.Context, *AddSecretRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddSecret not implemented")
 }
+func (UnimplementedNedoVaultServer) UpdateSecret(context.Context, *UpdateSecretRequest) (*SecretMeta, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSecret not implemented")
+}
 func (UnimplementedNedoVaultServer) DeleteSecret(context.Context, *DeleteSecretRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteSecret not implemented")
 }
@@ -155,6 +590,36 @@ func (UnimplementedNedoVaultServer) ListSecretsMetaStream(*emptypb.Empty, grpc.S
 func (UnimplementedNedoVaultServer) GetSecret(context.Context, *GetSecretRequest) (*GetSecretResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSecret not implemented")
 }
+func (UnimplementedNedoVaultServer) UploadSecret(grpc.ClientStreamingServer[UploadSecretRequest, emptypb.Empty]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadSecret not implemented")
+}
+func (UnimplementedNedoVaultServer) DownloadSecret(*DownloadSecretRequest, grpc.ServerStreamingServer[DownloadSecretResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadSecret not implemented")
+}
+func (UnimplementedNedoVaultServer) ListSecretVersions(context.Context, *ListSecretVersionsRequest) (*ListSecretVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSecretVersions not implemented")
+}
+func (UnimplementedNedoVaultServer) GetSecretVersion(context.Context, *GetSecretVersionRequest) (*GetSecretResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSecretVersion not implemented")
+}
+func (UnimplementedNedoVaultServer) RestoreSecretVersion(context.Context, *RestoreSecretVersionRequest) (*SecretMeta, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSecretVersion not implemented")
+}
+func (UnimplementedNedoVaultServer) ListTrash(context.Context, *emptypb.Empty) (*ListSecretsMetaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (UnimplementedNedoVaultServer) RestoreSecret(context.Context, *RestoreSecretRequest) (*SecretMeta, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSecret not implemented")
+}
+func (UnimplementedNedoVaultServer) PurgeSecret(context.Context, *PurgeSecretRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeSecret not implemented")
+}
+func (UnimplementedNedoVaultServer) Sync(context.Context, *SyncRequest) (*SyncResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sync not implemented")
+}
+func (UnimplementedNedoVaultServer) SyncStream(*SyncRequest, grpc.ServerStreamingServer[SyncResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method SyncStream not implemented")
+}
 func (UnimplementedNedoVaultServer) mustEmbedUnimplementedNedoVaultServer() {}
 func (UnimplementedNedoVaultServer) testEmbeddedByValue()                   {}
 
@@ -194,49 +659,337 @@ func _NedoVault_Authorize_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
-func _NedoVault_AddSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AddSecretRequest)
+func _NedoVault_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(NedoVaultServer).AddSecret(ctx, in)
+		return srv.(NedoVaultServer).Register(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: NedoVault_AddSecret_FullMethodName,
+		FullMethod: NedoVault_Register_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(NedoVaultServer).AddSecret(ctx, req.(*AddSecretRequest))
+		return srv.(NedoVaultServer).Register(ctx, req.(*AuthRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _NedoVault_DeleteSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteSecretRequest)
+func _NedoVault_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(NedoVaultServer).DeleteSecret(ctx, in)
+		return srv.(NedoVaultServer).Login(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: NedoVault_DeleteSecret_FullMethodName,
+		FullMethod: NedoVault_Login_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(NedoVaultServer).DeleteSecret(ctx, req.(*DeleteSecretRequest))
+		return srv.(NedoVaultServer).Login(ctx, req.(*AuthRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _NedoVault_ListSecretsMeta_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+func _NedoVault_RefreshToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshTokenRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(NedoVaultServer).ListSecretsMeta(ctx, in)
+		return srv.(NedoVaultServer).RefreshToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_RefreshToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).RefreshToken(ctx, req.(*RefreshTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_VerifyTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).VerifyTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_VerifyTOTP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).VerifyTOTP(ctx, req.(*VerifyTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_EnrollTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).EnrollTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_EnrollTOTP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).EnrollTOTP(ctx, req.(*EnrollTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_ConfirmTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).ConfirmTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_ConfirmTOTP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).ConfirmTOTP(ctx, req.(*ConfirmTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_DisableTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).DisableTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_DisableTOTP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).DisableTOTP(ctx, req.(*DisableTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_ChangePassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).ChangePassword(ctx, req.(*ChangePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_DeleteAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).DeleteAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_DeleteAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).DeleteAccount(ctx, req.(*DeleteAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).ListSessions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_RevokeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).RevokeSession(ctx, req.(*RevokeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_GetKeyCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).GetKeyCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_GetKeyCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).GetKeyCheck(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_SetKeyCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyCheck)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).SetKeyCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_SetKeyCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).SetKeyCheck(ctx, req.(*KeyCheck))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_Logout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).Logout(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_LogoutAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).LogoutAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_LogoutAll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).LogoutAll(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_AddSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).AddSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_AddSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).AddSecret(ctx, req.(*AddSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_UpdateSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).UpdateSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_UpdateSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).UpdateSecret(ctx, req.(*UpdateSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_DeleteSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).DeleteSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_DeleteSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).DeleteSecret(ctx, req.(*DeleteSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_ListSecretsMeta_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).ListSecretsMeta(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
@@ -277,6 +1030,161 @@ func _NedoVault_GetSecret_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NedoVault_UploadSecret_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NedoVaultServer).UploadSecret(&grpc.GenericServerStream[UploadSecretRequest, emptypb.Empty]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NedoVault_UploadSecretServer = grpc.ClientStreamingServer[UploadSecretRequest, emptypb.Empty]
+
+func _NedoVault_DownloadSecret_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadSecretRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NedoVaultServer).DownloadSecret(m, &grpc.GenericServerStream[DownloadSecretRequest, DownloadSecretResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NedoVault_DownloadSecretServer = grpc.ServerStreamingServer[DownloadSecretResponse]
+
+func _NedoVault_ListSecretVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSecretVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).ListSecretVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_ListSecretVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).ListSecretVersions(ctx, req.(*ListSecretVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_GetSecretVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSecretVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).GetSecretVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_GetSecretVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).GetSecretVersion(ctx, req.(*GetSecretVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_RestoreSecretVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreSecretVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).RestoreSecretVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_RestoreSecretVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).RestoreSecretVersion(ctx, req.(*RestoreSecretVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_ListTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).ListTrash(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_RestoreSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).RestoreSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_RestoreSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).RestoreSecret(ctx, req.(*RestoreSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_PurgeSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).PurgeSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_PurgeSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).PurgeSecret(ctx, req.(*PurgeSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_Sync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NedoVaultServer).Sync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NedoVault_Sync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NedoVaultServer).Sync(ctx, req.(*SyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NedoVault_SyncStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SyncRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NedoVaultServer).SyncStream(m, &grpc.GenericServerStream[SyncRequest, SyncResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NedoVault_SyncStreamServer = grpc.ServerStreamingServer[SyncResponse]
+
 // NedoVault_ServiceDesc is the grpc.ServiceDesc for NedoVault service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -288,10 +1196,74 @@ var NedoVault_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Authorize",
 			Handler:    _NedoVault_Authorize_Handler,
 		},
+		{
+			MethodName: "Register",
+			Handler:    _NedoVault_Register_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _NedoVault_Login_Handler,
+		},
+		{
+			MethodName: "RefreshToken",
+			Handler:    _NedoVault_RefreshToken_Handler,
+		},
+		{
+			MethodName: "VerifyTOTP",
+			Handler:    _NedoVault_VerifyTOTP_Handler,
+		},
+		{
+			MethodName: "EnrollTOTP",
+			Handler:    _NedoVault_EnrollTOTP_Handler,
+		},
+		{
+			MethodName: "ConfirmTOTP",
+			Handler:    _NedoVault_ConfirmTOTP_Handler,
+		},
+		{
+			MethodName: "DisableTOTP",
+			Handler:    _NedoVault_DisableTOTP_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _NedoVault_ChangePassword_Handler,
+		},
+		{
+			MethodName: "DeleteAccount",
+			Handler:    _NedoVault_DeleteAccount_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _NedoVault_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _NedoVault_RevokeSession_Handler,
+		},
+		{
+			MethodName: "GetKeyCheck",
+			Handler:    _NedoVault_GetKeyCheck_Handler,
+		},
+		{
+			MethodName: "SetKeyCheck",
+			Handler:    _NedoVault_SetKeyCheck_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _NedoVault_Logout_Handler,
+		},
+		{
+			MethodName: "LogoutAll",
+			Handler:    _NedoVault_LogoutAll_Handler,
+		},
 		{
 			MethodName: "AddSecret",
 			Handler:    _NedoVault_AddSecret_Handler,
 		},
+		{
+			MethodName: "UpdateSecret",
+			Handler:    _NedoVault_UpdateSecret_Handler,
+		},
 		{
 			MethodName: "DeleteSecret",
 			Handler:    _NedoVault_DeleteSecret_Handler,
@@ -304,6 +1276,34 @@ var NedoVault_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetSecret",
 			Handler:    _NedoVault_GetSecret_Handler,
 		},
+		{
+			MethodName: "ListSecretVersions",
+			Handler:    _NedoVault_ListSecretVersions_Handler,
+		},
+		{
+			MethodName: "GetSecretVersion",
+			Handler:    _NedoVault_GetSecretVersion_Handler,
+		},
+		{
+			MethodName: "RestoreSecretVersion",
+			Handler:    _NedoVault_RestoreSecretVersion_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _NedoVault_ListTrash_Handler,
+		},
+		{
+			MethodName: "RestoreSecret",
+			Handler:    _NedoVault_RestoreSecret_Handler,
+		},
+		{
+			MethodName: "PurgeSecret",
+			Handler:    _NedoVault_PurgeSecret_Handler,
+		},
+		{
+			MethodName: "Sync",
+			Handler:    _NedoVault_Sync_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -311,6 +1311,21 @@ var NedoVault_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _NedoVault_ListSecretsMetaStream_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "UploadSecret",
+			Handler:       _NedoVault_UploadSecret_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadSecret",
+			Handler:       _NedoVault_DownloadSecret_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SyncStream",
+			Handler:       _NedoVault_SyncStream_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api/api.proto",
 }
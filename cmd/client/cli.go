@@ -0,0 +1,796 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/client"
+	"golang.org/x/term"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// grpcExitBase is added to the status code of the failed request, e.g. NotFound exits with 105 and Unauthenticated with 116.
+// Other errors exit with 1 and usage errors with 2
+const grpcExitBase = 100
+
+var (
+	ErrUnknownType   = errors.New("unknown secret type")
+	ErrUnknownField  = errors.New("secret has no such field")
+	ErrNotEditable   = errors.New("file secrets are replaced by add -file")
+	ErrNoChanges     = errors.New("secret is not changed")
+	ErrEmptyValue    = errors.New("secret value is empty")
+	ErrFileTypeValue = errors.New("file secret is added from -file")
+)
+
+// secretJSON is the secret metadata printed with -json
+type secretJSON struct {
+	Key      string          `json:"key"`
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	Revision uint64          `json:"revision"`
+	Updated  time.Time       `json:"updated"`
+	Size     int64           `json:"size,omitempty"`
+	Preview  string          `json:"preview,omitempty"`
+	Value    json.RawMessage `json:"value,omitempty"`
+}
+
+// watchEventJSON is a change of the secrets printed by watch with -json, one per line
+type watchEventJSON struct {
+	Event  string      `json:"event"`
+	Secret *secretJSON `json:"secret"`
+}
+
+// exitCode maps the error of the command to the exit code of the process
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if s, ok := status.FromError(err); ok {
+		return grpcExitBase + int(s.Code())
+	}
+
+	return 1
+}
+
+// runLogin checks the password and the master password of the user, the session is saved and reused by other commands
+func runLogin(c *client.Conn, profile *client.Profile, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	username := fs.String("u", profile.Username, "vault username")
+	asJSON := fs.Bool("json", false, "print the result as json")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault login -u username [-json]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// master password is checked by the key check of the account
+	ctx, _, err := passwordLogin(context.Background(), c, *username)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.ListSecretsMeta(ctx, &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(map[string]any{
+			"username": *username,
+			"profile":  profile.Name,
+			"address":  profile.Address,
+			"secrets":  len(resp.GetSecretsMeta()),
+		})
+	}
+
+	fmt.Printf("logged in to %s (%s) as %s\n", profile.Name, profile.Address, *username)
+
+	return nil
+}
+
+// runLogout revokes the saved session and forgets it
+func runLogout(c *client.Conn, args []string) error {
+	fs := flag.NewFlagSet("logout", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault logout")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if c.Session.Credentials() == nil {
+		return nil
+	}
+
+	_, err := c.Logout(context.Background(), &emptypb.Empty{})
+
+	// session is forgotten even if the server is unreachable, its tokens expire anyway
+	c.Session.Clear()
+
+	return err
+}
+
+// runList prints metadata of the secrets sorted by key
+func runList(c *client.Conn, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("ls", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	secretType := fs.String("type", "", "list only secrets of the type: "+typeNames())
+	asJSON := fs.Bool("json", false, "print secrets as json")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault ls -u username [-type type] [-json] [prefix]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 1 || *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var (
+		filterType api.SecretType
+		err        error
+	)
+
+	if *secretType != "" {
+		if filterType, err = parseType(*secretType); err != nil {
+			return err
+		}
+	}
+
+	ctx, _, err := login(context.Background(), c, *username)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.ListSecretsMeta(ctx, &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+
+	list := make([]*secretJSON, 0, len(resp.GetSecretsMeta()))
+	for _, meta := range resp.GetSecretsMeta() {
+		if *secretType != "" && meta.GetType() != filterType {
+			continue
+		}
+		if !bytes.HasPrefix(meta.GetKey(), []byte(fs.Arg(0))) {
+			continue
+		}
+
+		list = append(list, newSecretJSON(meta))
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key < list[j].Key
+	})
+
+	if *asJSON {
+		return printJSON(list)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tTYPE\tNAME\tREVISION\tUPDATED\tPREVIEW")
+	for _, s := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", s.Key, s.Type, s.Name, s.Revision, s.Updated.Local().Format(time.DateTime), s.Preview)
+	}
+
+	return w.Flush()
+}
+
+// runGet prints the decrypted secret in the format accepted by add and edit, content of file secrets is written as is
+func runGet(c *client.Conn, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	field := fs.String("field", "", "print only the field of the secret, e.g. password")
+	out := fs.String("o", "", "write content of the file secret to the path instead of stdout")
+	asJSON := fs.Bool("json", false, "print the secret with its metadata as json")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault get -u username [-field name | -json] [-o path] key")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 || *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	key := []byte(fs.Arg(0))
+
+	ctx, cipher, err := login(context.Background(), c, *username)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.GetSecret(ctx, c, cipher, key)
+	if err != nil {
+		return err
+	}
+
+	if resp.GetSecret().GetFile() != nil && !*asJSON && *field == "" {
+		var w io.Writer = os.Stdout
+
+		if *out != "" {
+			f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			w = f
+		}
+
+		_, err = client.DownloadFile(ctx, c, cipher, key, 0, w)
+		return err
+	}
+
+	value, err := encodeValue(resp.GetSecret(), false)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		s := newSecretJSON(resp.GetSecretMeta())
+		if resp.GetSecret().GetText() != nil {
+			value, _ = json.Marshal(string(value))
+		}
+		s.Value = value
+
+		return printJSON(s)
+	}
+
+	if *field != "" {
+		if value, err = selectField(resp.GetSecret(), value, *field); err != nil {
+			return err
+		}
+	}
+
+	if !bytes.HasSuffix(value, []byte("\n")) {
+		value = append(value, '\n')
+	}
+
+	_, err = os.Stdout.Write(value)
+	return err
+}
+
+// runAdd adds the secret read from stdin or written in $EDITOR, file secrets are uploaded from -file
+func runAdd(c *client.Conn, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	secretType := fs.String("type", "text", "type of the secret: "+typeNames())
+	name := fs.String("name", "", "name of the secret, the key if empty")
+	path := fs.String("file", "", "path of the file to upload as file secret")
+	asJSON := fs.Bool("json", false, "print metadata of the added secret as json")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault add -u username [-type type] [-name name] [-file path] [-json] key")
+		fmt.Fprintln(fs.Output(), "text is taken as is, other types as json object of their fields, e.g. {\"login\": \"...\", \"password\": \"...\"}")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 || *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	key := []byte(fs.Arg(0))
+
+	if *name == "" {
+		*name = fs.Arg(0)
+	}
+
+	t, err := parseType(*secretType)
+	if err != nil {
+		return err
+	}
+
+	if *path != "" {
+		t = api.SecretType_TYPE_FILE
+	} else if t == api.SecretType_TYPE_FILE {
+		return ErrFileTypeValue
+	}
+
+	ctx, cipher, err := login(context.Background(), c, *username)
+	if err != nil {
+		return err
+	}
+
+	if *path != "" {
+		if *name == fs.Arg(0) {
+			*name = filepath.Base(*path)
+		}
+
+		err = client.UploadFile(ctx, c, cipher, key, []byte(*name), 0, *path)
+	} else {
+		var secret *api.Secret
+
+		if secret, err = newValue(t); err != nil {
+			return err
+		}
+
+		err = client.AddSecret(ctx, c, cipher, &api.AddSecretRequest{
+			Key:        key,
+			Name:       []byte(*name),
+			SecretType: t,
+			Secret:     secret,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		// metadata is not encrypted, so it is printed without opening the secret
+		resp, err := c.GetSecret(ctx, &api.GetSecretRequest{Key: key})
+		if err != nil {
+			return err
+		}
+
+		return printJSON(newSecretJSON(resp.GetSecretMeta()))
+	}
+
+	return nil
+}
+
+// runEdit replaces the secret with the value read from stdin or edited in $EDITOR.
+// The secret is replaced only if nobody changed it since it was read
+func runEdit(c *client.Conn, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	name := fs.String("name", "", "new name of the secret")
+	asJSON := fs.Bool("json", false, "print metadata of the updated secret as json")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault edit -u username [-name name] [-json] key")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 || *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	key := []byte(fs.Arg(0))
+
+	ctx, cipher, err := login(context.Background(), c, *username)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.GetSecret(ctx, c, cipher, key)
+	if err != nil {
+		return err
+	}
+
+	meta := resp.GetSecretMeta()
+
+	if *name == "" {
+		*name = string(meta.GetName())
+	}
+
+	req := &api.UpdateSecretRequest{
+		Key:        key,
+		Name:       []byte(*name),
+		SecretType: meta.GetType().Enum(),
+		Revision:   meta.GetRevision(),
+	}
+
+	// only the name of the file secret can be changed
+	if resp.GetSecret().GetFile() != nil {
+		if *name == string(meta.GetName()) {
+			return ErrNotEditable
+		}
+	} else {
+		current, err := encodeValue(resp.GetSecret(), true)
+		if err != nil {
+			return err
+		}
+
+		raw, err := readValue(current)
+		if err != nil {
+			return err
+		}
+
+		if req.Secret, err = decodeValue(meta.GetType(), raw); err != nil {
+			return err
+		}
+
+		if proto.Equal(req.Secret, resp.GetSecret()) && *name == string(meta.GetName()) {
+			return ErrNoChanges
+		}
+	}
+
+	updated, err := client.UpdateSecret(ctx, c, cipher, req)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(newSecretJSON(updated))
+	}
+
+	return nil
+}
+
+// runRemove moves the secrets to the trash
+func runRemove(c *client.Conn, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault rm -u username key...")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 || *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	ctx, _, err := login(context.Background(), c, *username)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range fs.Args() {
+		if _, err = c.DeleteSecret(ctx, &api.DeleteSecretRequest{Key: []byte(key)}); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// runWatch prints changes of the secrets until interrupted
+func runWatch(c *client.Conn, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	asJSON := fs.Bool("json", false, "print every change as json line")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault watch -u username [-json]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	ctx, _, err := login(context.Background(), c, *username)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stream, err := c.SyncStream(ctx, &api.SyncRequest{})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+
+	// the first response is a reset with all secrets, it is only remembered. Changes are applied to the copy
+	// and events are found by comparing it with the previous one, as a later reset replaces the whole copy
+	var known map[string]*api.SecretMeta
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		current := make(map[string]*api.SecretMeta, len(known))
+		if !resp.GetReset_() {
+			maps.Copy(current, known)
+		}
+
+		for _, change := range resp.GetChanges() {
+			if change.GetKind() == api.ChangeKind_CHANGE_KIND_DELETE {
+				delete(current, string(change.GetKey()))
+				continue
+			}
+
+			current[string(change.GetKey())] = change.GetSecretMeta()
+		}
+
+		if known != nil {
+			for _, e := range diffSecrets(known, current) {
+				if *asJSON {
+					err = encoder.Encode(e)
+				} else {
+					_, err = fmt.Printf("%s\t%s\t%s\t%d\n", e.Secret.Updated.Local().Format(time.DateTime), e.Event, e.Secret.Key, e.Secret.Revision)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		known = current
+	}
+}
+
+// diffSecrets returns events turning the previous snapshot into the current one, ordered by key
+func diffSecrets(previous, current map[string]*api.SecretMeta) []*watchEventJSON {
+	var events []*watchEventJSON
+
+	for key, meta := range current {
+		old, ok := previous[key]
+
+		switch {
+		case !ok:
+			events = append(events, &watchEventJSON{Event: "added", Secret: newSecretJSON(meta)})
+		case old.GetRevision() != meta.GetRevision() || !bytes.Equal(old.GetName(), meta.GetName()):
+			events = append(events, &watchEventJSON{Event: "updated", Secret: newSecretJSON(meta)})
+		}
+	}
+
+	for key, meta := range previous {
+		if _, ok := current[key]; !ok {
+			deleted := newSecretJSON(meta)
+			deleted.Updated = time.Now()
+
+			events = append(events, &watchEventJSON{Event: "deleted", Secret: deleted})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Secret.Key < events[j].Secret.Key
+	})
+
+	return events
+}
+
+func newSecretJSON(meta *api.SecretMeta) *secretJSON {
+	s := &secretJSON{
+		Key:      string(meta.GetKey()),
+		Name:     string(meta.GetName()),
+		Type:     typeName(meta.GetType()),
+		Revision: meta.GetRevision(),
+		Updated:  meta.GetTimestamp().AsTime(),
+		Size:     meta.GetSize(),
+	}
+
+	if card := meta.GetCard(); card != nil {
+		s.Preview = fmt.Sprintf("%s **** %s", card.GetBrand(), card.GetLastFour())
+	} else if sshKey := meta.GetSshKey(); sshKey != nil {
+		s.Preview = sshKey.GetFingerprint()
+	}
+
+	return s
+}
+
+// typeName returns name of the type accepted by -type, e.g. logpass for TYPE_LOGPASS
+func typeName(t api.SecretType) string {
+	return strings.ToLower(strings.TrimPrefix(t.String(), "TYPE_"))
+}
+
+func typeNames() string {
+	names := make([]string, 0, len(api.SecretType_name))
+	for t := range api.SecretType_name {
+		names = append(names, typeName(api.SecretType(t)))
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func parseType(name string) (api.SecretType, error) {
+	t, ok := api.SecretType_value["TYPE_"+strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s, expected one of %s", ErrUnknownType, name, typeNames())
+	}
+
+	return api.SecretType(t), nil
+}
+
+// emptySecret returns the secret of the type with no fields set, it is the template of the value in the editor
+func emptySecret(t api.SecretType) *api.Secret {
+	switch t {
+	case api.SecretType_TYPE_LOGPASS:
+		return &api.Secret{Secret: &api.Secret_LogPass{LogPass: &api.LogPass{}}}
+	case api.SecretType_TYPE_CARD:
+		return &api.Secret{Secret: &api.Secret_Card{Card: &api.Card{}}}
+	case api.SecretType_TYPE_OTP:
+		return &api.Secret{Secret: &api.Secret_Otp{Otp: &api.Otp{}}}
+	case api.SecretType_TYPE_SSH_KEY:
+		return &api.Secret{Secret: &api.Secret_SshKey{SshKey: &api.SshKey{}}}
+	case api.SecretType_TYPE_FILE:
+		return &api.Secret{Secret: &api.Secret_File{File: &api.File{}}}
+	}
+
+	return &api.Secret{Secret: &api.Secret_Text{Text: &api.Text{}}}
+}
+
+// encodeValue returns data of the text secret as is and fields of other secrets as json object
+func encodeValue(secret *api.Secret, multiline bool) ([]byte, error) {
+	if text := secret.GetText(); text != nil {
+		return []byte(text.GetData()), nil
+	}
+
+	message, err := secretMessage(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return protojson.MarshalOptions{
+		Multiline:       multiline,
+		UseProtoNames:   true,
+		EmitUnpopulated: true,
+	}.Marshal(message)
+}
+
+// decodeValue parses value in the format of encodeValue, single trailing newline of the text is dropped
+func decodeValue(t api.SecretType, raw []byte) (*api.Secret, error) {
+	secret := emptySecret(t)
+
+	if text := secret.GetText(); text != nil {
+		text.Data = strings.TrimSuffix(strings.TrimSuffix(string(raw), "\n"), "\r")
+		if text.Data == "" {
+			return nil, ErrEmptyValue
+		}
+
+		return secret, nil
+	}
+
+	message, err := secretMessage(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = protojson.Unmarshal(raw, message); err != nil {
+		return nil, fmt.Errorf("%s value: %w", typeName(t), err)
+	}
+
+	return secret, nil
+}
+
+// selectField returns the field of the secret, text secrets have only the data field
+func selectField(secret *api.Secret, value []byte, field string) ([]byte, error) {
+	if secret.GetText() != nil {
+		if field != "data" {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+		return value, nil
+	}
+
+	fields := map[string]any{}
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return nil, err
+	}
+
+	v, ok := fields[field]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+	}
+
+	if s, ok := v.(string); ok {
+		return []byte(s), nil
+	}
+
+	return json.Marshal(v)
+}
+
+func secretMessage(secret *api.Secret) (proto.Message, error) {
+	switch s := secret.GetSecret().(type) {
+	case *api.Secret_LogPass:
+		return s.LogPass, nil
+	case *api.Secret_Card:
+		return s.Card, nil
+	case *api.Secret_Otp:
+		return s.Otp, nil
+	case *api.Secret_SshKey:
+		return s.SshKey, nil
+	case *api.Secret_File:
+		return s.File, nil
+	}
+
+	return nil, ErrUnknownType
+}
+
+// newValue reads value of the new secret of the type, the editor is opened with template of its fields
+func newValue(t api.SecretType) (*api.Secret, error) {
+	template, err := encodeValue(emptySecret(t), true)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := readValue(template)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeValue(t, raw)
+}
+
+// readValue reads the value piped to stdin, in terminal it opens $EDITOR with the initial value instead
+func readValue(initial []byte) ([]byte, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return io.ReadAll(os.Stdin)
+	}
+
+	f, err := os.CreateTemp("", "nedovault-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(initial)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+
+	cmd := exec.Command(editor[0], append(editor[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor: %w", err)
+	}
+
+	return os.ReadFile(f.Name())
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
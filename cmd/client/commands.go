@@ -0,0 +1,526 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/renatus-cartesius/metricserv/pkg/logger"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/client"
+	"github.com/renatus-cartesius/nedovault/internal/e2e"
+	"github.com/renatus-cartesius/nedovault/internal/otp"
+	"github.com/renatus-cartesius/nedovault/internal/secrets"
+	"github.com/renatus-cartesius/nedovault/internal/sshagent"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrNotOtp         = errors.New("secret is not an otp")
+	ErrNoMatch        = errors.New("passwords do not match")
+)
+
+// runCommand runs the command against the vault of the profile, username of the profile is the default one
+func runCommand(c *client.Conn, profile *client.Profile, args []string) error {
+	switch args[0] {
+	case "register":
+		return runRegister(c, profile.Username, args[1:])
+	case "logout-all":
+		return runLogoutAll(c, profile.Username, args[1:])
+	case "2fa":
+		return run2FA(c, profile.Username, args[1:])
+	case "otp":
+		return runOtp(c, profile.Username, args[1:])
+	case "ssh-add":
+		return runSSHAdd(c, profile.Username, args[1:])
+	case "ssh-agent":
+		return runSSHAgent(c, profile.Username, args[1:])
+	case "login":
+		return runLogin(c, profile, args[1:])
+	case "logout":
+		return runLogout(c, args[1:])
+	case "ls":
+		return runList(c, profile.Username, args[1:])
+	case "get":
+		return runGet(c, profile.Username, args[1:])
+	case "add":
+		return runAdd(c, profile.Username, args[1:])
+	case "edit":
+		return runEdit(c, profile.Username, args[1:])
+	case "rm":
+		return runRemove(c, profile.Username, args[1:])
+	case "watch":
+		return runWatch(c, profile.Username, args[1:])
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+}
+
+// runRegister creates account in the vault and sets its master password, so a mistyped one is rejected on login
+func runRegister(c api.NedoVaultClient, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("register", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault register -u username")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	password, err := lookupSecret("NEDOVAULT_PASSWORD", "Password: ")
+	if err != nil {
+		return err
+	}
+
+	if _, ok := os.LookupEnv("NEDOVAULT_PASSWORD"); !ok {
+		confirm, err := readSecret("Repeat password: ")
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(password, confirm) {
+			return ErrNoMatch
+		}
+	}
+
+	ctx, salt, err := client.Register(context.Background(), c, []byte(*username), password)
+	if err != nil {
+		return err
+	}
+
+	masterPassword, err := lookupSecret("NEDOVAULT_MASTER_PASSWORD", "Master password: ")
+	if err != nil {
+		return err
+	}
+
+	if _, ok := os.LookupEnv("NEDOVAULT_MASTER_PASSWORD"); !ok {
+		confirm, err := readSecret("Repeat master password: ")
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(masterPassword, confirm) {
+			return ErrNoMatch
+		}
+	}
+
+	// key check of the empty vault is set by the first unlock
+	if _, err = client.Unlock(ctx, c, masterPassword, salt); err != nil {
+		return err
+	}
+
+	fmt.Println("registered", *username)
+
+	return nil
+}
+
+// runLogoutAll revokes all tokens of the user, e.g. when one of them has leaked
+func runLogoutAll(c api.NedoVaultClient, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("logout-all", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault logout-all -u username")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	password, err := lookupSecret("NEDOVAULT_PASSWORD", "Password: ")
+	if err != nil {
+		return err
+	}
+
+	ctx, _, err := client.Login(context.Background(), c, []byte(*username), password, askCode)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.LogoutAll(ctx, &emptypb.Empty{})
+	return err
+}
+
+// run2FA enrolls or disables two-factor authentication of the account
+func run2FA(c api.NedoVaultClient, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("2fa", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault 2fa -u username enroll|disable")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 || *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	password, err := lookupSecret("NEDOVAULT_PASSWORD", "Password: ")
+	if err != nil {
+		return err
+	}
+
+	ctx, _, err := client.Login(context.Background(), c, []byte(*username), password, askCode)
+	if err != nil {
+		return err
+	}
+
+	switch fs.Arg(0) {
+	case "enroll":
+		enrollment, err := c.EnrollTOTP(ctx, &api.EnrollTOTPRequest{
+			Password: password,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("add the key to your authenticator app:")
+		fmt.Println(enrollment.Uri)
+		fmt.Println("\nsecret:", enrollment.Secret)
+		fmt.Println("\nrecovery codes, each can be used once instead of the code if the app is lost:")
+		for _, code := range enrollment.RecoveryCodes {
+			fmt.Println(" ", code)
+		}
+		fmt.Println()
+
+		code, err := readSecret("Code from the app to confirm: ")
+		if err != nil {
+			return err
+		}
+
+		if _, err = c.ConfirmTOTP(ctx, &api.ConfirmTOTPRequest{Code: strings.TrimSpace(string(code))}); err != nil {
+			return err
+		}
+
+		fmt.Println("two-factor authentication enabled")
+	case "disable":
+		code, err := readSecret("Code from the app or recovery code: ")
+		if err != nil {
+			return err
+		}
+
+		_, err = c.DisableTOTP(ctx, &api.DisableTOTPRequest{
+			Password: password,
+			Code:     strings.TrimSpace(string(code)),
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("two-factor authentication disabled")
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+// runOtp prints current code of the otp secret, optionally importing it from otpauth:// uri first
+func runOtp(c *client.Conn, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("otp", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	uri := fs.String("import", "", "otpauth:// uri to import the secret from")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault otp -u username [-import otpauth://...] key")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 || *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	key := []byte(fs.Arg(0))
+
+	ctx, cipher, err := login(context.Background(), c, *username)
+	if err != nil {
+		return err
+	}
+
+	var o *api.Otp
+
+	if *uri != "" {
+		o, err = otp.ParseURI(*uri)
+		if err != nil {
+			return err
+		}
+
+		err = client.AddSecret(ctx, c, cipher, &api.AddSecretRequest{
+			Key:        key,
+			Name:       []byte(o.GetIssuer()),
+			SecretType: api.SecretType_TYPE_OTP,
+			Secret: &api.Secret{
+				Secret: &api.Secret_Otp{
+					Otp: o,
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		resp, err := client.GetSecret(ctx, c, cipher, key)
+		if err != nil {
+			return err
+		}
+
+		if o = resp.GetSecret().GetOtp(); o == nil {
+			return ErrNotOtp
+		}
+	}
+
+	now := time.Now()
+
+	code, err := otp.Generate(o, now)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s (expires in %ds)\n", code, int(otp.Remaining(o, now).Seconds()))
+
+	return nil
+}
+
+// runSSHAdd stores private key file in the vault as ssh key secret
+func runSSHAdd(c *client.Conn, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("ssh-add", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault ssh-add -u username key /path/to/private_key")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 || *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	privateKey, err := os.ReadFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	sshKey := &api.SshKey{
+		PrivateKey: string(privateKey),
+	}
+
+	var missing *ssh.PassphraseMissingError
+	if _, err = ssh.ParseRawPrivateKey(privateKey); errors.As(err, &missing) {
+		passphrase, err := readSecret("Key passphrase: ")
+		if err != nil {
+			return err
+		}
+		sshKey.Passphrase = string(passphrase)
+	}
+
+	if _, err = secrets.ParseSSHKey(sshKey); err != nil {
+		return err
+	}
+
+	ctx, cipher, err := login(context.Background(), c, *username)
+	if err != nil {
+		return err
+	}
+
+	return client.AddSecret(ctx, c, cipher, &api.AddSecretRequest{
+		Key:        []byte(fs.Arg(0)),
+		Name:       []byte(filepath.Base(fs.Arg(1))),
+		SecretType: api.SecretType_TYPE_SSH_KEY,
+		Secret: &api.Secret{
+			Secret: &api.Secret_SshKey{
+				SshKey: sshKey,
+			},
+		},
+	})
+}
+
+// runSSHAgent serves ssh keys of the vault through ssh-agent socket until interrupted
+func runSSHAgent(c *client.Conn, defaultUsername string, args []string) error {
+	fs := flag.NewFlagSet("ssh-agent", flag.ExitOnError)
+	username := fs.String("u", defaultUsername, "vault username")
+	socket := fs.String("a", "", "agent socket path (default is a socket in a new private temporary directory)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nedovault ssh-agent -u username [-a socket]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *username == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cipher, err := login(context.Background(), c, *username)
+	if err != nil {
+		return err
+	}
+
+	// default socket is created in a directory only the user can enter, so it is never reachable by others
+	if *socket == "" {
+		dir, err := os.MkdirTemp("", "nedovault-agent-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		*socket = filepath.Join(dir, "agent.sock")
+	}
+
+	sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", *socket)
+
+	return sshagent.NewAgent(ctx, c, cipher).Serve(sigCtx, *socket)
+}
+
+// login reuses the saved session of the user, so only the master password is asked. Otherwise it authorizes
+// with password, the session is saved by the credential store of the client
+func login(ctx context.Context, c *client.Conn, username string) (context.Context, *e2e.Cipher, error) {
+	if creds := c.Session.Credentials(); creds != nil && creds.Username == username {
+		cipher, err := unlock(ctx, c, creds.Salt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return ctx, cipher, openReplica(ctx, c, username, cipher)
+	}
+
+	ctx, cipher, err := passwordLogin(ctx, c, username)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ctx, cipher, openReplica(ctx, c, username, cipher)
+}
+
+// openReplica opens the offline replica of the user, so commands work without the vault, and replays
+// changes made offline. Replica locked by another running client is skipped
+func openReplica(ctx context.Context, c *client.Conn, username string, cipher *e2e.Cipher) error {
+	err := c.Replica.Open([]byte(username), cipher)
+	if errors.Is(err, client.ErrReplicaKey) {
+		return err
+	}
+	if err != nil {
+		logger.Log.Warn(
+			"error opening offline replica",
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	if err = c.Replica.Replay(ctx, c); err != nil && status.Code(err) != codes.Unavailable {
+		logger.Log.Warn(
+			"error replaying offline changes",
+			zap.Error(err),
+		)
+	}
+
+	return nil
+}
+
+// reportPending tells the user about changes of the command that were queued offline and conflicts of the earlier ones
+func reportPending(replica *client.Replica) {
+	if changes, conflicts := replica.Pending(); conflicts > 0 {
+		fmt.Fprintf(os.Stderr, "%d offline changes conflict with the vault, resolve them in the TUI\n", conflicts)
+	} else if changes > 0 {
+		fmt.Fprintf(os.Stderr, "%d offline changes will be synced when the vault is reachable\n", changes)
+	}
+}
+
+// passwordLogin authorizes with password taken from NEDOVAULT_PASSWORD and derives encryption key from
+// master password taken from NEDOVAULT_MASTER_PASSWORD, both are prompted from terminal when not set
+func passwordLogin(ctx context.Context, c api.NedoVaultClient, username string) (context.Context, *e2e.Cipher, error) {
+	password, err := lookupSecret("NEDOVAULT_PASSWORD", "Password: ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, salt, err := client.Login(ctx, c, []byte(username), password, askCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cipher, err := unlock(ctx, c, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ctx, cipher, nil
+}
+
+// unlock derives encryption key from master password taken from NEDOVAULT_MASTER_PASSWORD or prompted from terminal,
+// the key is checked by the vault's key check of the account
+func unlock(ctx context.Context, c api.NedoVaultClient, salt []byte) (*e2e.Cipher, error) {
+	masterPassword, err := lookupSecret("NEDOVAULT_MASTER_PASSWORD", "Master password: ")
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Unlock(ctx, c, masterPassword, salt)
+}
+
+// askCode asks for the two-factor authentication code, it is read from NEDOVAULT_CODE if set
+func askCode() (string, error) {
+	code, err := lookupSecret("NEDOVAULT_CODE", "Two-factor code: ")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(code)), nil
+}
+
+func lookupSecret(env, prompt string) ([]byte, error) {
+	if value, ok := os.LookupEnv(env); ok {
+		return []byte(value), nil
+	}
+
+	return readSecret(prompt)
+}
+
+func readSecret(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	return term.ReadPassword(int(os.Stdin.Fd()))
+}
@@ -1,44 +1,146 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/renatus-cartesius/metricserv/pkg/logger"
 	"github.com/renatus-cartesius/nedovault/api"
+	vault "github.com/renatus-cartesius/nedovault/internal/client"
 	"github.com/renatus-cartesius/nedovault/internal/tui"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
 	"log"
+	"os"
+	"path/filepath"
 	"sync"
 )
 
+// version is set on build with -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
 
-	serverAddress := "127.0.0.1:1337"
-	//ctx, cancel := context.WithCancel(context.Background())
-	//defer cancel()
+	defaultConfigPath, err := vault.DefaultConfigPath()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	configPath := flag.String("config", defaultConfigPath, "path to the client config with profiles")
+	profileName := flag.String("profile", os.Getenv("NEDOVAULT_PROFILE"), "profile to connect with, default profile of the config if empty")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: nedovault [-config path] [-profile name] [command [args]]")
+		fmt.Fprintln(flag.CommandLine.Output(), "without command the TUI is started, commands:")
+		fmt.Fprintln(flag.CommandLine.Output(), "  login logout ls get add edit rm watch register logout-all 2fa otp ssh-add ssh-agent")
+		fmt.Fprintf(flag.CommandLine.Output(), "failed requests exit with %d plus the gRPC status code, e.g. %d for NotFound\n", grpcExitBase, grpcExitBase+int(codes.NotFound))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cfg, err := vault.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	profile, err := cfg.Profile(*profileName)
+	if err != nil {
+		log.Fatalln(err)
+	}
 
-	if err := logger.Initialize("INFO"); err != nil {
+	if err = logger.Initialize(profile.LogLevel); err != nil {
 		log.Fatalln(err)
 	}
 
-	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	device, err := os.Hostname()
+	if err != nil {
+		device = "unknown"
 	}
 
-	conn, err := grpc.NewClient(serverAddress, opts...)
+	var conns []*grpc.ClientConn
+	// replicas of every profile opened in the TUI, they are closed on exit
+	var replicas []*vault.Replica
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	credentialsDir, err := vault.DefaultCredentialsDir()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	store := vault.NewCredentialStore(credentialsDir)
+
+	// every profile has its own session, so tokens of one vault are never sent to another
+	dial := func(p *vault.Profile) (*vault.Conn, error) {
+		session := vault.NewSession(
+			vault.WithClientInfo(device, "nedovault-client/"+version),
+			vault.WithCredentialStore(store, p.Name),
+		)
+
+		if err := session.Restore(); err != nil && !errors.Is(err, vault.ErrNoCredentials) {
+			logger.Log.Warn(
+				"error restoring saved session, login is required",
+				zap.String("profile", p.Name),
+				zap.Error(err),
+			)
+		}
+
+		// secrets are mirrored for offline use after the replica is unlocked with the user cipher on login
+		replica := vault.NewReplica(filepath.Join(credentialsDir, "replica", p.Name))
+
+		conn, err := p.Dial(append(replica.DialOptions(), session.DialOptions()...)...)
+		if err != nil {
+			return nil, err
+		}
+
+		conns = append(conns, conn)
+		replicas = append(replicas, replica)
+
+		return &vault.Conn{
+			NedoVaultClient: api.NewNedoVaultClient(conn),
+			Session:         session,
+			Replica:         replica,
+		}, nil
+	}
+
+	if flag.NArg() > 0 {
+		conn, err := dial(profile)
+		if err != nil {
+			logger.Log.Fatal(
+				"error creating grpc client",
+				zap.String("profile", profile.Name),
+				zap.Error(err),
+			)
+		}
+
+		err = runCommand(conn, profile, flag.Args())
+		reportPending(conn.Replica)
+		// replica is closed before exit, so the queued changes are flushed
+		conn.Replica.Close()
+
+		if errors.Is(err, ErrUnknownCommand) {
+			log.Println(err)
+			flag.Usage()
+			os.Exit(2)
+		} else if err != nil {
+			log.Println(err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
+	ui, err := tui.NewUI(cfg.ProfileList(), profile, dial)
 	if err != nil {
 		logger.Log.Fatal(
 			"error creating grpc client",
-			zap.String("server_address", serverAddress),
+			zap.String("profile", profile.Name),
 			zap.Error(err),
 		)
 	}
-	defer conn.Close()
-
-	client := api.NewNedoVaultClient(conn)
 
 	wg := &sync.WaitGroup{}
-	ui := tui.NewUI(client)
 
 	wg.Add(1)
 	go func() {
@@ -48,4 +150,13 @@ func main() {
 
 	wg.Wait()
 
+	// replicas are closed before exit, so the queued changes are flushed
+	for _, replica := range replicas {
+		if err = replica.Close(); err != nil {
+			logger.Log.Error(
+				"error closing replica",
+				zap.Error(err),
+			)
+		}
+	}
 }
@@ -0,0 +1,193 @@
+// gen-certs issues a CA with server and client certificates for local development.
+// Existing CA is reused, so certificates can be reissued without updating trusted bundles of the clients
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidPEM = errors.New("file has no pem block")
+)
+
+func main() {
+	out := flag.String("out", "./certs", "directory of the certificates")
+	hosts := flag.String("hosts", "localhost,127.0.0.1,::1", "comma separated names and addresses of the server")
+	client := flag.String("client", "nedovault-client", "common name of the client certificate")
+	validity := flag.Duration("validity", time.Hour*24*365, "validity of the server and client certificates")
+	flag.Parse()
+
+	if err := os.MkdirAll(*out, 0700); err != nil {
+		log.Fatalln(err)
+	}
+
+	caCert, caKey, err := loadOrCreateCA(*out)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	server := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "nedovault-server"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	for _, host := range strings.Split(*hosts, ",") {
+		host = strings.TrimSpace(host)
+		if ip := net.ParseIP(host); ip != nil {
+			server.IPAddresses = append(server.IPAddresses, ip)
+		} else if host != "" {
+			server.DNSNames = append(server.DNSNames, host)
+		}
+	}
+
+	if err = issue(*out, "server", server, *validity, caCert, caKey); err != nil {
+		log.Fatalln(err)
+	}
+
+	clientTemplate := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: *client},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	if err = issue(*out, "client", clientTemplate, *validity, caCert, caKey); err != nil {
+		log.Fatalln(err)
+	}
+
+	fmt.Printf("certificates are written to %s\n", *out)
+}
+
+// loadOrCreateCA reads the CA from the directory or creates a new one valid for 10 years
+func loadOrCreateCA(dir string) (*x509.Certificate, crypto.Signer, error) {
+	certPath, keyPath := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca-key.pem")
+
+	cert, key, err := readPair(certPath, keyPath)
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		return cert, key, err
+	}
+
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "nedovault development CA"},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	if err = issue(dir, "ca", template, time.Hour*24*365*10, nil, nil); err != nil {
+		return nil, nil, err
+	}
+
+	return readPair(certPath, keyPath)
+}
+
+// issue writes <name>.pem and <name>-key.pem signed by the CA, the certificate is self-signed if the CA is nil
+func issue(dir, name string, template *x509.Certificate, validity time.Duration, caCert *x509.Certificate, caKey crypto.Signer) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = time.Now().Add(validity)
+	if !template.IsCA {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+	}
+
+	if caCert == nil {
+		caCert, caKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, key.Public(), caKey)
+	if err != nil {
+		return err
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	// key goes first, so the server reloading the files never sees a certificate without its key
+	err = writePEM(filepath.Join(dir, name+"-key.pem"), "PRIVATE KEY", keyDER, 0600)
+	if err != nil {
+		return err
+	}
+
+	return writePEM(filepath.Join(dir, name+".pem"), "CERTIFICATE", der, 0644)
+}
+
+// writePEM replaces the file atomically, so it is never read half-written
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+
+	err := os.WriteFile(tmp, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), perm)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func readPair(certPath, keyPath string) (*x509.Certificate, crypto.Signer, error) {
+	certDER, err := readPEM(certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyDER, err := readPEM(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(keyDER)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: unsupported key type", keyPath)
+	}
+
+	return cert, signer, nil
+}
+
+func readPEM(path string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPEM, path)
+	}
+
+	return block.Bytes, nil
+}
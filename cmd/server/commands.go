@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/nedovault/pkg/storage"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+)
+
+// runCommand runs admin command against the storage, server must be stopped as the storage is opened exclusively
+func runCommand(db *badger.DB, kekPath string, args []string) error {
+	switch args[0] {
+	case "rotate-kek":
+		return runRotateKEK(db, kekPath, args[1:])
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+}
+
+// runRotateKEK generates a new master key and rewraps data keys of all users with it.
+// Interrupted rotation is continued by running the command again
+func runRotateKEK(db *badger.DB, kekPath string, args []string) error {
+	fs := flag.NewFlagSet("rotate-kek", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: server rotate-kek")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	current, err := readKEK(kekPath)
+	if err != nil {
+		return err
+	}
+
+	// the new key is saved before rewrapping, so it is not lost if rotation is interrupted
+	nextPath := kekPath + ".new"
+
+	next, err := readKEK(nextPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if next, err = storage.GenerateKEK(); err != nil {
+			return err
+		}
+		err = writeKEK(nextPath, next)
+	}
+	if err != nil {
+		return err
+	}
+
+	keyring, err := storage.NewKeyring(next, current)
+	if err != nil {
+		return err
+	}
+
+	badgerStorage := storage.NewBadgerStorage(db, storage.WithKeyring(keyring))
+
+	rewrapped, err := badgerStorage.RewrapDataKeys(context.Background())
+	if err != nil {
+		return err
+	}
+
+	// signing keys are wrapped by the master key too, they would be lost with the replaced key
+	rewrappedSigning, err := badgerStorage.RewrapSigningKeys(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if err = os.Rename(nextPath, kekPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("rewrapped %d data keys and %d signing keys, master key %s replaced by %s\n", rewrapped, rewrappedSigning, storage.KEKID(current), storage.KEKID(next))
+
+	return nil
+}
+
+// loadKeyring reads master key from path, generating it on the first start.
+// Key left by interrupted rotation becomes the current one, so the rewrapped data keys stay readable
+func loadKeyring(path string) (*storage.Keyring, error) {
+	current, err := readKEK(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if current, err = storage.GenerateKEK(); err != nil {
+			return nil, err
+		}
+		err = writeKEK(path, current)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := readKEK(path + ".new")
+	if errors.Is(err, os.ErrNotExist) {
+		return storage.NewKeyring(current)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.NewKeyring(next, current)
+}
+
+func readKEK(path string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return hex.DecodeString(strings.TrimSpace(string(raw)))
+}
+
+func writeKEK(path string, key []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".kek-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// envPrefix prefixes environment variables of the settings, e.g. -storage-path is NEDOVAULT_STORAGE_PATH
+const envPrefix = "NEDOVAULT_"
+
+var (
+	ErrInvalidConfig = errors.New("invalid config")
+)
+
+// Config of the server. Settings are taken from the defaults, the config file, environment variables
+// and flags, every next source overrides the previous one
+type Config struct {
+	Address string `yaml:"address"`
+	// JWKSAddress serves public keys of the tokens, empty address disables it
+	JWKSAddress string        `yaml:"jwks_address"`
+	LogLevel    string        `yaml:"log_level"`
+	Storage     StorageConfig `yaml:"storage"`
+	Auth        AuthConfig    `yaml:"auth"`
+	TLS         TLSConfig     `yaml:"tls"`
+}
+
+type StorageConfig struct {
+	Path string `yaml:"path"`
+	// EncryptionKey encrypts badger files, it is 16, 24 or 32 bytes long. It is better read from the file
+	EncryptionKey     string `yaml:"encryption_key"`
+	EncryptionKeyFile string `yaml:"encryption_key_file"`
+	IndexCacheSize    int64  `yaml:"index_cache_size"`
+	// KEKPath is the master key wrapping data keys of the users, it is generated on the first start
+	KEKPath            string        `yaml:"kek_path"`
+	MaxFileSize        int64         `yaml:"max_file_size"`
+	HistoryDepth       int           `yaml:"history_depth"`
+	TrashRetention     time.Duration `yaml:"trash_retention"`
+	TrashPurgeInterval time.Duration `yaml:"trash_purge_interval"`
+}
+
+type AuthConfig struct {
+	AccessTokenTTL          time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL         time.Duration `yaml:"refresh_token_ttl"`
+	BcryptCost              int           `yaml:"bcrypt_cost"`
+	SigningAlgorithm        string        `yaml:"signing_algorithm"`
+	SigningKeyRotation      time.Duration `yaml:"signing_key_rotation"`
+	SigningKeyCheckInterval time.Duration `yaml:"signing_key_check_interval"`
+	// LegacyJWTKey verifies HS256 tokens issued by older versions, they are rejected if it is not set
+	LegacyJWTKey       string `yaml:"legacy_jwt_key"`
+	LegacyJWTKeyFile   string `yaml:"legacy_jwt_key_file"`
+	PasswordMinLength  int    `yaml:"password_min_length"`
+	PasswordMinClasses int    `yaml:"password_min_classes"`
+	// BreachedPasswordsPaths are files with additional breached passwords, one per line
+	BreachedPasswordsPaths []string `yaml:"breached_passwords_paths"`
+}
+
+type TLSConfig struct {
+	// Cert and Key are PEM files of the server certificate, empty cert serves plaintext, e.g. behind a proxy terminating TLS
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+	// ClientCA is a bundle client certificates are verified against, they are required if it is set
+	ClientCA       string        `yaml:"client_ca"`
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Address:     ":1337",
+		JWKSAddress: ":1338",
+		LogLevel:    "INFO",
+		Storage: StorageConfig{
+			Path:               "./.nedovault",
+			EncryptionKeyFile:  "./.nedovault.key",
+			IndexCacheSize:     100 << 20,
+			KEKPath:            "./.nedovault.kek",
+			MaxFileSize:        64 << 20,
+			HistoryDepth:       10,
+			TrashRetention:     time.Hour * 24 * 30,
+			TrashPurgeInterval: time.Hour,
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:          time.Minute * 15,
+			RefreshTokenTTL:         time.Hour * 24 * 30,
+			BcryptCost:              12,
+			SigningAlgorithm:        auth.AlgorithmEdDSA,
+			SigningKeyRotation:      time.Hour * 24 * 7,
+			SigningKeyCheckInterval: time.Hour,
+			PasswordMinLength:       12,
+			PasswordMinClasses:      3,
+		},
+		TLS: TLSConfig{
+			Cert:           "./certs/server.pem",
+			Key:            "./certs/server-key.pem",
+			ReloadInterval: time.Minute,
+		},
+	}
+}
+
+// loadConfig reads the config from the file set by -config flag or NEDOVAULT_CONFIG, environment variables and args.
+// Secrets set by paths are read, so the returned config holds their values. Args left after flags are returned
+func loadConfig(args []string) (*Config, []string, error) {
+	// the first pass only finds the config file, as flags are applied over it
+	var path string
+
+	probe := flagSet(defaultConfig(), &path, flag.ContinueOnError)
+	probe.SetOutput(io.Discard)
+	if err := applyEnv(probe); err != nil {
+		return nil, nil, err
+	}
+	_ = probe.Parse(args)
+
+	cfg := defaultConfig()
+
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	fs := flagSet(cfg, &path, flag.ExitOnError)
+	if err := applyEnv(fs); err != nil {
+		return nil, nil, err
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.ensureEncryptionKey(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.readSecrets(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+func flagSet(cfg *Config, path *string, handling flag.ErrorHandling) *flag.FlagSet {
+	fs := flag.NewFlagSet("server", handling)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: server [flags] [command]")
+		fmt.Fprintf(fs.Output(), "every flag is also read from %s<FLAG> environment variable, e.g. %sSTORAGE_PATH\n", envPrefix, envPrefix)
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(path, "config", *path, "path of the YAML config file")
+
+	fs.StringVar(&cfg.Address, "address", cfg.Address, "grpc listen address")
+	fs.StringVar(&cfg.JWKSAddress, "jwks-address", cfg.JWKSAddress, "listen address of the key set, empty disables it")
+	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "log level")
+
+	fs.StringVar(&cfg.Storage.Path, "storage-path", cfg.Storage.Path, "badger directory")
+	fs.StringVar(&cfg.Storage.EncryptionKey, "storage-encryption-key", cfg.Storage.EncryptionKey, "badger encryption key, prefer the key file")
+	fs.StringVar(&cfg.Storage.EncryptionKeyFile, "storage-encryption-key-file", cfg.Storage.EncryptionKeyFile, "file with badger encryption key of 16, 24 or 32 bytes")
+	fs.Int64Var(&cfg.Storage.IndexCacheSize, "storage-index-cache-size", cfg.Storage.IndexCacheSize, "badger index cache size in bytes")
+	fs.StringVar(&cfg.Storage.KEKPath, "storage-kek-path", cfg.Storage.KEKPath, "master key file, generated on the first start")
+	fs.Int64Var(&cfg.Storage.MaxFileSize, "storage-max-file-size", cfg.Storage.MaxFileSize, "maximum size of file secrets in bytes, zero means no limit")
+	fs.IntVar(&cfg.Storage.HistoryDepth, "storage-history-depth", cfg.Storage.HistoryDepth, "previous versions kept for every secret")
+	fs.DurationVar(&cfg.Storage.TrashRetention, "storage-trash-retention", cfg.Storage.TrashRetention, "how long deleted secrets are kept")
+	fs.DurationVar(&cfg.Storage.TrashPurgeInterval, "storage-trash-purge-interval", cfg.Storage.TrashPurgeInterval, "how often expired secrets are purged")
+
+	fs.DurationVar(&cfg.Auth.AccessTokenTTL, "auth-access-token-ttl", cfg.Auth.AccessTokenTTL, "lifetime of access tokens")
+	fs.DurationVar(&cfg.Auth.RefreshTokenTTL, "auth-refresh-token-ttl", cfg.Auth.RefreshTokenTTL, "lifetime of refresh tokens")
+	fs.IntVar(&cfg.Auth.BcryptCost, "auth-bcrypt-cost", cfg.Auth.BcryptCost, "cost of password hashes")
+	fs.StringVar(&cfg.Auth.SigningAlgorithm, "auth-signing-algorithm", cfg.Auth.SigningAlgorithm, "token signing algorithm, EdDSA or RS256")
+	fs.DurationVar(&cfg.Auth.SigningKeyRotation, "auth-signing-key-rotation", cfg.Auth.SigningKeyRotation, "how often signing key is replaced")
+	fs.DurationVar(&cfg.Auth.SigningKeyCheckInterval, "auth-signing-key-check-interval", cfg.Auth.SigningKeyCheckInterval, "how often signing key age is checked")
+	fs.StringVar(&cfg.Auth.LegacyJWTKey, "auth-legacy-jwt-key", cfg.Auth.LegacyJWTKey, "key of HS256 tokens issued by older versions, prefer the key file")
+	fs.StringVar(&cfg.Auth.LegacyJWTKeyFile, "auth-legacy-jwt-key-file", cfg.Auth.LegacyJWTKeyFile, "file with key of HS256 tokens issued by older versions")
+	fs.IntVar(&cfg.Auth.PasswordMinLength, "auth-password-min-length", cfg.Auth.PasswordMinLength, "minimal password length")
+	fs.IntVar(&cfg.Auth.PasswordMinClasses, "auth-password-min-classes", cfg.Auth.PasswordMinClasses, "minimal number of character classes in password")
+	fs.Var((*listValue)(&cfg.Auth.BreachedPasswordsPaths), "auth-breached-passwords-paths", "comma separated files with breached passwords")
+
+	fs.StringVar(&cfg.TLS.Cert, "tls-cert", cfg.TLS.Cert, "server certificate, empty serves plaintext")
+	fs.StringVar(&cfg.TLS.Key, "tls-key", cfg.TLS.Key, "server certificate key")
+	fs.StringVar(&cfg.TLS.ClientCA, "tls-client-ca", cfg.TLS.ClientCA, "CA bundle of client certificates, enables mutual TLS")
+	fs.DurationVar(&cfg.TLS.ReloadInterval, "tls-reload-interval", cfg.TLS.ReloadInterval, "how often certificate files are checked for changes")
+
+	return fs
+}
+
+// applyEnv sets flags from their environment variables
+func applyEnv(fs *flag.FlagSet) error {
+	var err error
+
+	fs.VisitAll(func(f *flag.Flag) {
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+
+		if setErr := f.Value.Set(value); setErr != nil {
+			err = errors.Join(err, fmt.Errorf("%s: %w", name, setErr))
+		}
+	})
+
+	return err
+}
+
+func (c *Config) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// unknown keys are rejected, so typos are not silently ignored
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+
+	if err = decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidConfig, path, err)
+	}
+
+	return nil
+}
+
+// readSecrets reads the secrets from their files, the value set directly takes precedence over the file
+func (c *Config) readSecrets() error {
+	secrets := []struct {
+		name  string
+		value *string
+		path  string
+	}{
+		{"storage encryption key", &c.Storage.EncryptionKey, c.Storage.EncryptionKeyFile},
+		{"legacy jwt key", &c.Auth.LegacyJWTKey, c.Auth.LegacyJWTKeyFile},
+	}
+
+	for _, s := range secrets {
+		if *s.value != "" || s.path == "" {
+			continue
+		}
+
+		raw, err := os.ReadFile(s.path)
+		if err != nil {
+			return fmt.Errorf("%w: reading %s: %s", ErrInvalidConfig, s.name, err)
+		}
+
+		*s.value = strings.TrimRight(string(raw), "\r\n")
+	}
+
+	return nil
+}
+
+// ensureEncryptionKey generates the key file on the first start, when there is no storage encrypted by another key yet
+func (c *Config) ensureEncryptionKey() error {
+	if c.Storage.EncryptionKey != "" || c.Storage.EncryptionKeyFile == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(c.Storage.EncryptionKeyFile); !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if _, err := os.Stat(c.Storage.Path); !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: storage %s exists, but its encryption key file %s is missing", ErrInvalidConfig, c.Storage.Path, c.Storage.EncryptionKeyFile)
+	}
+
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	// hex encoded key is 32 bytes long, so it is used as is
+	return os.WriteFile(c.Storage.EncryptionKeyFile, []byte(hex.EncodeToString(key)+"\n"), 0600)
+}
+
+// validate reports all invalid settings at once
+func (c *Config) validate() error {
+	var errs []error
+
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Address != "", "address must be set")
+	_, levelErr := zap.ParseAtomicLevel(c.LogLevel)
+	check(levelErr == nil, "unknown log level %q", c.LogLevel)
+
+	check(c.Storage.Path != "", "storage path must be set")
+	keyLen := len(c.Storage.EncryptionKey)
+	check(keyLen == 16 || keyLen == 24 || keyLen == 32, "storage encryption key must be 16, 24 or 32 bytes, got %d", keyLen)
+	check(c.Storage.KEKPath != "", "storage kek path must be set")
+	check(c.Storage.MaxFileSize >= 0, "storage max file size must not be negative")
+	check(c.Storage.HistoryDepth >= 0, "storage history depth must not be negative")
+	check(c.Storage.TrashRetention > 0, "storage trash retention must be positive")
+	check(c.Storage.TrashPurgeInterval > 0, "storage trash purge interval must be positive")
+
+	check(c.Auth.AccessTokenTTL > 0, "access token ttl must be positive")
+	check(c.Auth.RefreshTokenTTL > c.Auth.AccessTokenTTL, "refresh token ttl must be longer than access token ttl")
+	check(c.Auth.SigningAlgorithm == auth.AlgorithmEdDSA || c.Auth.SigningAlgorithm == auth.AlgorithmRS256,
+		"unknown signing algorithm %q", c.Auth.SigningAlgorithm)
+	check(c.Auth.SigningKeyRotation > 0, "signing key rotation must be positive")
+	check(c.Auth.SigningKeyCheckInterval > 0, "signing key check interval must be positive")
+	check(c.Auth.PasswordMinLength > 0, "password min length must be positive")
+	check(c.Auth.PasswordMinClasses >= 1 && c.Auth.PasswordMinClasses <= 4, "password min classes must be from 1 to 4")
+
+	check(c.TLS.Cert == "" || c.TLS.Key != "", "tls key must be set with the certificate")
+	check(c.TLS.ClientCA == "" || c.TLS.Cert != "", "mutual tls requires the server certificate")
+	check(c.TLS.ReloadInterval > 0, "tls reload interval must be positive")
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+}
+
+// listValue is a comma separated flag
+type listValue []string
+
+func (l *listValue) String() string {
+	if l == nil {
+		return ""
+	}
+
+	return strings.Join(*l, ",")
+}
+
+func (l *listValue) Set(value string) error {
+	*l = nil
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+
+	return nil
+}
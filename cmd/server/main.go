@@ -1,8 +1,9 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"github.com/dgraph-io/badger/v4"
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/renatus-cartesius/metricserv/pkg/logger"
 	"github.com/renatus-cartesius/nedovault/api"
 	"github.com/renatus-cartesius/nedovault/internal/auth"
@@ -10,19 +11,28 @@ import (
 	"github.com/renatus-cartesius/nedovault/pkg/storage"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"log"
 	"net"
-	"time"
+	"net/http"
+	"os"
 )
 
 func main() {
 
-	address := ":1337"
+	cfg, args, err := loadConfig(os.Args[1:])
+	if err != nil {
+		log.Fatalln(err)
+	}
 
-	badgerOpts := badger.DefaultOptions("./.nedovault")
+	if err = logger.Initialize(cfg.LogLevel); err != nil {
+		log.Fatalln(err)
+	}
+
+	badgerOpts := badger.DefaultOptions(cfg.Storage.Path)
 
-	badgerOpts.EncryptionKey = []byte("verysstrongkeeeeyfromsomeconfigg")
-	badgerOpts.IndexCacheSize = 100 << 20
+	badgerOpts.EncryptionKey = []byte(cfg.Storage.EncryptionKey)
+	badgerOpts.IndexCacheSize = cfg.Storage.IndexCacheSize
 
 	db, err := badger.Open(badgerOpts)
 	if err != nil {
@@ -30,19 +40,135 @@ func main() {
 	}
 	defer db.Close()
 
-	if err = logger.Initialize("INFO"); err != nil {
-		log.Fatalln(err)
+	if len(args) > 0 {
+		if err = runCommand(db, cfg.Storage.KEKPath, args); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	keyring, err := loadKeyring(cfg.Storage.KEKPath)
+	if err != nil {
+		logger.Log.Fatal(
+			"error loading master key",
+			zap.String("path", cfg.Storage.KEKPath),
+			zap.Error(err),
+		)
+	}
+
+	badgerStorage := storage.NewBadgerStorage(
+		db,
+		storage.WithMaxFileSize(cfg.Storage.MaxFileSize),
+		storage.WithHistoryDepth(cfg.Storage.HistoryDepth),
+		storage.WithKeyring(keyring),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go badgerStorage.RunTrashPurger(ctx, cfg.Storage.TrashRetention, cfg.Storage.TrashPurgeInterval)
+
+	passwordPolicy, err := auth.NewPasswordPolicy(cfg.Auth.PasswordMinLength, cfg.Auth.PasswordMinClasses, cfg.Auth.BreachedPasswordsPaths...)
+	if err != nil {
+		logger.Log.Fatal(
+			"error loading password policy",
+			zap.Error(err),
+		)
+	}
+
+	// signing keys saved before they were wrapped by the master key are wrapped on start
+	if rewrapped, err := badgerStorage.RewrapSigningKeys(ctx); err != nil {
+		logger.Log.Fatal(
+			"error wrapping signing keys",
+			zap.Error(err),
+		)
+	} else if rewrapped > 0 {
+		logger.Log.Info(
+			"signing keys wrapped by the master key",
+			zap.Int("count", rewrapped),
+		)
+	}
+
+	// retired keys are published until the longest lived tokens signed by them expire
+	keyset, err := auth.NewKeyset(ctx, badgerStorage, cfg.Auth.SigningAlgorithm, cfg.Auth.SigningKeyRotation, cfg.Auth.RefreshTokenTTL)
+	if err != nil {
+		logger.Log.Fatal(
+			"error loading signing keys",
+			zap.Error(err),
+		)
+	}
+
+	go keyset.RunRotation(ctx, cfg.Auth.SigningKeyCheckInterval)
+
+	authOpts := []auth.Option{
+		auth.WithBcryptCost(cfg.Auth.BcryptCost),
+	}
+
+	// tokens issued before the key set are accepted until they expire
+	if cfg.Auth.LegacyJWTKey != "" {
+		authOpts = append(authOpts, auth.WithLegacyKey([]byte(cfg.Auth.LegacyJWTKey)))
 	}
 
-	badgerStorage := storage.NewBadgerStorage(db)
 	localAuth := auth.NewLocalAuth(
-		[]byte("d6b32087c4b1f7c8b88c945234d54cfa5aa73d4b14e5e7a778448d515db00028b20db"), // TODO: store key in the storage
-		time.Hour*24*30,
+		keyset,
+		cfg.Auth.AccessTokenTTL,
+		cfg.Auth.RefreshTokenTTL,
 		badgerStorage,
-		jwt.SigningMethodHS256,
+		passwordPolicy,
+		authOpts...,
 	)
 
-	lis, err := net.Listen("tcp", address)
+	var certReloader *server.CertReloader
+	if cfg.TLS.Cert != "" {
+		certReloader, err = server.NewCertReloader(cfg.TLS.Cert, cfg.TLS.Key, cfg.TLS.ClientCA)
+		if err != nil {
+			logger.Log.Fatal(
+				"error loading tls certificate",
+				zap.String("cert_path", cfg.TLS.Cert),
+				zap.Error(err),
+			)
+		}
+
+		go certReloader.Run(ctx, cfg.TLS.ReloadInterval)
+	} else {
+		logger.Log.Warn(
+			"tls is disabled, tokens and secrets are sent in plaintext",
+		)
+	}
+
+	if cfg.JWKSAddress != "" {
+		go func() {
+			logger.Log.Info(
+				"starting jwks server",
+				zap.String("address", cfg.JWKSAddress),
+			)
+
+			jwksServer := &http.Server{
+				Addr:    cfg.JWKSAddress,
+				Handler: server.NewJWKSHandler(keyset),
+			}
+
+			var err error
+			if certReloader != nil {
+				// key set is public, so client certificates are not requested
+				jwksServer.TLSConfig = &tls.Config{
+					MinVersion:     tls.VersionTLS12,
+					GetCertificate: certReloader.GetCertificate,
+				}
+				err = jwksServer.ListenAndServeTLS("", "")
+			} else {
+				err = jwksServer.ListenAndServe()
+			}
+
+			if err != nil {
+				logger.Log.Error(
+					"jwks server stopped",
+					zap.Error(err),
+				)
+			}
+		}()
+	}
+
+	lis, err := net.Listen("tcp", cfg.Address)
 	if err != nil {
 		logger.Log.Fatal(
 			"error creating listen struct for grpc server",
@@ -54,9 +180,13 @@ func main() {
 		grpc.StreamInterceptor(server.NewAuthStreamInterceptor(localAuth)),
 	}
 
+	if certReloader != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(certReloader.ServerConfig())))
+	}
+
 	logger.Log.Info(
 		"starting grpc server",
-		zap.String("address", address),
+		zap.String("address", cfg.Address),
 	)
 	grpcServer := grpc.NewServer(opts...)
 
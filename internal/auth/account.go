@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/renatus-cartesius/metricserv/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ChangePassword replaces password of the user and revokes all tokens, so sessions opened with the old password are closed
+func (a *LocalAuth) ChangePassword(ctx context.Context, username, oldPassword, newPassword []byte) error {
+	_, err := a.checkPassword(ctx, username, oldPassword)
+	if err != nil {
+		return err
+	}
+
+	if err = a.policy.Check(username, newPassword); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword(newPassword, a.cost)
+	if err != nil {
+		return err
+	}
+
+	// only the hash is updated, so the second factor used meanwhile is not rolled back
+	err = a.storage.UpdateAuthMeta(ctx, username, func(meta *Meta) error {
+		meta.Hash = hash
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = a.storage.RevokeAllTokens(ctx, username); err != nil {
+		return err
+	}
+
+	logger.Log.Info(
+		"password changed",
+		zap.String("username", string(username)),
+	)
+
+	return nil
+}
+
+// DeleteAccount removes the user with all secrets, the password and the second factor are checked again
+func (a *LocalAuth) DeleteAccount(ctx context.Context, username, password []byte, code string) error {
+	meta, err := a.checkPassword(ctx, username, password)
+	if err != nil {
+		return err
+	}
+
+	if meta.TOTP != nil && meta.TOTP.Confirmed {
+		if err = a.verifyCode(ctx, username, code, nil); err != nil {
+			return err
+		}
+	}
+
+	// tokens are revoked first, so the secrets are not changed while they are deleted
+	if err = a.storage.RevokeAllTokens(ctx, username); err != nil {
+		return err
+	}
+
+	if err = a.storage.DeleteUser(ctx, username); err != nil {
+		return err
+	}
+
+	if err = a.resetFailures(ctx, username); err != nil {
+		return err
+	}
+
+	logger.Log.Info(
+		"account deleted",
+		zap.String("username", string(username)),
+	)
+
+	return nil
+}
+
+// checkPassword verifies password of the logged in user before sensitive changes, failures are counted as failed logins
+func (a *LocalAuth) checkPassword(ctx context.Context, username, password []byte) (*Meta, error) {
+	if err := a.reserveAttempt(ctx, username); err != nil {
+		return nil, err
+	}
+
+	meta, err := a.getMeta(ctx, username)
+	if err == nil {
+		err = bcrypt.CompareHashAndPassword(meta.Hash, password)
+	}
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if releaseErr := a.releaseAttempt(ctx, username); releaseErr != nil {
+		return nil, releaseErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
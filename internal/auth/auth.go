@@ -1,14 +1,17 @@
 package auth
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/renatus-cartesius/metricserv/pkg/logger"
 	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/e2e"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
-	"reflect"
 	"time"
 )
 
@@ -16,53 +19,275 @@ var (
 	ErrInvalidCredentials = errors.New("client passed invalid credentials")
 	ErrMetadataGet        = errors.New("something went wrong when getting auth metadata")
 	ErrInvalidToken       = errors.New("client passed invalid token")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserExists         = errors.New("user already exists")
+	ErrEmptyUsername      = errors.New("username must not be empty")
+	ErrRevokedToken       = errors.New("client passed revoked token")
+	ErrTokenReused        = errors.New("client reused refresh token")
+)
+
+// Types of the issued tokens, access tokens authorize requests and refresh tokens are exchanged for new tokens.
+// Challenge is issued after the password check to complete login with the second factor
+const (
+	TokenAccess    = "access"
+	TokenRefresh   = "refresh"
+	TokenChallenge = "challenge"
 )
 
 type Username string
 
+// TokenID is a context key of the request token id
+type TokenID string
+
 type Storage interface {
 	GetAuthMeta(ctx context.Context, username []byte) (*Meta, error)
 	AddAuthMeta(ctx context.Context, username []byte, meta *Meta) error
-	AppendTokens(ctx context.Context, username, token []byte) error
+	// UpdateAuthMeta changes auth metadata of the user by fn in one transaction, error of fn aborts the update.
+	// ErrUserNotFound is returned if there is no such user
+	UpdateAuthMeta(ctx context.Context, username []byte, fn func(meta *Meta) error) error
+	// CreateAuthMeta adds auth metadata of the new user, ErrUserExists is returned if the user is already registered
+	CreateAuthMeta(ctx context.Context, username []byte, meta *Meta) error
+	// AppendTokens saves records of the issued tokens, records of the expired ones may be dropped
+	AppendTokens(ctx context.Context, username []byte, tokens ...*TokenMeta) error
+	// GetToken returns record of the issued token or nil if there is no such token
+	GetToken(ctx context.Context, username []byte, id string) (*TokenMeta, error)
+	RevokeToken(ctx context.Context, username []byte, id string) error
+	// RevokeAllTokens revokes all tokens of the user and drops all sessions
+	RevokeAllTokens(ctx context.Context, username []byte) error
+	// RevokeFamily revokes all tokens issued within one login session and drops the session
+	RevokeFamily(ctx context.Context, username []byte, family string) error
+	// RotateToken marks the refresh token used and saves records of the tokens issued instead of it in one transaction.
+	// ErrTokenReused is returned if the refresh token was already used or revoked. Records of the expired tokens
+	// and of the refresh tokens rotated before in the same session may be dropped
+	RotateToken(ctx context.Context, username []byte, id string, tokens ...*TokenMeta) error
+	// GetLoginAttempts returns failed login attempts counted by the key or nil if there are none
+	GetLoginAttempts(ctx context.Context, key []byte) (*LoginAttempts, error)
+	// AddLoginFailure counts the failed attempt if check of the current attempts passes, both in one transaction.
+	// Updated attempts are returned, they are forgotten after the window
+	AddLoginFailure(ctx context.Context, key []byte, window time.Duration, check func(attempts *LoginAttempts) error) (*LoginAttempts, error)
+	// RemoveLoginFailure takes back one failure counted by AddLoginFailure
+	RemoveLoginFailure(ctx context.Context, key []byte) error
+	ResetLoginAttempts(ctx context.Context, key []byte) error
+	// PutSession saves the session, the record expires with the session. Nothing is saved if all tokens of the session are revoked
+	PutSession(ctx context.Context, username []byte, session *Session) error
+	// TouchSession replaces record of the existing session, nothing is saved if the session was revoked meanwhile
+	TouchSession(ctx context.Context, username []byte, session *Session) error
+	// GetSession returns the session or nil if there is no such active session
+	GetSession(ctx context.Context, username []byte, id string) (*Session, error)
+	ListSessions(ctx context.Context, username []byte) ([]*Session, error)
+	// DeleteUser removes the user with all secrets and auth metadata
+	DeleteUser(ctx context.Context, username []byte) error
 }
 
 type Meta struct {
-	Hash   []byte
-	Tokens []jwt.Token
+	Hash []byte
+	// Salt is used by clients to derive the encryption key from the master password
+	Salt []byte
+	// KeyCheck is sealed by the client with the derived key to check the master password, nil until it is set
+	KeyCheck []byte `json:",omitempty"`
+	// TOTP is the second factor of the account, nil if it is not enrolled
+	TOTP *TOTPMeta `json:",omitempty"`
+}
+
+// TokenMeta is a record of the issued token, tokens without record and revoked ones are rejected
+type TokenMeta struct {
+	ID string `json:"id"`
+	// Family is shared by all tokens issued within one login session
+	Family    string    `json:"family,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Revoked   bool      `json:"revoked"`
 }
 
 type Claims struct {
 	jwt.RegisteredClaims
 	Username string `json:"username"`
+	Type     string `json:"token_type,omitempty"`
+	// Family is the login session of the token, it is known even if the token record was dropped
+	Family string `json:"family,omitempty"`
+}
+
+// TokenPair is a short-lived access token and a refresh token exchanged for the next pair
+type TokenPair struct {
+	Access  string
+	Refresh string
+}
+
+// LoginResult is tokens of the new session or the challenge to complete with the second factor
+type LoginResult struct {
+	Tokens    *TokenPair
+	Salt      []byte
+	Challenge string
 }
 
 type LocalAuth struct {
-	key      []byte
-	tokenTTL time.Duration
-	storage  Storage
+	keys *Keyset
+	// legacyKey verifies HS256 tokens issued before the key set was introduced
+	legacyKey  []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	storage    Storage
+	policy     *PasswordPolicy
+	cost       int
+	lockout    LockoutPolicy
+}
+
+type Option func(a *LocalAuth)
 
-	method jwt.SigningMethod
+// WithBcryptCost sets cost of password hashes, hashes of other cost are replaced on the next login.
+// Costs below bcrypt.MinCost are replaced by bcrypt.DefaultCost, as bcrypt itself does
+func WithBcryptCost(cost int) Option {
+	return func(a *LocalAuth) {
+		if cost < bcrypt.MinCost {
+			cost = bcrypt.DefaultCost
+		}
+		a.cost = cost
+	}
+}
+
+// WithLegacyKey accepts HS256 tokens without kid header signed by the key, so users are not logged out on upgrade
+func WithLegacyKey(key []byte) Option {
+	return func(a *LocalAuth) {
+		a.legacyKey = key
+	}
 }
 
+// WithLockoutPolicy sets backoff of login attempts after failures
+func WithLockoutPolicy(policy LockoutPolicy) Option {
+	return func(a *LocalAuth) {
+		a.lockout = policy
+	}
+}
+
+// ParseToken checks the access token and returns its claims
 func (a *LocalAuth) ParseToken(ctx context.Context, tok []byte) (*Claims, error) {
+	claims, err := a.parseClaims(tok)
+	if err != nil {
+		return nil, err
+	}
+
+	// refresh tokens and challenges are not accepted in place of access tokens, tokens without type are issued by older versions
+	if claims.Type != TokenAccess && claims.Type != "" {
+		return nil, ErrInvalidToken
+	}
+
+	// token is valid only while its record exists and is not revoked
+	tokenMeta, err := a.storage.GetToken(ctx, []byte(claims.Username), claims.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenMeta == nil || tokenMeta.Revoked {
+		return nil, ErrRevokedToken
+	}
+
+	// failure to save the last seen time should not fail the request
+	if tokenMeta.Family != "" {
+		if err = a.touchSession(ctx, []byte(claims.Username), tokenMeta.Family, time.Time{}); err != nil {
+			logger.Log.Error(
+				"error updating session",
+				zap.String("username", claims.Username),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return claims, nil
+}
+
+// Refresh exchanges the refresh token for a new token pair of the same login session.
+// Reused refresh token means it has leaked, so the whole session is revoked
+func (a *LocalAuth) Refresh(ctx context.Context, tok []byte) (*TokenPair, error) {
+	claims, err := a.parseClaims(tok)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Type != TokenRefresh {
+		return nil, ErrInvalidToken
+	}
+
+	username := []byte(claims.Username)
+
+	tokenMeta, err := a.storage.GetToken(ctx, username, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// record of the used refresh token is dropped on the next rotation of the session, so it is reused
+	if tokenMeta == nil && claims.Family != "" {
+		tokenMeta = &TokenMeta{ID: claims.ID, Family: claims.Family, Type: TokenRefresh, Revoked: true}
+	}
+
+	if tokenMeta == nil {
+		return nil, ErrRevokedToken
+	}
+
+	pair, tokens, err := a.newTokenPair(username, tokenMeta.Family)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tokenMeta.Revoked {
+		err = a.storage.RotateToken(ctx, username, claims.ID, tokens...)
+	} else {
+		err = ErrTokenReused
+	}
+
+	if errors.Is(err, ErrTokenReused) {
+		logger.Log.Warn(
+			"refresh token reused, revoking session",
+			zap.String("username", claims.Username),
+			zap.String("family", tokenMeta.Family),
+		)
+
+		if err = a.storage.RevokeFamily(ctx, username, tokenMeta.Family); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrTokenReused
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// refresh token is the last one of the pair, the session lives as long as it.
+	// Tokens are already rotated, so the client gets them even if the session record is not updated
+	if err = a.touchSession(ctx, username, tokenMeta.Family, tokens[len(tokens)-1].ExpiresAt); err != nil {
+		logger.Log.Error(
+			"error updating session on refresh",
+			zap.String("username", claims.Username),
+			zap.String("family", tokenMeta.Family),
+			zap.Error(err),
+		)
+	}
+
+	return pair, nil
+}
+
+func (a *LocalAuth) parseClaims(tok []byte) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(
 		string(tok),
 		&Claims{},
 		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Header["kid"]; ok {
+				return a.keys.verificationKey(token)
+			}
 
-			if reflect.TypeOf(token.Method) != reflect.TypeOf(a.method) {
+			if a.legacyKey == nil || token.Method != jwt.SigningMethodHS256 {
 				logger.Log.Error(
 					"passed token has invalid signing type",
 				)
 				return nil, ErrInvalidToken
 			}
 
-			return a.key, nil
+			return a.legacyKey, nil
 		},
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
 	}
 
 	claims, ok := token.Claims.(*Claims)
@@ -75,18 +300,101 @@ func (a *LocalAuth) ParseToken(ctx context.Context, tok []byte) (*Claims, error)
 	return claims, nil
 }
 
-func NewLocalAuth(key []byte, tokenTTL time.Duration, storage Storage, method jwt.SigningMethod) *LocalAuth {
-	return &LocalAuth{
-		key:      key,
-		tokenTTL: tokenTTL,
-		storage:  storage,
-		method:   method,
+// Logout revokes all tokens of the login session the token with passed id belongs to
+func (a *LocalAuth) Logout(ctx context.Context, username []byte, tokenID string) error {
+	tokenMeta, err := a.storage.GetToken(ctx, username, tokenID)
+	if err != nil {
+		return err
+	}
+
+	// tokens issued before refresh tokens were introduced have no session
+	if tokenMeta == nil || tokenMeta.Family == "" {
+		return a.storage.RevokeToken(ctx, username, tokenID)
+	}
+
+	return a.storage.RevokeFamily(ctx, username, tokenMeta.Family)
+}
+
+// LogoutAll revokes all tokens of the user
+func (a *LocalAuth) LogoutAll(ctx context.Context, username []byte) error {
+	return a.storage.RevokeAllTokens(ctx, username)
+}
+
+func NewLocalAuth(keys *Keyset, accessTTL, refreshTTL time.Duration, storage Storage, policy *PasswordPolicy, opts ...Option) *LocalAuth {
+	a := &LocalAuth{
+		keys:       keys,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		storage:    storage,
+		policy:     policy,
+		cost:       bcrypt.DefaultCost,
+		lockout:    DefaultLockoutPolicy,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Register creates account of the user and returns tokens and key derivation salt of the user
+func (a *LocalAuth) Register(ctx context.Context, in *api.AuthRequest) (*TokenPair, []byte, error) {
+	if err := CheckUsername(in.GetUsername()); err != nil {
+		return nil, nil, err
+	}
+
+	if err := a.policy.Check(in.GetUsername(), in.GetPassword()); err != nil {
+		return nil, nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword(in.Password, a.cost)
+	if err != nil {
+		logger.Log.Error(
+			"error generating password hash",
+			zap.String("username", string(in.Username)),
+		)
+		return nil, nil, err
+	}
+
+	salt, err := e2e.NewSalt()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta := &Meta{
+		Hash: hash,
+		Salt: salt,
+	}
+
+	if err = a.storage.CreateAuthMeta(ctx, in.Username, meta); err != nil {
+		return nil, nil, err
+	}
+
+	logger.Log.Info(
+		"user registered",
+		zap.String("username", string(in.Username)),
+	)
+
+	pair, err := a.IssueTokens(ctx, in.Username)
+	if err != nil {
+		return nil, nil, err
 	}
+
+	return pair, meta.Salt, nil
 }
 
-func (a *LocalAuth) Authorize(ctx context.Context, in *api.AuthRequest) (string, error) {
+// Login checks password of the existing user and returns tokens and key derivation salt of the user.
+// Challenge is returned instead if the user has enabled the second factor
+func (a *LocalAuth) Login(ctx context.Context, in *api.AuthRequest) (*LoginResult, error) {
+	if err := CheckUsername(in.GetUsername()); err != nil {
+		return nil, err
+	}
+
+	if err := a.reserveAttempt(ctx, in.Username); err != nil {
+		return nil, err
+	}
 
-	// check if user exists
 	meta, err := a.storage.GetAuthMeta(ctx, in.Username)
 	if err != nil {
 
@@ -95,70 +403,188 @@ func (a *LocalAuth) Authorize(ctx context.Context, in *api.AuthRequest) (string,
 			zap.Error(err),
 		)
 
-		return "", ErrMetadataGet
+		if err = a.releaseAttempt(ctx, in.Username); err != nil {
+			return nil, err
+		}
+		return nil, ErrMetadataGet
 	}
 
-	if meta != nil {
+	// reserved attempt is left counted as failed
+	if meta == nil {
+		return nil, ErrUserNotFound
+	}
 
-		logger.Log.Debug(
-			"comparing password with hash",
-			zap.String("username", string(in.Username)),
-		)
+	logger.Log.Debug(
+		"comparing password with hash",
+		zap.String("username", string(in.Username)),
+	)
+
+	// comparing hash and pass with bcrypt
+	err = bcrypt.CompareHashAndPassword(meta.Hash, in.Password)
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return nil, ErrInvalidCredentials
+	}
 
-		// comparing hash and pass with bcrypt
-		if err = bcrypt.CompareHashAndPassword(meta.Hash, in.Password); err != nil {
-			return "", err
+	if releaseErr := a.releaseAttempt(ctx, in.Username); releaseErr != nil {
+		return nil, releaseErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// hash is replaced once the cost is changed, as the password is known only on login.
+	// Only the hash is updated, so the second factor used meanwhile is not rolled back
+	if cost, err := bcrypt.Cost(meta.Hash); err == nil && cost != a.cost {
+		hash, err := bcrypt.GenerateFromPassword(in.Password, a.cost)
+		if err != nil {
+			return nil, err
 		}
 
-		// getting token and return it
+		err = a.storage.UpdateAuthMeta(ctx, in.Username, func(stored *Meta) error {
+			// password changed meanwhile is kept
+			if bytes.Equal(stored.Hash, meta.Hash) {
+				stored.Hash = hash
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
 
-		return a.IssueToken(ctx, in.Username)
-	} else {
-		// adding auth metadata for user
-		logger.Log.Debug(
-			"adding auth metadata for user",
+		logger.Log.Info(
+			"password rehashed",
 			zap.String("username", string(in.Username)),
+			zap.Int("from_cost", cost),
+			zap.Int("to_cost", a.cost),
 		)
+	}
 
-		hash, err := bcrypt.GenerateFromPassword(in.Password, 1)
+	// users registered before encryption was introduced get their salt on the next login
+	if meta.Salt == nil {
+		salt, err := e2e.NewSalt()
 		if err != nil {
-			logger.Log.Error(
-				"error generating password hash",
-				zap.String("username", string(in.Username)),
-			)
+			return nil, err
 		}
 
-		meta = &Meta{
-			Hash: hash,
+		// salt given by concurrent login is kept
+		err = a.storage.UpdateAuthMeta(ctx, in.Username, func(stored *Meta) error {
+			if stored.Salt == nil {
+				stored.Salt = salt
+			}
+			meta.Salt = stored.Salt
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		if err = a.storage.AddAuthMeta(ctx, in.Username, meta); err != nil {
-			return "", err
+	// failures are reset only after the second factor, so codes can't be guessed by logging in again
+	if meta.TOTP != nil && meta.TOTP.Confirmed {
+		challenge, err := a.issueChallenge(ctx, in.Username)
+		if err != nil {
+			return nil, err
 		}
 
+		return &LoginResult{
+			Challenge: challenge,
+		}, nil
+	}
+
+	if err = a.resetFailures(ctx, in.Username); err != nil {
+		return nil, err
 	}
 
-	return a.IssueToken(ctx, in.Username)
+	pair, err := a.IssueTokens(ctx, in.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{
+		Tokens: pair,
+		Salt:   meta.Salt,
+	}, nil
 }
 
-func (a *LocalAuth) IssueToken(ctx context.Context, username []byte) (string, error) {
+// IssueTokens starts a new login session of the user
+func (a *LocalAuth) IssueTokens(ctx context.Context, username []byte) (*TokenPair, error) {
+	family := uuid.NewString()
+
+	pair, tokens, err := a.newTokenPair(username, family)
+	if err != nil {
+		return nil, err
+	}
+
+	// we need to append new tokens to user existing tokens
+	if err = a.storage.AppendTokens(ctx, username, tokens...); err != nil {
+		return nil, err
+	}
+
+	if err = a.startSession(ctx, username, family, tokens[len(tokens)-1].ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// newTokenPair signs access and refresh tokens of the session and returns records to be saved
+func (a *LocalAuth) newTokenPair(username []byte, family string) (*TokenPair, []*TokenMeta, error) {
+	now := time.Now()
+
+	access := &TokenMeta{
+		ID:        uuid.NewString(),
+		Family:    family,
+		Type:      TokenAccess,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(a.accessTTL),
+	}
+
+	refresh := &TokenMeta{
+		ID:        uuid.NewString(),
+		Family:    family,
+		Type:      TokenRefresh,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(a.refreshTTL),
+	}
+
+	accessToken, err := a.signToken(username, access)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, err := a.signToken(username, refresh)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pair := &TokenPair{
+		Access:  accessToken,
+		Refresh: refreshToken,
+	}
+
+	return pair, []*TokenMeta{access, refresh}, nil
+}
+
+func (a *LocalAuth) signToken(username []byte, tokenMeta *TokenMeta) (string, error) {
 	claims := Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:  "",
 			Subject: "",
 			ExpiresAt: &jwt.NumericDate{
-				Time: time.Now().Add(a.tokenTTL),
+				Time: tokenMeta.ExpiresAt,
 			},
 			NotBefore: nil,
 			IssuedAt: &jwt.NumericDate{
-				Time: time.Now(),
+				Time: tokenMeta.IssuedAt,
 			},
-			ID: "",
+			ID: tokenMeta.ID,
 		},
 		Username: string(username),
+		Type:     tokenMeta.Type,
+		Family:   tokenMeta.Family,
 	}
 
-	token, err := jwt.NewWithClaims(a.method, claims).SignedString([]byte(a.key))
+	token, err := a.keys.sign(claims)
 	if err != nil {
 
 		logger.Log.Error(
@@ -169,8 +595,5 @@ func (a *LocalAuth) IssueToken(ctx context.Context, username []byte) (string, er
 		return "", err
 	}
 
-	// we need to append a new token to user existing tokens
-	err = a.storage.AppendTokens(ctx, username, []byte(token))
-
-	return token, err
+	return token, nil
 }
@@ -0,0 +1,358 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"github.com/renatus-cartesius/nedovault/pkg/storage"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/peer"
+	"net"
+	"testing"
+	"time"
+)
+
+const testPassword = "Corr3ct-Horse-Battery!"
+
+// testLockout locks the username out after three failures and the peer after five
+var testLockout = auth.LockoutPolicy{
+	FreeAttempts:     3,
+	PeerFreeAttempts: 5,
+	BaseDelay:        time.Hour,
+	MaxDelay:         time.Hour * 4,
+	Window:           time.Hour * 24,
+}
+
+// newTestAuth returns auth backed by in-memory storage with alice and bob registered
+func newTestAuth(t *testing.T) *auth.LocalAuth {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatalf("badger.Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	st := storage.NewBadgerStorage(db)
+
+	keys, err := auth.NewKeyset(ctx, st, auth.AlgorithmEdDSA, time.Hour*24, time.Hour*48)
+	if err != nil {
+		t.Fatalf("NewKeyset() error = %v", err)
+	}
+
+	policy, err := auth.NewPasswordPolicy(8, 2)
+	if err != nil {
+		t.Fatalf("NewPasswordPolicy() error = %v", err)
+	}
+
+	a := auth.NewLocalAuth(keys, time.Minute, time.Hour, st, policy,
+		auth.WithBcryptCost(bcrypt.MinCost),
+		auth.WithLockoutPolicy(testLockout),
+	)
+
+	for _, username := range []string{"alice", "bob"} {
+		if _, _, err = a.Register(ctx, &api.AuthRequest{Username: []byte(username), Password: []byte(testPassword)}); err != nil {
+			t.Fatalf("Register(%s) error = %v", username, err)
+		}
+	}
+
+	return a
+}
+
+func peerContext(host string) context.Context {
+	if host == "" {
+		return context.Background()
+	}
+
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP(host), Port: 40000},
+	})
+}
+
+func TestLoginLockout(t *testing.T) {
+	type attempt struct {
+		username string
+		password string
+		peer     string
+		want     error
+	}
+
+	wrong := func(username, peer string, want error) attempt {
+		return attempt{username: username, password: "wrong password", peer: peer, want: want}
+	}
+	right := func(username, peer string, want error) attempt {
+		return attempt{username: username, password: testPassword, peer: peer, want: want}
+	}
+
+	tests := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name: "username locked out after free attempts",
+			attempts: []attempt{
+				wrong("alice", "", auth.ErrInvalidCredentials),
+				wrong("alice", "", auth.ErrInvalidCredentials),
+				wrong("alice", "", auth.ErrInvalidCredentials),
+				wrong("alice", "", auth.ErrLockedOut),
+				// even the right password waits for the delay
+				right("alice", "", auth.ErrLockedOut),
+				// other users are not affected
+				right("bob", "", nil),
+			},
+		},
+		{
+			name: "successful login resets failures",
+			attempts: []attempt{
+				wrong("alice", "", auth.ErrInvalidCredentials),
+				wrong("alice", "", auth.ErrInvalidCredentials),
+				right("alice", "", nil),
+				wrong("alice", "", auth.ErrInvalidCredentials),
+				wrong("alice", "", auth.ErrInvalidCredentials),
+				wrong("alice", "", auth.ErrInvalidCredentials),
+				wrong("alice", "", auth.ErrLockedOut),
+			},
+		},
+		{
+			name: "unknown username is locked out too",
+			attempts: []attempt{
+				wrong("ghost", "", auth.ErrUserNotFound),
+				wrong("ghost", "", auth.ErrUserNotFound),
+				wrong("ghost", "", auth.ErrUserNotFound),
+				wrong("ghost", "", auth.ErrLockedOut),
+			},
+		},
+		{
+			name: "peer locked out after guessing many usernames",
+			attempts: []attempt{
+				wrong("alice", "192.0.2.1", auth.ErrInvalidCredentials),
+				wrong("bob", "192.0.2.1", auth.ErrInvalidCredentials),
+				wrong("carol", "192.0.2.1", auth.ErrUserNotFound),
+				wrong("dave", "192.0.2.1", auth.ErrUserNotFound),
+				wrong("eve", "192.0.2.1", auth.ErrUserNotFound),
+				right("bob", "192.0.2.1", auth.ErrLockedOut),
+				// the attempt refused by the peer is not counted for the username
+				right("bob", "198.51.100.1", nil),
+			},
+		},
+		{
+			name: "right password keeps the peer attempts",
+			attempts: []attempt{
+				right("alice", "192.0.2.1", nil),
+				right("alice", "192.0.2.1", nil),
+				right("alice", "192.0.2.1", nil),
+				right("alice", "192.0.2.1", nil),
+				right("alice", "192.0.2.1", nil),
+				right("alice", "192.0.2.1", nil),
+			},
+		},
+		{
+			name: "invalid username is not counted",
+			attempts: []attempt{
+				wrong("../alice", "", auth.ErrInvalidUsername),
+				wrong("../alice", "", auth.ErrInvalidUsername),
+				wrong("../alice", "", auth.ErrInvalidUsername),
+				wrong("../alice", "", auth.ErrInvalidUsername),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAuth(t)
+
+			for i, at := range tt.attempts {
+				_, err := a.Login(peerContext(at.peer), &api.AuthRequest{
+					Username: []byte(at.username),
+					Password: []byte(at.password),
+				})
+				if !errors.Is(err, at.want) {
+					t.Fatalf("attempt %d of %s: Login() error = %v, want %v", i+1, at.username, err, at.want)
+				}
+
+				var locked *auth.LockedOutError
+				if errors.As(err, &locked) && (locked.RetryAfter <= 0 || locked.RetryAfter > testLockout.BaseDelay) {
+					t.Errorf("attempt %d of %s: retry after %v, want up to %v", i+1, at.username, locked.RetryAfter, testLockout.BaseDelay)
+				}
+			}
+		})
+	}
+}
+
+func login(t *testing.T, a *auth.LocalAuth, username string) *auth.TokenPair {
+	t.Helper()
+
+	result, err := a.Login(context.Background(), &api.AuthRequest{Username: []byte(username), Password: []byte(testPassword)})
+	if err != nil {
+		t.Fatalf("Login(%s) error = %v", username, err)
+	}
+
+	return result.Tokens
+}
+
+func refresh(t *testing.T, a *auth.LocalAuth, pair *auth.TokenPair) *auth.TokenPair {
+	t.Helper()
+
+	next, err := a.Refresh(context.Background(), []byte(pair.Refresh))
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	return next
+}
+
+func TestRefreshReuse(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// run returns the error of the checked call
+		run  func(t *testing.T, a *auth.LocalAuth) error
+		want error
+	}{
+		{
+			name: "rotated refresh token",
+			run: func(t *testing.T, a *auth.LocalAuth) error {
+				next := refresh(t, a, refresh(t, a, login(t, a, "alice")))
+				_, err := a.ParseToken(ctx, []byte(next.Access))
+				return err
+			},
+		},
+		{
+			name: "reused refresh token",
+			run: func(t *testing.T, a *auth.LocalAuth) error {
+				pair := login(t, a, "alice")
+				refresh(t, a, pair)
+				_, err := a.Refresh(ctx, []byte(pair.Refresh))
+				return err
+			},
+			want: auth.ErrTokenReused,
+		},
+		{
+			name: "reuse revokes refresh token of the session",
+			run: func(t *testing.T, a *auth.LocalAuth) error {
+				pair := login(t, a, "alice")
+				next := refresh(t, a, pair)
+				a.Refresh(ctx, []byte(pair.Refresh))
+				_, err := a.Refresh(ctx, []byte(next.Refresh))
+				return err
+			},
+			want: auth.ErrTokenReused,
+		},
+		{
+			name: "reuse revokes access token of the session",
+			run: func(t *testing.T, a *auth.LocalAuth) error {
+				pair := login(t, a, "alice")
+				next := refresh(t, a, pair)
+				a.Refresh(ctx, []byte(pair.Refresh))
+				_, err := a.ParseToken(ctx, []byte(next.Access))
+				return err
+			},
+			want: auth.ErrRevokedToken,
+		},
+		{
+			name: "reuse of the refresh token rotated long ago",
+			run: func(t *testing.T, a *auth.LocalAuth) error {
+				pair := login(t, a, "alice")
+				next := pair
+				for i := 0; i < 3; i++ {
+					next = refresh(t, a, next)
+				}
+				if _, err := a.Refresh(ctx, []byte(pair.Refresh)); !errors.Is(err, auth.ErrTokenReused) {
+					return err
+				}
+				_, err := a.Refresh(ctx, []byte(next.Refresh))
+				return err
+			},
+			want: auth.ErrTokenReused,
+		},
+		{
+			name: "other sessions are kept",
+			run: func(t *testing.T, a *auth.LocalAuth) error {
+				other := login(t, a, "alice")
+				pair := login(t, a, "alice")
+				refresh(t, a, pair)
+				a.Refresh(ctx, []byte(pair.Refresh))
+				_, err := a.Refresh(ctx, []byte(other.Refresh))
+				return err
+			},
+		},
+		{
+			name: "access token is not a refresh token",
+			run: func(t *testing.T, a *auth.LocalAuth) error {
+				_, err := a.Refresh(ctx, []byte(login(t, a, "alice").Access))
+				return err
+			},
+			want: auth.ErrInvalidToken,
+		},
+		{
+			name: "refresh token is not an access token",
+			run: func(t *testing.T, a *auth.LocalAuth) error {
+				_, err := a.ParseToken(ctx, []byte(login(t, a, "alice").Refresh))
+				return err
+			},
+			want: auth.ErrInvalidToken,
+		},
+		{
+			name: "refresh after logout",
+			run: func(t *testing.T, a *auth.LocalAuth) error {
+				pair := login(t, a, "alice")
+				claims, err := a.ParseToken(ctx, []byte(pair.Access))
+				if err != nil {
+					return err
+				}
+				if err = a.Logout(ctx, []byte("alice"), claims.ID); err != nil {
+					return err
+				}
+				_, err = a.Refresh(ctx, []byte(pair.Refresh))
+				return err
+			},
+			want: auth.ErrTokenReused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(t, newTestAuth(t)); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefreshConcurrent(t *testing.T) {
+	a := newTestAuth(t)
+	pair := login(t, a, "alice")
+
+	const attempts = 10
+
+	errs := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		go func() {
+			_, err := a.Refresh(context.Background(), []byte(pair.Refresh))
+			errs <- err
+		}()
+	}
+
+	refreshed := 0
+	for i := 0; i < attempts; i++ {
+		err := <-errs
+		switch {
+		case err == nil:
+			refreshed++
+		case !errors.Is(err, auth.ErrTokenReused):
+			t.Errorf("Refresh() error = %v, want %v", err, auth.ErrTokenReused)
+		}
+	}
+
+	// the token is exchanged at most once, the reuse by the other requests revokes the session
+	if refreshed > 1 {
+		t.Errorf("refresh token was exchanged %d times", refreshed)
+	}
+}
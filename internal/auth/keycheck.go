@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrKeyCheckExists = errors.New("key check is already set")
+)
+
+// KeyCheck returns the key check of the user, nil if it is not set yet
+func (a *LocalAuth) KeyCheck(ctx context.Context, username []byte) ([]byte, error) {
+	meta, err := a.storage.GetAuthMeta(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return meta.KeyCheck, nil
+}
+
+// SetKeyCheck saves the key check of the user once, so the master password can't be switched by a stolen token
+func (a *LocalAuth) SetKeyCheck(ctx context.Context, username, keyCheck []byte) error {
+	return a.storage.UpdateAuthMeta(ctx, username, func(meta *Meta) error {
+		if meta.KeyCheck != nil {
+			return ErrKeyCheckExists
+		}
+
+		meta.KeyCheck = keyCheck
+		return nil
+	})
+}
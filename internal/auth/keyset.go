@@ -0,0 +1,464 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/renatus-cartesius/metricserv/pkg/logger"
+	"go.uber.org/zap"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// Algorithms of the signing keys
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+)
+
+const rsaKeyBits = 3072
+
+// JWKSCacheTTL is how long verifiers may cache the published key set, so the next key is published that long before it signs
+const JWKSCacheTTL = time.Minute * 5
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown signing algorithm")
+	ErrUnknownKey       = errors.New("token is signed by unknown key")
+	ErrNoSigningKey     = errors.New("no active signing key")
+)
+
+// KeyStorage persists signing keys, so tokens stay valid across restarts
+type KeyStorage interface {
+	ListSigningKeys(ctx context.Context) ([]*SigningKey, error)
+	PutSigningKey(ctx context.Context, key *SigningKey) error
+	DeleteSigningKey(ctx context.Context, id string) error
+}
+
+// SigningKey is a private key signing tokens, identified by kid header of the tokens
+type SigningKey struct {
+	ID        string    `json:"id"`
+	Algorithm string    `json:"algorithm"`
+	CreatedAt time.Time `json:"created_at"`
+	// ActivatesAt is when the key starts signing, it is published before, so verifiers caching the key set know it
+	ActivatesAt time.Time `json:"activates_at,omitempty"`
+	// RetiredAt is set once the key is replaced, its public key is published until tokens signed by it expire
+	RetiredAt time.Time `json:"retired_at,omitempty"`
+	// Private is PKCS #8 encoded private key
+	Private []byte `json:"private"`
+
+	signer crypto.Signer
+}
+
+func (k *SigningKey) retired() bool {
+	return !k.RetiredAt.IsZero()
+}
+
+// Keyset signs tokens with the current key and verifies them with any key that is not expired yet.
+// Keys are rotated periodically, the next key is published JWKSCacheTTL before it replaces the current one.
+// Retired keys are kept for retention, which must be longer than lifetime of any token
+type Keyset struct {
+	mu      sync.RWMutex
+	keys    map[string]*SigningKey
+	current *SigningKey
+	// next is published, but does not sign yet
+	next *SigningKey
+
+	storage   KeyStorage
+	algorithm string
+	rotation  time.Duration
+	retention time.Duration
+}
+
+// NewKeyset loads keys from the storage, the new key is generated if there is no current key of the algorithm
+func NewKeyset(ctx context.Context, storage KeyStorage, algorithm string, rotation, retention time.Duration) (*Keyset, error) {
+	if _, err := signingMethod(algorithm); err != nil {
+		return nil, err
+	}
+
+	k := &Keyset{
+		keys:      make(map[string]*SigningKey),
+		storage:   storage,
+		algorithm: algorithm,
+		rotation:  rotation,
+		retention: retention,
+	}
+
+	if err := k.load(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := k.activate(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := k.ensureCurrent(ctx); err != nil {
+		return nil, err
+	}
+
+	// the algorithm was changed, key of the new algorithm replaces the current one once it is published long enough
+	if k.current.Algorithm != algorithm && (k.next == nil || k.next.Algorithm != algorithm) {
+		if err := k.Rotate(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// load replaces keys of the set by the keys saved in the storage, so keys published or dropped
+// by other replicas are seen
+func (k *Keyset) load(ctx context.Context) error {
+	stored, err := k.storage.ListSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*SigningKey, len(stored))
+
+	for _, key := range stored {
+		private, err := x509.ParsePKCS8PrivateKey(key.Private)
+		if err != nil {
+			return err
+		}
+
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return ErrUnknownAlgorithm
+		}
+		key.signer = signer
+
+		// keys saved before the activation was introduced signed right away
+		if key.ActivatesAt.IsZero() {
+			key.ActivatesAt = key.CreatedAt
+		}
+
+		keys[key.ID] = key
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys = keys
+
+	return nil
+}
+
+// Rotate publishes a new key, which replaces the current one after JWKSCacheTTL.
+// The previous key is kept to verify the tokens it has signed
+func (k *Keyset) Rotate(ctx context.Context) error {
+	return k.publish(ctx, time.Now().Add(JWKSCacheTTL))
+}
+
+// publish saves a new key, which starts signing at activatesAt
+func (k *Keyset) publish(ctx context.Context, activatesAt time.Time) error {
+	key, err := generateSigningKey(k.algorithm)
+	if err != nil {
+		return err
+	}
+	key.ActivatesAt = activatesAt
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// the new key is saved first, so tokens are never signed by a key lost on restart
+	if err = k.storage.PutSigningKey(ctx, key); err != nil {
+		return err
+	}
+
+	k.keys[key.ID] = key
+	k.next = key
+
+	logger.Log.Info(
+		"signing key published",
+		zap.String("kid", key.ID),
+		zap.String("algorithm", key.Algorithm),
+		zap.Time("activates_at", key.ActivatesAt),
+	)
+
+	return nil
+}
+
+// ensureCurrent publishes a key signing right away if there is nothing to sign with, e.g. on the first start
+// or when the keys were dropped from the storage by another replica
+func (k *Keyset) ensureCurrent(ctx context.Context) error {
+	k.mu.RLock()
+	current := k.current
+	k.mu.RUnlock()
+
+	if current != nil {
+		return nil
+	}
+
+	if err := k.publish(ctx, time.Now()); err != nil {
+		return err
+	}
+
+	return k.activate(ctx)
+}
+
+// activate makes the latest key, which activation time has come, current and retires the keys it replaces
+func (k *Keyset) activate(ctx context.Context) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+
+	var current, next *SigningKey
+	for _, key := range k.keys {
+		switch {
+		case key.retired():
+		case key.ActivatesAt.After(now):
+			if next == nil || key.ActivatesAt.After(next.ActivatesAt) {
+				next = key
+			}
+		case current == nil || key.ActivatesAt.After(current.ActivatesAt):
+			current = key
+		}
+	}
+
+	for id, key := range k.keys {
+		if key == current || key.retired() || key.ActivatesAt.After(now) {
+			continue
+		}
+
+		retired := *key
+		retired.RetiredAt = now
+
+		if err := k.storage.PutSigningKey(ctx, &retired); err != nil {
+			return err
+		}
+
+		k.keys[id] = &retired
+	}
+
+	if current != nil && k.current != nil && current.ID != k.current.ID {
+		logger.Log.Info(
+			"signing key rotated",
+			zap.String("kid", current.ID),
+			zap.String("algorithm", current.Algorithm),
+		)
+	}
+
+	k.current, k.next = current, next
+
+	return nil
+}
+
+// RunRotation publishes the next key, so it replaces the current one once the current key is older than the rotation period.
+// Retired keys are dropped after retention. Keys are reloaded from the storage on every check, so replicas sharing it agree on them
+func (k *Keyset) RunRotation(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			k.check(ctx)
+		}
+	}
+}
+
+// check is a single rotation check of RunRotation
+func (k *Keyset) check(ctx context.Context) {
+	if err := k.load(ctx); err != nil {
+		logger.Log.Error(
+			"error loading signing keys",
+			zap.Error(err),
+		)
+		return
+	}
+
+	if err := k.activate(ctx); err != nil {
+		logger.Log.Error(
+			"error activating signing key",
+			zap.Error(err),
+		)
+	}
+
+	if err := k.ensureCurrent(ctx); err != nil {
+		logger.Log.Error(
+			"error publishing signing key",
+			zap.Error(err),
+		)
+		return
+	}
+
+	k.mu.RLock()
+	due := k.next == nil && time.Since(k.current.ActivatesAt) >= k.rotation-JWKSCacheTTL
+	k.mu.RUnlock()
+
+	if due {
+		if err := k.Rotate(ctx); err != nil {
+			logger.Log.Error(
+				"error rotating signing key",
+				zap.Error(err),
+			)
+		}
+	}
+
+	if err := k.prune(ctx); err != nil {
+		logger.Log.Error(
+			"error dropping retired signing keys",
+			zap.Error(err),
+		)
+	}
+}
+
+// JWKS returns public keys in JSON Web Key Set format, so other services can verify tokens
+func (k *Keyset) JWKS() ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := struct {
+		Keys []map[string]string `json:"keys"`
+	}{
+		Keys: make([]map[string]string, 0, len(k.keys)),
+	}
+
+	for _, key := range k.keys {
+		jwk := map[string]string{
+			"kid": key.ID,
+			"alg": key.Algorithm,
+			"use": "sig",
+		}
+
+		switch public := key.signer.Public().(type) {
+		case ed25519.PublicKey:
+			jwk["kty"] = "OKP"
+			jwk["crv"] = "Ed25519"
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return json.Marshal(set)
+}
+
+// sign signs the claims by the current key and sets its kid header
+func (k *Keyset) sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	key := k.current
+	k.mu.RUnlock()
+
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+
+	method, err := signingMethod(key.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.signer)
+}
+
+// verificationKey returns public key of the token by its kid header
+func (k *Keyset) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	k.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	// algorithm is taken from the key, so the token can't switch verification to another one
+	if token.Method.Alg() != key.Algorithm {
+		return nil, ErrInvalidToken
+	}
+
+	return key.signer.Public(), nil
+}
+
+func (k *Keyset) prune(ctx context.Context) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for id, key := range k.keys {
+		if !key.retired() || time.Since(key.RetiredAt) < k.retention {
+			continue
+		}
+
+		if err := k.storage.DeleteSigningKey(ctx, id); err != nil {
+			return err
+		}
+
+		delete(k.keys, id)
+
+		logger.Log.Info(
+			"retired signing key dropped",
+			zap.String("kid", id),
+		)
+	}
+
+	return nil
+}
+
+func generateSigningKey(algorithm string) (*SigningKey, error) {
+	var (
+		signer crypto.Signer
+		err    error
+	)
+
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	case AlgorithmRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		return nil, ErrUnknownAlgorithm
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	private, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 8)
+	if _, err = rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		ID:        hex.EncodeToString(id),
+		Algorithm: algorithm,
+		CreatedAt: time.Now(),
+		Private:   private,
+		signer:    signer,
+	}, nil
+}
+
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256, nil
+	}
+
+	return nil, ErrUnknownAlgorithm
+}
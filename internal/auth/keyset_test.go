@@ -0,0 +1,399 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v4"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memKeyStorage keeps signing keys in memory in the order they were published
+type memKeyStorage struct {
+	mu    sync.Mutex
+	keys  map[string]SigningKey
+	order []string
+}
+
+func newMemKeyStorage() *memKeyStorage {
+	return &memKeyStorage{
+		keys: make(map[string]SigningKey),
+	}
+}
+
+func (s *memKeyStorage) ListSigningKeys(ctx context.Context) ([]*SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]*SigningKey, 0, len(s.keys))
+	for _, id := range s.order {
+		if key, ok := s.keys[id]; ok {
+			keys = append(keys, &key)
+		}
+	}
+
+	return keys, nil
+}
+
+func (s *memKeyStorage) PutSigningKey(ctx context.Context, key *SigningKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[key.ID]; !ok {
+		s.order = append(s.order, key.ID)
+	}
+
+	stored := *key
+	stored.signer = nil
+	s.keys[key.ID] = stored
+
+	return nil
+}
+
+func (s *memKeyStorage) DeleteSigningKey(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, id)
+
+	return nil
+}
+
+// id returns id of the n-th published key
+func (s *memKeyStorage) id(n int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n < 0 || n >= len(s.order) {
+		return ""
+	}
+
+	return s.order[n]
+}
+
+// age moves activation and retirement of the stored keys back by d, as if d has passed
+func (s *memKeyStorage) age(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, key := range s.keys {
+		key.CreatedAt = key.CreatedAt.Add(-d)
+		key.ActivatesAt = key.ActivatesAt.Add(-d)
+		if !key.RetiredAt.IsZero() {
+			key.RetiredAt = key.RetiredAt.Add(-d)
+		}
+		s.keys[id] = key
+	}
+}
+
+func publishedKeys(t *testing.T, k *Keyset) []string {
+	t.Helper()
+
+	raw, err := k.JWKS()
+	if err != nil {
+		t.Fatalf("JWKS() error = %v", err)
+	}
+
+	set := struct {
+		Keys []map[string]string `json:"keys"`
+	}{}
+	if err = json.Unmarshal(raw, &set); err != nil {
+		t.Fatalf("JWKS() returned invalid json: %v", err)
+	}
+
+	ids := make([]string, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		ids = append(ids, jwk["kid"])
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+func signTestToken(t *testing.T, k *Keyset) string {
+	t.Helper()
+
+	token, err := k.sign(&Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Username: "alice",
+	})
+	if err != nil {
+		t.Fatalf("sign() error = %v", err)
+	}
+
+	return token
+}
+
+func keyID(key *SigningKey) string {
+	if key == nil {
+		return ""
+	}
+
+	return key.ID
+}
+
+// tamper changes one character in the middle of the token signature
+func tamper(token string) string {
+	raw := []byte(token)
+
+	i := len(raw) - 10
+	if raw[i] == 'A' {
+		raw[i] = 'B'
+	} else {
+		raw[i] = 'A'
+	}
+
+	return string(raw)
+}
+
+func verifyTestToken(k *Keyset, token string) error {
+	_, err := jwt.ParseWithClaims(token, &Claims{}, k.verificationKey)
+	return err
+}
+
+func tokenKID(t *testing.T, token string) string {
+	t.Helper()
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified() error = %v", err)
+	}
+
+	kid, _ := parsed.Header["kid"].(string)
+
+	return kid
+}
+
+func TestKeysetRotation(t *testing.T) {
+	ctx := context.Background()
+	rotation, retention := time.Hour*24, time.Hour*48
+
+	storage := newMemKeyStorage()
+
+	k, err := NewKeyset(ctx, storage, AlgorithmEdDSA, rotation, retention)
+	if err != nil {
+		t.Fatalf("NewKeyset() error = %v", err)
+	}
+
+	first := signTestToken(t, k)
+
+	// every step lets the time pass and runs the rotation check, keys are numbered in the order they were published
+	tests := []struct {
+		name      string
+		passed    time.Duration
+		current   int
+		next      int
+		published []int
+		// firstErr is the error verifying the token signed by the first key
+		firstErr error
+	}{
+		{name: "fresh key", passed: 0, current: 0, next: -1, published: []int{0}},
+		{name: "before the rotation period", passed: rotation - JWKSCacheTTL - time.Minute, current: 0, next: -1, published: []int{0}},
+		{name: "next key published before it signs", passed: time.Minute, current: 0, next: 1, published: []int{0, 1}},
+		{name: "next key published once", passed: time.Minute, current: 0, next: 1, published: []int{0, 1}},
+		{name: "next key signs after the cache lifetime", passed: JWKSCacheTTL, current: 1, next: -1, published: []int{0, 1}},
+		{name: "retired key is kept for retention", passed: retention - time.Minute, current: 1, next: 2, published: []int{0, 1, 2}},
+		{name: "retired key is dropped after retention", passed: time.Minute, current: 1, next: 2, published: []int{1, 2}, firstErr: ErrUnknownKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage.age(tt.passed)
+			k.check(ctx)
+
+			k.mu.RLock()
+			current, next := k.current, k.next
+			k.mu.RUnlock()
+
+			if currentID := keyID(current); currentID != storage.id(tt.current) {
+				t.Errorf("current key = %s, want key %d", currentID, tt.current)
+			}
+			if nextID := keyID(next); nextID != storage.id(tt.next) {
+				t.Errorf("next key = %s, want key %d", nextID, tt.next)
+			}
+
+			want := make([]string, 0, len(tt.published))
+			for _, n := range tt.published {
+				want = append(want, storage.id(n))
+			}
+			slices.Sort(want)
+
+			if got := publishedKeys(t, k); !slices.Equal(got, want) {
+				t.Errorf("JWKS() keys = %v, want %v", got, want)
+			}
+
+			if kid := tokenKID(t, signTestToken(t, k)); kid != storage.id(tt.current) {
+				t.Errorf("token is signed by %s, want key %d", kid, tt.current)
+			}
+
+			if err := verifyTestToken(k, first); !errors.Is(err, tt.firstErr) {
+				t.Errorf("verifying token of the first key error = %v, want %v", err, tt.firstErr)
+			}
+		})
+	}
+}
+
+func TestKeysetRestart(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemKeyStorage()
+
+	k, err := NewKeyset(ctx, storage, AlgorithmEdDSA, time.Hour*24, time.Hour*48)
+	if err != nil {
+		t.Fatalf("NewKeyset() error = %v", err)
+	}
+
+	token := signTestToken(t, k)
+
+	if err = k.Rotate(ctx); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		current   int
+		next      int
+	}{
+		// the published key waits for its activation after restart
+		{name: "same algorithm", algorithm: AlgorithmEdDSA, current: 0, next: 1},
+		// key of the new algorithm is published, the current one keeps signing meanwhile
+		{name: "algorithm changed", algorithm: AlgorithmRS256, current: 0, next: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restarted, err := NewKeyset(ctx, storage, tt.algorithm, time.Hour*24, time.Hour*48)
+			if err != nil {
+				t.Fatalf("NewKeyset() error = %v", err)
+			}
+
+			if restarted.current.ID != storage.id(tt.current) || restarted.next.ID != storage.id(tt.next) {
+				t.Errorf("NewKeyset() current %s, next %s, want keys %d and %d",
+					restarted.current.ID, restarted.next.ID, tt.current, tt.next)
+			}
+			if restarted.next.Algorithm != tt.algorithm {
+				t.Errorf("NewKeyset() next key algorithm = %s, want %s", restarted.next.Algorithm, tt.algorithm)
+			}
+
+			if err = verifyTestToken(restarted, token); err != nil {
+				t.Errorf("token signed before restart is rejected: %v", err)
+			}
+		})
+	}
+}
+
+func TestKeysetVerify(t *testing.T) {
+	ctx := context.Background()
+
+	k, err := NewKeyset(ctx, newMemKeyStorage(), AlgorithmEdDSA, time.Hour*24, time.Hour*48)
+	if err != nil {
+		t.Fatalf("NewKeyset() error = %v", err)
+	}
+
+	other, err := NewKeyset(ctx, newMemKeyStorage(), AlgorithmEdDSA, time.Hour*24, time.Hour*48)
+	if err != nil {
+		t.Fatalf("NewKeyset() error = %v", err)
+	}
+
+	token := signTestToken(t, k)
+
+	// HS256 token with kid of the key, signed by the public key as a secret
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{Username: "alice"})
+	confused.Header["kid"] = k.current.ID
+	confusedToken, err := confused.SignedString([]byte(k.current.signer.Public().(ed25519.PublicKey)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{name: "signed by the current key", token: token},
+		{name: "signed by another key set", token: signTestToken(t, other), want: ErrUnknownKey},
+		{name: "algorithm of the key switched", token: confusedToken, want: ErrInvalidToken},
+		{name: "tampered signature", token: tamper(token), want: jwt.ErrEd25519Verification},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifyTestToken(k, tt.token); !errors.Is(err, tt.want) {
+				t.Errorf("verifying token error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err = NewKeyset(ctx, newMemKeyStorage(), "HS256", time.Hour, time.Hour); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Errorf("NewKeyset() with unknown algorithm error = %v, want %v", err, ErrUnknownAlgorithm)
+	}
+}
+
+func TestKeysetNoCurrentKey(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// lose leaves the storage without a key able to sign
+		lose func(storage *memKeyStorage)
+	}{
+		{
+			name: "keys deleted by another replica",
+			lose: func(storage *memKeyStorage) {
+				for _, id := range slices.Clone(storage.order) {
+					storage.DeleteSigningKey(ctx, id)
+				}
+			},
+		},
+		{
+			name: "all keys retired",
+			lose: func(storage *memKeyStorage) {
+				storage.mu.Lock()
+				defer storage.mu.Unlock()
+
+				for id, key := range storage.keys {
+					key.RetiredAt = time.Now()
+					storage.keys[id] = key
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMemKeyStorage()
+
+			k, err := NewKeyset(ctx, storage, AlgorithmEdDSA, time.Hour*24, time.Hour*48)
+			if err != nil {
+				t.Fatalf("NewKeyset() error = %v", err)
+			}
+
+			tt.lose(storage)
+			k.check(ctx)
+
+			k.mu.RLock()
+			current := k.current
+			k.mu.RUnlock()
+
+			// the new key signs right away
+			if current == nil || current.ActivatesAt.After(time.Now()) {
+				t.Fatalf("current key = %+v after check, want an active key", current)
+			}
+
+			if kid := tokenKID(t, signTestToken(t, k)); kid != current.ID {
+				t.Errorf("token is signed by %s, want %s", kid, current.ID)
+			}
+		})
+	}
+
+	k := &Keyset{keys: make(map[string]*SigningKey)}
+	if _, err := k.sign(&Claims{Username: "alice"}); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("sign() without current key error = %v, want %v", err, ErrNoSigningKey)
+	}
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/renatus-cartesius/metricserv/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/peer"
+	"net"
+	"time"
+)
+
+var (
+	ErrLockedOut = errors.New("too many failed login attempts")
+)
+
+// LoginAttempts counts failed login attempts of one username or peer address
+type LoginAttempts struct {
+	Failures    int       `json:"failures"`
+	LastFailure time.Time `json:"last_failure"`
+}
+
+// LockoutPolicy delays login attempts after repeated failures. Every failure after the free ones
+// doubles the delay before the next attempt, until the caller is locked out for MaxDelay
+type LockoutPolicy struct {
+	// FreeAttempts is how many failures of the username are allowed before the backoff starts
+	FreeAttempts int
+	// PeerFreeAttempts is the same for one peer address, which may be shared by many users behind NAT
+	PeerFreeAttempts int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	// Window is how long failures are remembered after the last one
+	Window time.Duration
+}
+
+// DefaultLockoutPolicy locks the username out for 15 minutes after 13 failures in a row
+var DefaultLockoutPolicy = LockoutPolicy{
+	FreeAttempts:     3,
+	PeerFreeAttempts: 20,
+	BaseDelay:        time.Second,
+	MaxDelay:         time.Minute * 15,
+	Window:           time.Hour * 24,
+}
+
+// LockedOutError is returned when the caller has to wait before the next login attempt
+type LockedOutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedOutError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLockedOut, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockedOutError) Is(target error) bool {
+	return target == ErrLockedOut
+}
+
+// delay returns how long the caller waits after the failure
+func (p *LockoutPolicy) delay(failures, free int) time.Duration {
+	if failures < free {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := free; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.MaxDelay)
+}
+
+// attemptKeys returns keys the attempt is counted by, peer key is empty if the address is unknown
+func attemptKeys(ctx context.Context, username []byte) (userKey, peerKey []byte) {
+	userKey = append([]byte("user/"), username...)
+
+	host := peerHost(ctx)
+	if host == "" {
+		return userKey, nil
+	}
+
+	return userKey, []byte("peer/" + host)
+}
+
+// peerHost returns address of the peer without port or empty string if it is unknown
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// reserveAttempt counts the attempt as failed before the credentials are checked, so concurrent attempts
+// can't pass the lockout together. LockedOutError is returned if the username or the peer has to wait.
+// The attempt is taken back by releaseAttempt unless the credentials turn out to be wrong
+func (a *LocalAuth) reserveAttempt(ctx context.Context, username []byte) error {
+	userKey, peerKey := attemptKeys(ctx, username)
+
+	// unknown usernames are counted too, so the lockout does not tell whether the user exists
+	if err := a.addFailure(ctx, userKey, a.lockout.FreeAttempts); err != nil {
+		return err
+	}
+
+	if peerKey == nil {
+		return nil
+	}
+
+	if err := a.addFailure(ctx, peerKey, a.lockout.PeerFreeAttempts); err != nil {
+		if releaseErr := a.storage.RemoveLoginFailure(ctx, userKey); releaseErr != nil {
+			return releaseErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (a *LocalAuth) addFailure(ctx context.Context, key []byte, free int) error {
+	attempts, err := a.storage.AddLoginFailure(ctx, key, a.lockout.Window, func(attempts *LoginAttempts) error {
+		retryAfter := time.Until(attempts.LastFailure.Add(a.lockout.delay(attempts.Failures, free)))
+		if retryAfter > 0 {
+			return &LockedOutError{
+				RetryAfter: retryAfter,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the attempt in progress is not a failure yet
+	if attempts.Failures > free {
+		logger.Log.Warn(
+			"repeated failed login attempts",
+			zap.ByteString("key", key),
+			zap.Int("failures", attempts.Failures-1),
+		)
+	}
+
+	return nil
+}
+
+// releaseAttempt takes back the attempt reserved by reserveAttempt, once the credentials are correct
+// or the check failed for reasons other than wrong credentials
+func (a *LocalAuth) releaseAttempt(ctx context.Context, username []byte) error {
+	userKey, peerKey := attemptKeys(ctx, username)
+
+	for _, key := range [][]byte{userKey, peerKey} {
+		if key == nil {
+			continue
+		}
+
+		if err := a.storage.RemoveLoginFailure(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resetFailures forgets failures of the username after successful login, failures of the peer expire by themselves
+func (a *LocalAuth) resetFailures(ctx context.Context, username []byte) error {
+	userKey, _ := attemptKeys(ctx, username)
+
+	return a.storage.ResetLoginAttempts(ctx, userKey)
+}
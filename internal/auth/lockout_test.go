@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutDelay(t *testing.T) {
+	p := &LockoutPolicy{
+		BaseDelay: time.Second,
+		MaxDelay:  time.Minute,
+	}
+
+	tests := []struct {
+		name     string
+		failures int
+		free     int
+		want     time.Duration
+	}{
+		{name: "no failures", failures: 0, free: 3, want: 0},
+		{name: "last free failure", failures: 2, free: 3, want: 0},
+		{name: "free attempts used", failures: 3, free: 3, want: time.Second},
+		{name: "doubled", failures: 4, free: 3, want: time.Second * 2},
+		{name: "doubled again", failures: 5, free: 3, want: time.Second * 4},
+		{name: "just under the max", failures: 8, free: 3, want: time.Second * 32},
+		{name: "capped", failures: 9, free: 3, want: time.Minute},
+		{name: "far beyond the max", failures: 1000, free: 3, want: time.Minute},
+		{name: "no free attempts", failures: 0, free: 0, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.delay(tt.failures, tt.free); got != tt.want {
+				t.Errorf("delay(%d, %d) = %v, want %v", tt.failures, tt.free, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+//go:embed breached.txt
+var breachedPasswords []byte
+
+var (
+	ErrWeakPassword    = errors.New("password does not satisfy the policy")
+	ErrInvalidUsername = errors.New("username must be 1 to 64 latin letters, digits, dots, underscores or hyphens")
+)
+
+// usernamePattern keeps the separator of storage keys out of usernames, so keys of one user never alias another's
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// CheckUsername returns ErrEmptyUsername or ErrInvalidUsername if username can't be registered
+func CheckUsername(username []byte) error {
+	if len(username) == 0 {
+		return ErrEmptyUsername
+	}
+
+	if !usernamePattern.Match(username) {
+		return ErrInvalidUsername
+	}
+
+	return nil
+}
+
+// PasswordPolicy is checked on registration and password change
+type PasswordPolicy struct {
+	MinLength int
+	// MinClasses is how many of lowercase, uppercase, digit and other character classes password must contain
+	MinClasses int
+
+	breached map[string]struct{}
+}
+
+// NewPasswordPolicy makes policy rejecting passwords from the bundled breached list and the lists located at breachedPaths,
+// one password per line
+func NewPasswordPolicy(minLength, minClasses int, breachedPaths ...string) (*PasswordPolicy, error) {
+	p := &PasswordPolicy{
+		MinLength:  minLength,
+		MinClasses: minClasses,
+		breached:   make(map[string]struct{}),
+	}
+
+	if err := p.loadBreached(bytes.NewReader(breachedPasswords)); err != nil {
+		return nil, err
+	}
+
+	for _, path := range breachedPaths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		err = p.loadBreached(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func (p *PasswordPolicy) loadBreached(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p.breached[strings.ToLower(line)] = struct{}{}
+	}
+
+	return scanner.Err()
+}
+
+// Check returns ErrWeakPassword wrapped with the reason if password does not satisfy the policy
+func (p *PasswordPolicy) Check(username, password []byte) error {
+	if len([]rune(string(password))) < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters long", ErrWeakPassword, p.MinLength)
+	}
+
+	if classes := characterClasses(string(password)); classes < p.MinClasses {
+		return fmt.Errorf("%w: must contain at least %d of lowercase, uppercase, digit and other characters", ErrWeakPassword, p.MinClasses)
+	}
+
+	lower := strings.ToLower(string(password))
+
+	if len(username) > 0 && strings.Contains(lower, strings.ToLower(string(username))) {
+		return fmt.Errorf("%w: must not contain username", ErrWeakPassword)
+	}
+
+	if _, ok := p.breached[lower]; ok {
+		return fmt.Errorf("%w: found in the list of breached passwords", ErrWeakPassword)
+	}
+
+	return nil
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, other int
+
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+
+	return lower + upper + digit + other
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/renatus-cartesius/metricserv/pkg/logger"
+	"github.com/renatus-cartesius/nedovault/api"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sort"
+	"time"
+)
+
+const (
+	// sessionTouchInterval limits how often the last seen time is saved, so requests don't write on every call
+	sessionTouchInterval = time.Minute
+	// maxClientInfoLength truncates device name and client version reported by the client
+	maxClientInfoLength = 64
+)
+
+// Metadata keys of the client info sent with the auth requests
+const (
+	deviceHeader        = "device-name"
+	clientVersionHeader = "client-version"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// Session is a login of the user on one device. Its ID is the family of the tokens issued by the login and its refreshes
+type Session struct {
+	ID            string `json:"id"`
+	Device        string `json:"device,omitempty"`
+	ClientVersion string `json:"client_version,omitempty"`
+	// Address is the peer address of the last request
+	Address   string    `json:"address,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	LastSeen  time.Time `json:"last_seen"`
+	// ExpiresAt is expiration of the last refresh token, the record is dropped after it
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ListSessions returns active sessions of the user, the session of the token with passed id is marked current
+func (a *LocalAuth) ListSessions(ctx context.Context, username []byte, tokenID string) ([]*api.Session, error) {
+	sessions, err := a.storage.ListSessions(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	var current string
+
+	tokenMeta, err := a.storage.GetToken(ctx, username, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if tokenMeta != nil {
+		current = tokenMeta.Family
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeen.After(sessions[j].LastSeen)
+	})
+
+	res := make([]*api.Session, 0, len(sessions))
+
+	for _, s := range sessions {
+		res = append(res, &api.Session{
+			Id:            s.ID,
+			Device:        s.Device,
+			ClientVersion: s.ClientVersion,
+			Address:       s.Address,
+			CreatedAt:     timestamppb.New(s.CreatedAt),
+			LastSeen:      timestamppb.New(s.LastSeen),
+			Current:       s.ID == current,
+		})
+	}
+
+	return res, nil
+}
+
+// RevokeSession revokes all tokens of the session, the device has to log in again
+func (a *LocalAuth) RevokeSession(ctx context.Context, username []byte, id string) error {
+	session, err := a.storage.GetSession(ctx, username, id)
+	if err != nil {
+		return err
+	}
+
+	if session == nil {
+		return ErrSessionNotFound
+	}
+
+	if err = a.storage.RevokeFamily(ctx, username, id); err != nil {
+		return err
+	}
+
+	logger.Log.Info(
+		"session revoked",
+		zap.String("username", string(username)),
+		zap.String("session", id),
+		zap.String("device", session.Device),
+	)
+
+	return nil
+}
+
+// startSession saves the session of the new login with the client info of the request, nothing is saved if the session was revoked
+func (a *LocalAuth) startSession(ctx context.Context, username []byte, family string, expiresAt time.Time) error {
+	device, version := clientInfo(ctx)
+	now := time.Now()
+
+	return a.storage.PutSession(ctx, username, &Session{
+		ID:            family,
+		Device:        device,
+		ClientVersion: version,
+		Address:       peerHost(ctx),
+		CreatedAt:     now,
+		LastSeen:      now,
+		ExpiresAt:     expiresAt,
+	})
+}
+
+// touchSession updates the last seen time and address of the session, the session is prolonged if expiresAt is set.
+// Sessions of logins made before sessions were introduced are started on refresh, revoked sessions are left as is
+func (a *LocalAuth) touchSession(ctx context.Context, username []byte, family string, expiresAt time.Time) error {
+	session, err := a.storage.GetSession(ctx, username, family)
+	if err != nil {
+		return err
+	}
+
+	if session == nil {
+		if expiresAt.IsZero() {
+			return nil
+		}
+		return a.startSession(ctx, username, family, expiresAt)
+	}
+
+	address := peerHost(ctx)
+
+	if expiresAt.IsZero() && address == session.Address && time.Since(session.LastSeen) < sessionTouchInterval {
+		return nil
+	}
+
+	session.LastSeen = time.Now()
+	session.Address = address
+
+	if expiresAt.After(session.ExpiresAt) {
+		session.ExpiresAt = expiresAt
+	}
+
+	// client info is updated on refresh, as the client may be upgraded during the session
+	if !expiresAt.IsZero() {
+		if device, version := clientInfo(ctx); device != "" || version != "" {
+			session.Device, session.ClientVersion = device, version
+		}
+	}
+
+	return a.storage.TouchSession(ctx, username, session)
+}
+
+// clientInfo returns device name and client version sent by the client in the request metadata
+func clientInfo(ctx context.Context) (device, version string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ""
+	}
+
+	value := func(key string) string {
+		values := md.Get(key)
+		if len(values) == 0 {
+			return ""
+		}
+
+		v := []rune(values[0])
+		if len(v) > maxClientInfoLength {
+			v = v[:maxClientInfoLength]
+		}
+
+		return string(v)
+	}
+
+	return value(deviceHeader), value(clientVersionHeader)
+}
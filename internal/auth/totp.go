@@ -0,0 +1,391 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/renatus-cartesius/metricserv/pkg/logger"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/otp"
+	"go.uber.org/zap"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	totpIssuer = "nedovault"
+	// totpSeedSize is a size of the seed recommended by RFC 4226
+	totpSeedSize = 20
+	// totpSkew is how many time steps before and after the current one codes are accepted, to tolerate clock drift
+	totpSkew = 1
+
+	recoveryCodesCount = 10
+	recoveryCodeLength = 10
+
+	// challengeTTL limits time between the password check and the second factor
+	challengeTTL = time.Minute * 5
+)
+
+var (
+	ErrTOTPEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnrolled = errors.New("two-factor authentication is not enrolled")
+	ErrInvalidCode     = errors.New("invalid two-factor authentication code")
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPMeta is the second factor of the account
+type TOTPMeta struct {
+	// Secret is base32 seed shared with the authenticator app
+	Secret string
+	// Confirmed is set once the user proved the seed is saved, only confirmed second factor is required on login
+	Confirmed bool
+	// LastCounter is the time step of the last accepted code, so codes can't be replayed
+	LastCounter uint64
+	// RecoveryCodes are sha256 hashes of unused recovery codes
+	RecoveryCodes [][]byte
+}
+
+// EnrollTOTP generates a new seed and recovery codes of the user, they replace the previous unconfirmed enrollment.
+// The password is checked again, so a stolen token can't enable the second factor locking out the user
+func (a *LocalAuth) EnrollTOTP(ctx context.Context, username, password []byte) (*api.EnrollTOTPResponse, error) {
+	meta, err := a.checkPassword(ctx, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	if meta.TOTP != nil && meta.TOTP.Confirmed {
+		return nil, ErrTOTPEnabled
+	}
+
+	seed := make([]byte, totpSeedSize)
+	if _, err = rand.Read(seed); err != nil {
+		return nil, err
+	}
+
+	secret := base32NoPadding.EncodeToString(seed)
+
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([][]byte, 0, recoveryCodesCount)
+
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	err = a.storage.UpdateAuthMeta(ctx, username, func(meta *Meta) error {
+		if meta.TOTP != nil && meta.TOTP.Confirmed {
+			return ErrTOTPEnabled
+		}
+
+		meta.TOTP = &TOTPMeta{
+			Secret:        secret,
+			RecoveryCodes: hashes,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uri := fmt.Sprintf(
+		"otpauth://totp/%s:%s?secret=%s&issuer=%s",
+		url.PathEscape(totpIssuer),
+		url.PathEscape(string(username)),
+		secret,
+		url.QueryEscape(totpIssuer),
+	)
+
+	return &api.EnrollTOTPResponse{
+		Secret:        secret,
+		Uri:           uri,
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// ConfirmTOTP enables the enrolled second factor once the user passes a valid code from the authenticator app
+func (a *LocalAuth) ConfirmTOTP(ctx context.Context, username []byte, code string) error {
+	err := a.storage.UpdateAuthMeta(ctx, username, func(meta *Meta) error {
+		if meta.TOTP == nil {
+			return ErrTOTPNotEnrolled
+		}
+
+		if meta.TOTP.Confirmed {
+			return ErrTOTPEnabled
+		}
+
+		counter, ok := checkTOTP(meta.TOTP, code, time.Now())
+		if !ok {
+			return ErrInvalidCode
+		}
+
+		meta.TOTP.Confirmed = true
+		meta.TOTP.LastCounter = counter
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Log.Info(
+		"two-factor authentication enabled",
+		zap.String("username", string(username)),
+	)
+
+	return nil
+}
+
+// DisableTOTP removes the second factor, the user has to pass the password and a code
+func (a *LocalAuth) DisableTOTP(ctx context.Context, username, password []byte, code string) error {
+	meta, err := a.checkPassword(ctx, username, password)
+	if err != nil {
+		return err
+	}
+
+	if meta.TOTP == nil {
+		return ErrTOTPNotEnrolled
+	}
+
+	if meta.TOTP.Confirmed {
+		err = a.verifyCode(ctx, username, code, func(meta *Meta) {
+			meta.TOTP = nil
+		})
+	} else {
+		// unconfirmed enrollment is dropped without code, as the seed may be lost, unless it was confirmed meanwhile
+		err = a.storage.UpdateAuthMeta(ctx, username, func(meta *Meta) error {
+			if meta.TOTP != nil && meta.TOTP.Confirmed {
+				return ErrTOTPEnabled
+			}
+
+			meta.TOTP = nil
+
+			return nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Log.Info(
+		"two-factor authentication disabled",
+		zap.String("username", string(username)),
+	)
+
+	return nil
+}
+
+// VerifyTOTP completes login of the user with the challenge returned by Login and the code.
+// Challenge is single-use, after a wrong code the user has to pass the password again
+func (a *LocalAuth) VerifyTOTP(ctx context.Context, challenge []byte, code string) (*LoginResult, error) {
+	claims, err := a.parseClaims(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Type != TokenChallenge {
+		return nil, ErrInvalidToken
+	}
+
+	username := []byte(claims.Username)
+
+	tokenMeta, err := a.storage.GetToken(ctx, username, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenMeta == nil || tokenMeta.Revoked {
+		return nil, ErrRevokedToken
+	}
+
+	var salt []byte
+
+	err = a.verifyCode(ctx, username, code, func(meta *Meta) {
+		salt = meta.Salt
+	})
+	if err != nil {
+		if revokeErr := a.storage.RevokeToken(ctx, username, claims.ID); revokeErr != nil {
+			return nil, revokeErr
+		}
+		return nil, err
+	}
+
+	if err = a.resetFailures(ctx, username); err != nil {
+		return nil, err
+	}
+
+	pair, tokens, err := a.newTokenPair(username, tokenMeta.Family)
+	if err != nil {
+		return nil, err
+	}
+
+	// challenge is used by concurrent request with the same code
+	if err = a.storage.RotateToken(ctx, username, claims.ID, tokens...); err != nil {
+		if errors.Is(err, ErrTokenReused) {
+			return nil, ErrRevokedToken
+		}
+		return nil, err
+	}
+
+	if err = a.startSession(ctx, username, tokenMeta.Family, tokens[len(tokens)-1].ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{
+		Tokens: pair,
+		Salt:   salt,
+	}, nil
+}
+
+// issueChallenge starts login session waiting for the second factor
+func (a *LocalAuth) issueChallenge(ctx context.Context, username []byte) (string, error) {
+	now := time.Now()
+
+	tokenMeta := &TokenMeta{
+		ID:        uuid.NewString(),
+		Family:    uuid.NewString(),
+		Type:      TokenChallenge,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(challengeTTL),
+	}
+
+	challenge, err := a.signToken(username, tokenMeta)
+	if err != nil {
+		return "", err
+	}
+
+	if err = a.storage.AppendTokens(ctx, username, tokenMeta); err != nil {
+		return "", err
+	}
+
+	return challenge, nil
+}
+
+// verifyCode checks the code as a login attempt, wrong codes are counted as failed logins.
+// The used code and the update, if any, are saved in the same transaction as the code is checked in
+func (a *LocalAuth) verifyCode(ctx context.Context, username []byte, code string, update func(meta *Meta)) error {
+	if err := a.reserveAttempt(ctx, username); err != nil {
+		return err
+	}
+
+	err := a.storage.UpdateAuthMeta(ctx, username, func(meta *Meta) error {
+		if err := useCode(username, meta.TOTP, code); err != nil {
+			return err
+		}
+
+		if update != nil {
+			update(meta)
+		}
+
+		return nil
+	})
+	if errors.Is(err, ErrInvalidCode) {
+		return err
+	}
+
+	if releaseErr := a.releaseAttempt(ctx, username); releaseErr != nil {
+		return releaseErr
+	}
+
+	return err
+}
+
+// useCode accepts code from the authenticator app or one of the recovery codes, the used recovery code is removed
+func useCode(username []byte, t *TOTPMeta, code string) error {
+	if t == nil {
+		return ErrTOTPNotEnrolled
+	}
+
+	if counter, ok := checkTOTP(t, code, time.Now()); ok {
+		t.LastCounter = counter
+		return nil
+	}
+
+	hash := hashRecoveryCode(code)
+
+	for i, recoveryCode := range t.RecoveryCodes {
+		if subtle.ConstantTimeCompare(hash, recoveryCode) == 1 {
+			t.RecoveryCodes = append(t.RecoveryCodes[:i], t.RecoveryCodes[i+1:]...)
+
+			logger.Log.Info(
+				"recovery code used",
+				zap.String("username", string(username)),
+				zap.Int("left", len(t.RecoveryCodes)),
+			)
+
+			return nil
+		}
+	}
+
+	return ErrInvalidCode
+}
+
+func (a *LocalAuth) getMeta(ctx context.Context, username []byte) (*Meta, error) {
+	meta, err := a.storage.GetAuthMeta(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if meta == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return meta, nil
+}
+
+// checkTOTP returns the time step of the code if it is valid and was not used before
+func checkTOTP(t *TOTPMeta, code string, now time.Time) (uint64, bool) {
+	o := &api.Otp{
+		Secret: t.Secret,
+	}
+
+	current := uint64(now.Unix()) / otp.DefaultPeriod
+
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= t.LastCounter {
+			continue
+		}
+
+		expected, err := otp.Generate(o, time.Unix(int64(step*otp.DefaultPeriod), 0))
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+func newRecoveryCode() (string, error) {
+	raw := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32NoPadding.EncodeToString(raw))[:recoveryCodeLength]
+
+	return code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:], nil
+}
+
+// hashRecoveryCode hashes normalized code, recovery codes are random enough to not need a slow hash
+func hashRecoveryCode(code string) []byte {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+
+	sum := sha256.Sum256([]byte(code))
+	return sum[:]
+}
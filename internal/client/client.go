@@ -0,0 +1,151 @@
+// Package client contains helpers shared by nedovault clients
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/e2e"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var (
+	ErrCodeRequired = errors.New("account requires two-factor authentication code")
+)
+
+// Conn is the client of the vault with the session of its user and the offline replica of the secrets
+type Conn struct {
+	api.NedoVaultClient
+	Session *Session
+	Replica *Replica
+}
+
+// CodeFunc asks the user for the two-factor authentication code
+type CodeFunc func() (string, error)
+
+// Login authorizes user and returns context carrying the issued token and the salt of user encryption key.
+// Code is asked only if the account has two-factor authentication enabled
+func Login(ctx context.Context, client api.NedoVaultClient, username, password []byte, code CodeFunc) (context.Context, []byte, error) {
+	res, err := client.Login(ctx, &api.AuthRequest{
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if res.Challenge != "" {
+		if code == nil {
+			return nil, nil, ErrCodeRequired
+		}
+
+		c, err := code()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		res, err = client.VerifyTOTP(ctx, &api.VerifyTOTPRequest{
+			Challenge: res.Challenge,
+			Code:      c,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, "token", res.Token), res.Salt, nil
+}
+
+// Register creates account of the user and returns context carrying the issued token and the salt of user encryption key
+func Register(ctx context.Context, client api.NedoVaultClient, username, password []byte) (context.Context, []byte, error) {
+	res, err := client.Register(ctx, &api.AuthRequest{
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, "token", res.Token), res.Salt, nil
+}
+
+// Unlock derives the encryption key from the master password and checks it with the key check of the account.
+// Account without key check gets one once the key opens its secrets, so the first unlock of an empty vault sets it.
+// Check is skipped if the vault is unreachable, the offline replica encrypted with the key detects the wrong one then
+func Unlock(ctx context.Context, client api.NedoVaultClient, masterPassword, salt []byte) (*e2e.Cipher, error) {
+	cipher, err := e2e.NewCipher(masterPassword, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.GetKeyCheck(ctx, &emptypb.Empty{})
+	if status.Code(err) == codes.Unavailable {
+		return cipher, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.GetKeyCheck()) > 0 {
+		return cipher, cipher.VerifyKeyCheck(res.GetKeyCheck())
+	}
+
+	if err = openAnySecret(ctx, client, cipher); err != nil {
+		return nil, err
+	}
+
+	keyCheck, err := cipher.KeyCheck()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = client.SetKeyCheck(ctx, &api.KeyCheck{
+		KeyCheck: keyCheck,
+	})
+	// another client has set the key check meanwhile
+	if status.Code(err) == codes.AlreadyExists {
+		if res, err = client.GetKeyCheck(ctx, &emptypb.Empty{}); err != nil {
+			return nil, err
+		}
+
+		return cipher, cipher.VerifyKeyCheck(res.GetKeyCheck())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher, nil
+}
+
+// openAnySecret returns ErrWrongMasterPassword if the first encrypted secret of the vault can't be opened,
+// secrets stored before encryption was introduced are skipped
+func openAnySecret(ctx context.Context, client api.NedoVaultClient, cipher *e2e.Cipher) error {
+	resp, err := client.ListSecretsMeta(ctx, &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+
+	for _, meta := range resp.GetSecretsMeta() {
+		secret, err := client.GetSecret(ctx, &api.GetSecretRequest{
+			Key: meta.GetKey(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, ok := secret.GetSecret().GetSecret().(*api.Secret_Encrypted); !ok {
+			continue
+		}
+
+		if _, err = cipher.Open(meta.GetKey(), secret.GetSecret()); err != nil {
+			return e2e.ErrWrongMasterPassword
+		}
+
+		return nil
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultProfileName is the profile used when there is no config file
+const DefaultProfileName = "local"
+
+var (
+	ErrInvalidConfig   = errors.New("invalid client config")
+	ErrProfileNotFound = errors.New("profile not found")
+)
+
+// Config is a set of named profiles, e.g. staging and production vaults
+type Config struct {
+	// DefaultProfile is used when the profile is not chosen, it may be omitted if there is only one profile
+	DefaultProfile string              `yaml:"default_profile"`
+	Profiles       map[string]*Profile `yaml:"profiles"`
+}
+
+// Profile is a vault the client connects to
+type Profile struct {
+	Name    string     `yaml:"-"`
+	Address string     `yaml:"address"`
+	TLS     TLSProfile `yaml:"tls"`
+	// Username is the default username of the commands and the login page
+	Username string `yaml:"username"`
+	LogLevel string `yaml:"log_level"`
+}
+
+type TLSProfile struct {
+	// CA is the bundle the server certificate is verified against, system roots are used if it is empty
+	CA string `yaml:"ca"`
+	// Cert and Key are the client certificate presented to servers with mutual TLS
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+	// Plaintext connects without TLS, e.g. to a vault on the same host
+	Plaintext bool `yaml:"plaintext"`
+}
+
+// DefaultConfigPath returns ~/.config/nedovault/config.yaml or its equivalent on the platform
+func DefaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "nedovault", "config.yaml"), nil
+}
+
+// DefaultConfig has the only profile of the vault started locally with certificates issued by gen-certs
+func DefaultConfig() *Config {
+	return &Config{
+		DefaultProfile: DefaultProfileName,
+		Profiles: map[string]*Profile{
+			DefaultProfileName: {
+				Name:     DefaultProfileName,
+				Address:  "127.0.0.1:1337",
+				TLS:      TLSProfile{CA: "./certs/ca.pem"},
+				LogLevel: "INFO",
+			},
+		},
+	}
+}
+
+// LoadConfig reads the config file, DefaultConfig is returned if it does not exist.
+// Relative paths of the certificates are resolved against the directory of the file
+func LoadConfig(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultConfig(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{}
+
+	// unknown keys are rejected, so typos are not silently ignored
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+
+	if err = decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %s: %s", ErrInvalidConfig, path, err)
+	}
+
+	if len(c.Profiles) == 0 {
+		return nil, fmt.Errorf("%w: %s: no profiles", ErrInvalidConfig, path)
+	}
+
+	for name, p := range c.Profiles {
+		if p == nil {
+			return nil, fmt.Errorf("%w: profile %s is empty", ErrInvalidConfig, name)
+		}
+
+		p.Name = name
+
+		if p.LogLevel == "" {
+			p.LogLevel = "INFO"
+		}
+
+		if err = p.validate(); err != nil {
+			return nil, err
+		}
+
+		for _, certPath := range []*string{&p.TLS.CA, &p.TLS.Cert, &p.TLS.Key} {
+			*certPath = resolvePath(filepath.Dir(path), *certPath)
+		}
+	}
+
+	if c.DefaultProfile == "" && len(c.Profiles) == 1 {
+		for name := range c.Profiles {
+			c.DefaultProfile = name
+		}
+	}
+
+	if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+		return nil, fmt.Errorf("%w: default_profile must name one of the profiles", ErrInvalidConfig)
+	}
+
+	return c, nil
+}
+
+// Profile returns the profile by name, empty name returns the default one
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+
+	return p, nil
+}
+
+// ProfileList returns profiles sorted by name
+func (c *Config) ProfileList() []*Profile {
+	profiles := make([]*Profile, 0, len(c.Profiles))
+	for _, p := range c.Profiles {
+		profiles = append(profiles, p)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	return profiles
+}
+
+// Dial creates connection to the vault of the profile
+func (p *Profile) Dial(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	var (
+		creds credentials.TransportCredentials
+		err   error
+	)
+
+	if p.TLS.Plaintext {
+		creds = insecure.NewCredentials()
+	} else if creds, err = TransportCredentials(p.TLS.CA, p.TLS.Cert, p.TLS.Key); err != nil {
+		return nil, fmt.Errorf("profile %s: %w", p.Name, err)
+	}
+
+	return grpc.NewClient(p.Address, append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, opts...)...)
+}
+
+func (p *Profile) validate() error {
+	var errs []error
+
+	if p.Address == "" {
+		errs = append(errs, errors.New("address must be set"))
+	}
+
+	if _, err := zap.ParseAtomicLevel(p.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("unknown log level %q", p.LogLevel))
+	}
+
+	if (p.TLS.Cert == "") != (p.TLS.Key == "") {
+		errs = append(errs, ErrKeyPairPartial)
+	}
+
+	if p.TLS.Plaintext && (p.TLS.CA != "" || p.TLS.Cert != "") {
+		errs = append(errs, errors.New("plaintext profile must not have certificates"))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: profile %s: %w", ErrInvalidConfig, p.Name, errors.Join(errs...))
+}
+
+// resolvePath expands ~ and makes relative path relative to dir
+func resolvePath(dir, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,336 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/chacha20poly1305"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Files of the credential store, the key never leaves the machine
+const (
+	credentialsFile    = "credentials"
+	credentialsKeyFile = "credentials.key"
+	// credentialsLockFile serializes access of the processes sharing the store
+	credentialsLockFile = "credentials.lock"
+)
+
+var (
+	ErrNoCredentials       = errors.New("no saved credentials")
+	ErrInsecurePermissions = errors.New("credentials are accessible by other users, expected 0600 permissions")
+	ErrCorruptCredentials  = errors.New("credentials can't be decrypted")
+)
+
+// Credentials are tokens of the logged in user with the salt of the encryption key,
+// so the session is reused and only the master password is asked
+type Credentials struct {
+	Username     string    `json:"username"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	Salt         []byte    `json:"salt"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Expired reports if the session can't be used anymore, the access token is refreshed until the refresh token expires
+func (c *Credentials) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt)
+}
+
+// CredentialStore keeps credentials of the profiles in a file encrypted with a key generated on the first save.
+// Both files are readable only by the owner
+type CredentialStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// DefaultCredentialsDir returns ~/.cache/nedovault or its equivalent on the platform
+func DefaultCredentialsDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "nedovault"), nil
+}
+
+func NewCredentialStore(dir string) *CredentialStore {
+	return &CredentialStore{
+		dir: dir,
+	}
+}
+
+// Load returns credentials of the profile, expired credentials are removed
+func (cs *CredentialStore) Load(profile string) (*Credentials, error) {
+	unlock, err := cs.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	all, err := cs.read()
+	if err != nil {
+		return nil, err
+	}
+
+	creds, ok := all[profile]
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	if creds.Expired(time.Now()) {
+		delete(all, profile)
+		return nil, errors.Join(ErrNoCredentials, cs.write(all))
+	}
+
+	return creds, nil
+}
+
+// Save replaces credentials of the profile
+func (cs *CredentialStore) Save(profile string, creds *Credentials) error {
+	unlock, err := cs.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return cs.save(profile, creds)
+}
+
+// Update replaces credentials of the profile with the ones returned by fn, nil removes them. Other processes
+// can't access the store until fn returns, so it may exchange the saved refresh token without racing them.
+// Saved credentials are passed to fn, nil if there are none or they are expired
+func (cs *CredentialStore) Update(profile string, fn func(saved *Credentials) *Credentials) error {
+	unlock, err := cs.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := cs.read()
+	if err != nil && !errors.Is(err, ErrCorruptCredentials) {
+		return err
+	}
+
+	saved := all[profile]
+	if saved != nil && saved.Expired(time.Now()) {
+		saved = nil
+	}
+
+	creds := fn(saved)
+	if creds == saved && saved != nil {
+		return nil
+	}
+
+	if creds == nil {
+		return cs.delete(profile)
+	}
+
+	return cs.save(profile, creds)
+}
+
+func (cs *CredentialStore) save(profile string, creds *Credentials) error {
+	all, err := cs.read()
+	if err != nil && !errors.Is(err, ErrCorruptCredentials) {
+		return err
+	}
+	if all == nil {
+		// unreadable credentials are replaced, they would be asked again anyway
+		all = make(map[string]*Credentials)
+	}
+
+	all[profile] = creds
+
+	return cs.write(all)
+}
+
+// Delete forgets credentials of the profile
+func (cs *CredentialStore) Delete(profile string) error {
+	unlock, err := cs.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return cs.delete(profile)
+}
+
+func (cs *CredentialStore) delete(profile string) error {
+	all, err := cs.read()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := all[profile]; !ok {
+		return nil
+	}
+
+	delete(all, profile)
+
+	return cs.write(all)
+}
+
+// lock acquires the store for the process and among other processes, the returned func releases it
+func (cs *CredentialStore) lock() (func(), error) {
+	cs.mu.Lock()
+
+	if err := os.MkdirAll(cs.dir, 0700); err != nil {
+		cs.mu.Unlock()
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(cs.dir, credentialsLockFile), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		cs.mu.Unlock()
+		return nil, err
+	}
+
+	if err = lockFile(f); err != nil {
+		f.Close()
+		cs.mu.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		// closing the file releases the lock
+		f.Close()
+		cs.mu.Unlock()
+	}, nil
+}
+
+func (cs *CredentialStore) read() (map[string]*Credentials, error) {
+	all := make(map[string]*Credentials)
+
+	sealed, err := readPrivate(filepath.Join(cs.dir, credentialsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := readPrivate(filepath.Join(cs.dir, credentialsKeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptCredentials, err)
+	}
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptCredentials, err)
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrCorruptCredentials
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(credentialsFile))
+	if err != nil {
+		return nil, ErrCorruptCredentials
+	}
+
+	if err = json.Unmarshal(plaintext, &all); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptCredentials, err)
+	}
+
+	return all, nil
+}
+
+func (cs *CredentialStore) write(all map[string]*Credentials) error {
+	if err := os.MkdirAll(cs.dir, 0700); err != nil {
+		return err
+	}
+
+	key, err := cs.key()
+	if err != nil {
+		return err
+	}
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+
+	return writePrivate(filepath.Join(cs.dir, credentialsFile), aead.Seal(nonce, nonce, plaintext, []byte(credentialsFile)))
+}
+
+// key reads the key of the store or generates it if there is none
+func (cs *CredentialStore) key() ([]byte, error) {
+	path := filepath.Join(cs.dir, credentialsKeyFile)
+
+	key, err := readPrivate(path)
+	if err == nil && len(key) == chacha20poly1305.KeySize {
+		return key, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	key = make([]byte, chacha20poly1305.KeySize)
+	if _, err = rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return key, writePrivate(path, key)
+}
+
+// readPrivate reads the file refusing it if other users have access to it.
+// Permissions are not checked on Windows, where the file is protected by the ACL of the user profile
+func readPrivate(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInsecurePermissions, path)
+	}
+
+	return os.ReadFile(path)
+}
+
+// writePrivate replaces the file atomically with the one readable only by the owner
+func writePrivate(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	// temporary file is created readable only by the owner
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// tokenExpiry returns expiration of the token without verifying it, zero time if it has none
+func tokenExpiry(token string) time.Time {
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil || claims.ExpiresAt == nil {
+		return time.Time{}
+	}
+
+	return claims.ExpiresAt.Time
+}
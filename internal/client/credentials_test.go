@@ -0,0 +1,126 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func testCredentials() *Credentials {
+	return &Credentials{
+		Username:     "alice",
+		AccessToken:  "access-token-of-alice",
+		RefreshToken: "refresh-token-of-alice",
+		Salt:         []byte("0123456789abcdef"),
+	}
+}
+
+func TestCredentialStoreEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	cs := NewCredentialStore(dir)
+
+	creds := testCredentials()
+	if err := cs.Save("default", creds); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	for _, file := range []string{credentialsFile, credentialsKeyFile} {
+		info, err := os.Stat(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("stat %s: %v", file, err)
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+			t.Errorf("%s permissions = %o, want 600", file, info.Mode().Perm())
+		}
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, credentialsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plain := range []string{creds.Username, creds.AccessToken, creds.RefreshToken} {
+		if bytes.Contains(raw, []byte(plain)) {
+			t.Errorf("stored credentials contain %q in plaintext", plain)
+		}
+	}
+
+	loaded, err := NewCredentialStore(dir).Load("default")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.AccessToken != creds.AccessToken || loaded.RefreshToken != creds.RefreshToken {
+		t.Errorf("Load() = %+v, want %+v", loaded, creds)
+	}
+}
+
+func TestCredentialStoreRead(t *testing.T) {
+	tests := []struct {
+		name string
+		// change breaks the saved store
+		change func(t *testing.T, dir string)
+		want   error
+	}{
+		{
+			name:   "saved",
+			change: func(t *testing.T, dir string) {},
+		},
+		{
+			name: "credentials readable by others",
+			change: func(t *testing.T, dir string) {
+				if err := os.Chmod(filepath.Join(dir, credentialsFile), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: ErrInsecurePermissions,
+		},
+		{
+			name: "key readable by others",
+			change: func(t *testing.T, dir string) {
+				if err := os.Chmod(filepath.Join(dir, credentialsKeyFile), 0640); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: ErrInsecurePermissions,
+		},
+		{
+			name: "key replaced",
+			change: func(t *testing.T, dir string) {
+				if err := writePrivate(filepath.Join(dir, credentialsKeyFile), bytes.Repeat([]byte{1}, 32)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: ErrCorruptCredentials,
+		},
+		{
+			name: "key removed",
+			change: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, credentialsKeyFile)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: ErrCorruptCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if runtime.GOOS == "windows" && errors.Is(tt.want, ErrInsecurePermissions) {
+				t.Skip("permissions are not checked on windows")
+			}
+
+			dir := t.TempDir()
+			if err := NewCredentialStore(dir).Save("default", testCredentials()); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			tt.change(t, dir)
+
+			if _, err := NewCredentialStore(dir).Load("default"); !errors.Is(err, tt.want) {
+				t.Errorf("Load() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/e2e"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ChunkSize is a size of chunks the files are uploaded with
+const ChunkSize = 64 << 10
+
+var (
+	ErrUnexpectedMessage = errors.New("unexpected message in download stream")
+	ErrTruncatedFile     = errors.New("file is truncated, its last chunk was not received")
+)
+
+// UploadFile streams file located at path to the vault as a file secret, every chunk is encrypted separately
+// and the last one is marked, so empty file is uploaded as one empty chunk.
+// Zero revision creates a new secret, otherwise the secret of that revision is replaced
+func UploadFile(ctx context.Context, client api.NedoVaultClient, cipher *e2e.Cipher, key, name []byte, revision uint64, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stream, err := client.UploadSecret(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(&api.UploadSecretRequest{
+		Payload: &api.UploadSecretRequest_Header{
+			Header: &api.UploadSecretHeader{
+				Key:       key,
+				Name:      name,
+				Filename:  filepath.Base(path),
+				Revision:  revision,
+				Encrypted: true,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReaderSize(f, ChunkSize)
+	buf := make([]byte, ChunkSize)
+
+	for index, last := uint64(0), false; !last; index++ {
+		n, err := io.ReadFull(r, buf)
+		switch {
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+			last = true
+		case err != nil:
+			return err
+		default:
+			// full chunk is the last one if nothing follows it
+			if _, err = r.Peek(1); errors.Is(err, io.EOF) {
+				last = true
+			} else if err != nil {
+				return err
+			}
+		}
+
+		chunk, err := cipher.SealChunk(key, index, last, buf[:n])
+		if err != nil {
+			return err
+		}
+
+		if err = stream.Send(&api.UploadSecretRequest{
+			Payload: &api.UploadSecretRequest_Chunk{
+				Chunk: chunk,
+			},
+		}); err != nil {
+			// real error is returned by CloseAndRecv
+			break
+		}
+	}
+
+	_, err = stream.CloseAndRecv()
+	return err
+}
+
+// DownloadFile writes decrypted content of the file secret to w and returns the secret it belongs to.
+// ErrTruncatedFile is returned if the stream ends before the last chunk. Zero revision downloads the current version of the file
+func DownloadFile(ctx context.Context, client api.NedoVaultClient, cipher *e2e.Cipher, key []byte, revision uint64, w io.Writer) (*api.GetSecretResponse, error) {
+	stream, err := client.DownloadSecret(ctx, &api.DownloadSecretRequest{
+		Key:      key,
+		Revision: revision,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	secret := first.GetSecret()
+	if secret == nil {
+		return nil, ErrUnexpectedMessage
+	}
+
+	// files uploaded before encryption was introduced are stored as is
+	encrypted := secret.GetSecret().GetFile().GetEncrypted()
+
+	// unmarked files were uploaded before the last chunk was marked, marked chunks can't be opened as unmarked
+	var unmarked, last bool
+
+	for index := uint64(0); ; index++ {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			if encrypted && !unmarked && !last {
+				return nil, ErrTruncatedFile
+			}
+			return secret, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if last {
+			return nil, ErrUnexpectedMessage
+		}
+
+		chunk := resp.GetChunk()
+		if encrypted {
+			if chunk, err = openChunk(cipher, key, index, chunk, &unmarked, &last); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err = w.Write(chunk); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// openChunk opens the chunk of the encrypted file, the first chunk tells if the file is unmarked
+func openChunk(cipher *e2e.Cipher, key []byte, index uint64, sealed []byte, unmarked, last *bool) ([]byte, error) {
+	if *unmarked {
+		return cipher.OpenUnmarkedChunk(key, index, sealed)
+	}
+
+	chunk, isLast, err := cipher.OpenChunk(key, index, sealed)
+	if err == nil {
+		*last = isLast
+		return chunk, nil
+	}
+
+	if index != 0 {
+		return nil, err
+	}
+
+	if chunk, err = cipher.OpenUnmarkedChunk(key, index, sealed); err != nil {
+		return nil, err
+	}
+
+	*unmarked = true
+
+	return chunk, nil
+}
+
+// DownloadFileTo saves the file secret into dir under its original filename and returns path to it
+func DownloadFileTo(ctx context.Context, client api.NedoVaultClient, cipher *e2e.Cipher, key []byte, revision uint64, dir string) (string, error) {
+	tmp, err := os.CreateTemp(dir, ".nedovault-download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	secret, err := DownloadFile(ctx, client, cipher, key, revision, tmp)
+	if err != nil {
+		tmp.Close()
+		return "", err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return "", err
+	}
+
+	filename := filepath.Base(secret.GetSecret().GetFile().GetFilename())
+	if filename == "." || filename == string(filepath.Separator) {
+		filename = fmt.Sprintf("%s.bin", key)
+	}
+
+	path := filepath.Join(dir, filename)
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
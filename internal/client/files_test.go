@@ -0,0 +1,165 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/e2e"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fileClient keeps the uploaded chunks and streams them back on download
+type fileClient struct {
+	api.NedoVaultClient
+	chunks [][]byte
+}
+
+func (c *fileClient) UploadSecret(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[api.UploadSecretRequest, emptypb.Empty], error) {
+	return &uploadStream{c: c}, nil
+}
+
+func (c *fileClient) DownloadSecret(ctx context.Context, in *api.DownloadSecretRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[api.DownloadSecretResponse], error) {
+	msgs := []*api.DownloadSecretResponse{{
+		Payload: &api.DownloadSecretResponse_Secret{
+			Secret: &api.GetSecretResponse{
+				Secret: &api.Secret{
+					Secret: &api.Secret_File{
+						File: &api.File{Encrypted: true},
+					},
+				},
+			},
+		},
+	}}
+
+	for _, chunk := range c.chunks {
+		msgs = append(msgs, &api.DownloadSecretResponse{
+			Payload: &api.DownloadSecretResponse_Chunk{Chunk: chunk},
+		})
+	}
+
+	return &downloadStream{msgs: msgs}, nil
+}
+
+type uploadStream struct {
+	grpc.ClientStream
+	c *fileClient
+}
+
+func (s *uploadStream) Send(req *api.UploadSecretRequest) error {
+	if chunk, ok := req.GetPayload().(*api.UploadSecretRequest_Chunk); ok {
+		s.c.chunks = append(s.c.chunks, chunk.Chunk)
+	}
+	return nil
+}
+
+func (s *uploadStream) CloseAndRecv() (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+type downloadStream struct {
+	grpc.ClientStream
+	msgs []*api.DownloadSecretResponse
+}
+
+func (s *downloadStream) Recv() (*api.DownloadSecretResponse, error) {
+	if len(s.msgs) == 0 {
+		return nil, io.EOF
+	}
+
+	msg := s.msgs[0]
+	s.msgs = s.msgs[1:]
+
+	return msg, nil
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	cipher, err := e2e.NewCipher([]byte("master"), []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewCipher() error = %v", err)
+	}
+
+	key := []byte("file")
+
+	tests := []struct {
+		name string
+		size int
+		// chunks is the number of chunks sent for the file
+		chunks int
+		// mangle changes the uploaded chunks before download
+		mangle func(chunks [][]byte) [][]byte
+		want   error
+	}{
+		{name: "empty", size: 0, chunks: 1},
+		{name: "less than a chunk", size: 100, chunks: 1},
+		{name: "exactly one chunk", size: ChunkSize, chunks: 1},
+		{name: "several chunks", size: ChunkSize*2 + 1, chunks: 3},
+		{
+			name:   "last chunk dropped",
+			size:   ChunkSize*2 + 1,
+			chunks: 3,
+			mangle: func(chunks [][]byte) [][]byte { return chunks[:len(chunks)-1] },
+			want:   ErrTruncatedFile,
+		},
+		{
+			name:   "all chunks dropped",
+			size:   100,
+			chunks: 1,
+			mangle: func(chunks [][]byte) [][]byte { return nil },
+			want:   ErrTruncatedFile,
+		},
+		{
+			name:   "chunks reordered",
+			size:   ChunkSize * 2,
+			chunks: 2,
+			mangle: func(chunks [][]byte) [][]byte { return [][]byte{chunks[1], chunks[0]} },
+			want:   e2e.ErrDecrypt,
+		},
+		{
+			name:   "chunk after the last one",
+			size:   ChunkSize + 1,
+			chunks: 2,
+			mangle: func(chunks [][]byte) [][]byte { return append(chunks, chunks[1]) },
+			want:   ErrUnexpectedMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := bytes.Repeat([]byte{'x'}, tt.size)
+
+			path := filepath.Join(t.TempDir(), "file")
+			if err := os.WriteFile(path, content, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			c := &fileClient{}
+
+			if err := UploadFile(context.Background(), c, cipher, key, key, 0, path); err != nil {
+				t.Fatalf("UploadFile() error = %v", err)
+			}
+			if len(c.chunks) != tt.chunks {
+				t.Fatalf("UploadFile() sent %d chunks, want %d", len(c.chunks), tt.chunks)
+			}
+
+			if tt.mangle != nil {
+				c.chunks = tt.mangle(c.chunks)
+			}
+
+			got := &bytes.Buffer{}
+
+			_, err := DownloadFile(context.Background(), c, cipher, key, 0, got)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("DownloadFile() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && !bytes.Equal(got.Bytes(), content) {
+				t.Errorf("DownloadFile() wrote %d bytes, want %d", got.Len(), len(content))
+			}
+		})
+	}
+}
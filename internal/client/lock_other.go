@@ -0,0 +1,12 @@
+//go:build !windows && (!unix || aix)
+
+package client
+
+import (
+	"os"
+)
+
+// lockFile does nothing where files can't be locked, processes of the same profile are not coordinated there
+func lockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build unix && !aix
+
+package client
+
+import (
+	"golang.org/x/sys/unix"
+	"os"
+)
+
+// lockFile blocks until the exclusive lock of the file is acquired, it is released when the file is closed
+func lockFile(f *os.File) error {
+	return unix.Flock(int(f.Fd()), unix.LOCK_EX)
+}
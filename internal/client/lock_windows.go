@@ -0,0 +1,13 @@
+//go:build windows
+
+package client
+
+import (
+	"golang.org/x/sys/windows"
+	"os"
+)
+
+// lockFile blocks until the exclusive lock of the file is acquired, it is released when the file is closed
+func lockFile(f *os.File) error {
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, &windows.Overlapped{})
+}
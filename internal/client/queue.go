@@ -0,0 +1,638 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/nedovault/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"slices"
+	"time"
+)
+
+// ChangeOp is a kind of the change made offline
+type ChangeOp string
+
+const (
+	ChangeAdd    ChangeOp = "add"
+	ChangeUpdate ChangeOp = "update"
+	ChangeDelete ChangeOp = "delete"
+)
+
+// Resolution is the side of the conflict written to the vault
+type Resolution int
+
+const (
+	// KeepMine overwrites the secret in the vault with the offline change
+	KeepMine Resolution = iota
+	// KeepTheirs drops the offline change
+	KeepTheirs
+	// KeepBoth adds the offline content as a new secret next to the one in the vault
+	KeepBoth
+)
+
+// keepMineAttempts bounds how many times the offline change is written over the secret changing in the vault
+const keepMineAttempts = 3
+
+var (
+	ErrNoChange    = errors.New("secret has no offline changes")
+	ErrNoLocalCopy = errors.New("offline change has no content to keep as a copy")
+)
+
+// Change is a change of the secret made offline and not written to the vault yet. Request holds the sealed
+// content of the secret, its revision is the one the change is based on
+type Change struct {
+	Key      []byte
+	Op       ChangeOp
+	Request  *api.UpdateSecretRequest
+	QueuedAt time.Time
+	// Conflict tells why the change can't be replayed, the change waits for the user to resolve it
+	Conflict string
+}
+
+// changeRecord is a change as it is stored in the replica
+type changeRecord struct {
+	Op       ChangeOp  `json:"op"`
+	Request  []byte    `json:"request"`
+	QueuedAt time.Time `json:"queued_at"`
+	Conflict string    `json:"conflict,omitempty"`
+}
+
+// replayKey marks requests of the replay, they fail instead of being queued again when the vault is unreachable
+type replayKey struct{}
+
+// Changes returns changes made offline in the order they were made
+func (r *Replica) Changes() ([]*Change, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.db == nil {
+		return nil, ErrReplicaClosed
+	}
+
+	var changes []*Change
+
+	err := r.db.View(func(txn *badger.Txn) error {
+		var err error
+		changes, err = listChanges(txn)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(changes, func(a, b *Change) int {
+		return a.QueuedAt.Compare(b.QueuedAt)
+	})
+
+	return changes, nil
+}
+
+// Pending returns the number of changes waiting for the vault and how many of them are conflicts
+func (r *Replica) Pending() (changes, conflicts int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.changes, r.conflicts
+}
+
+// Replay writes changes made offline to the vault. Changes of the secrets that were changed in the vault meanwhile
+// are kept as conflicts, replay stops when the vault becomes unreachable again
+func (r *Replica) Replay(ctx context.Context, c api.NedoVaultClient) error {
+	changes, err := r.Changes()
+	if err != nil {
+		return err
+	}
+
+	ctx = context.WithValue(ctx, replayKey{}, true)
+
+	for _, ch := range changes {
+		if ch.Conflict != "" {
+			continue
+		}
+
+		err = replayChange(ctx, c, ch)
+		if status.Code(err) == codes.Unavailable {
+			return err
+		}
+
+		if err == nil {
+			err = r.dropChange(ch)
+		} else {
+			err = r.setConflict(ch, conflictReason(err))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Resolve writes the chosen side of the conflict to the vault and drops the offline change
+func (r *Replica) Resolve(ctx context.Context, c api.NedoVaultClient, key []byte, resolution Resolution) error {
+	changes, err := r.Changes()
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(changes, func(ch *Change) bool {
+		return bytes.Equal(ch.Key, key)
+	})
+	if i < 0 {
+		return ErrNoChange
+	}
+	ch := changes[i]
+
+	ctx = context.WithValue(ctx, replayKey{}, true)
+
+	switch resolution {
+	case KeepMine:
+		err = keepMine(ctx, c, ch)
+	case KeepBoth:
+		err = r.keepCopy(ctx, c, ch)
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.dropChange(ch)
+}
+
+// replayChange writes the change to the vault if the secret still has the revision the change is based on
+func replayChange(ctx context.Context, c api.NedoVaultClient, ch *Change) error {
+	switch ch.Op {
+	case ChangeAdd:
+		_, err := c.AddSecret(ctx, ch.addRequest())
+		return err
+	case ChangeUpdate:
+		_, err := c.UpdateSecret(ctx, ch.Request)
+		return err
+	}
+
+	_, err := c.DeleteSecret(ctx, &api.DeleteSecretRequest{Key: ch.Key, Revision: ch.Request.GetRevision()})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+
+	return err
+}
+
+// keepMine overwrites whatever revision the secret has in the vault, it is retried while the secret keeps changing
+func keepMine(ctx context.Context, c api.NedoVaultClient, ch *Change) error {
+	var err error
+
+	for i := 0; i < keepMineAttempts; i++ {
+		err = overwrite(ctx, c, ch)
+
+		switch status.Code(err) {
+		case codes.Aborted, codes.AlreadyExists:
+			continue
+		}
+
+		return err
+	}
+
+	return err
+}
+
+// overwrite writes the change over the current revision of the secret in the vault
+func overwrite(ctx context.Context, c api.NedoVaultClient, ch *Change) error {
+	resp, err := c.GetSecret(ctx, &api.GetSecretRequest{Key: ch.Key})
+	if status.Code(err) == codes.NotFound {
+		if ch.Op == ChangeDelete {
+			return nil
+		}
+		if ch.Request.GetSecret() != nil {
+			_, err = c.AddSecret(ctx, ch.addRequest())
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	revision := resp.GetSecretMeta().GetRevision()
+
+	if ch.Op == ChangeDelete {
+		_, err = c.DeleteSecret(ctx, &api.DeleteSecretRequest{Key: ch.Key, Revision: revision})
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		return err
+	}
+
+	req := proto.Clone(ch.Request).(*api.UpdateSecretRequest)
+	req.Revision = revision
+
+	_, err = c.UpdateSecret(ctx, req)
+
+	return err
+}
+
+// keepCopy adds the offline content under a new key, the content is sealed again as the key is authenticated with it
+func (r *Replica) keepCopy(ctx context.Context, c api.NedoVaultClient, ch *Change) error {
+	if ch.Op == ChangeDelete || ch.Request.GetSecret() == nil {
+		return ErrNoLocalCopy
+	}
+
+	r.mu.RLock()
+	cipher := r.cipher
+	r.mu.RUnlock()
+
+	if cipher == nil {
+		return ErrReplicaClosed
+	}
+
+	secret, err := cipher.Open(ch.Key, ch.Request.GetSecret())
+	if err != nil {
+		return err
+	}
+
+	req := ch.addRequest()
+	req.Key = fmt.Appendf(nil, "%s-conflict-%s", ch.Key, time.Now().Format("20060102150405"))
+
+	if req.Secret, err = cipher.Seal(req.GetKey(), secret); err != nil {
+		return err
+	}
+
+	_, err = c.AddSecret(ctx, req)
+
+	return err
+}
+
+// conflictReason describes the error of the replayed change for the user
+func conflictReason(err error) string {
+	switch status.Code(err) {
+	case codes.Aborted:
+		return "changed on another device"
+	case codes.NotFound:
+		return "deleted on another device"
+	case codes.AlreadyExists:
+		return "added on another device"
+	}
+
+	return status.Convert(err).Message()
+}
+
+// enqueue stores the change failed to reach the vault and returns metadata the secret has with it. Changes of the same
+// secret are merged, so the change is always based on the revision the secret had in the vault
+func (r *Replica) enqueue(op ChangeOp, req *api.UpdateSecretRequest) (*api.SecretMeta, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return nil, ErrReplicaClosed
+	}
+
+	var meta *api.SecretMeta
+
+	err := r.db.Update(func(txn *badger.Txn) error {
+		base, err := getReplicaMeta(txn, req.GetKey())
+		if err != nil {
+			return err
+		}
+
+		prev, err := getChange(txn, req.GetKey())
+		if err != nil {
+			return err
+		}
+
+		exists, revision := base != nil, base.GetRevision()
+		if prev != nil {
+			exists, revision = prev.Op != ChangeDelete, prev.Request.GetRevision()
+		}
+
+		ch := &Change{
+			Key:      req.GetKey(),
+			Op:       op,
+			Request:  req,
+			QueuedAt: time.Now(),
+		}
+		if prev != nil {
+			ch.Conflict = prev.Conflict
+		}
+
+		switch op {
+		case ChangeAdd:
+			if exists {
+				return status.Error(codes.AlreadyExists, "secret already exists")
+			}
+
+			// secret deleted offline is replaced
+			if prev != nil {
+				ch.Op = ChangeUpdate
+				req.Revision = revision
+			}
+		case ChangeUpdate:
+			if !exists {
+				return status.Error(codes.NotFound, "no such secret")
+			}
+			if req.GetRevision() != revision {
+				return status.Error(codes.Aborted, "secret was changed by another client, fetch it and retry")
+			}
+
+			// only name is changed, content of the previous change is kept
+			if prev != nil {
+				ch.Op = prev.Op
+				if req.Name == nil {
+					req.Name = prev.Request.Name
+				}
+				if req.GetSecret() == nil {
+					req.SecretType = prev.Request.SecretType
+					req.Secret = prev.Request.GetSecret()
+					req.CardPreview = prev.Request.GetCardPreview()
+					req.SshKeyPreview = prev.Request.GetSshKeyPreview()
+				}
+			}
+		case ChangeDelete:
+			if !exists {
+				return status.Error(codes.NotFound, "no such secret")
+			}
+			if req.GetRevision() != 0 && req.GetRevision() != revision {
+				return status.Error(codes.Aborted, "secret was changed by another client, fetch it and retry")
+			}
+
+			// secret added offline never reaches the vault
+			if prev != nil && prev.Op == ChangeAdd {
+				return txn.Delete(replicaPath(replicaQueuePrefix, req.GetKey()))
+			}
+
+			req.Revision = revision
+		}
+
+		meta = ch.meta(base)
+
+		return putChange(txn, ch)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, r.count()
+}
+
+// dropChange removes the change unless the secret was changed offline again meanwhile
+func (r *Replica) dropChange(ch *Change) error {
+	return r.updateChange(ch, func(txn *badger.Txn) error {
+		return txn.Delete(replicaPath(replicaQueuePrefix, ch.Key))
+	})
+}
+
+func (r *Replica) setConflict(ch *Change, reason string) error {
+	return r.updateChange(ch, func(txn *badger.Txn) error {
+		ch.Conflict = reason
+		return putChange(txn, ch)
+	})
+}
+
+func (r *Replica) updateChange(ch *Change, update func(txn *badger.Txn) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return ErrReplicaClosed
+	}
+
+	err := r.db.Update(func(txn *badger.Txn) error {
+		current, err := getChange(txn, ch.Key)
+		if err != nil {
+			return err
+		}
+
+		if current == nil || !current.QueuedAt.Equal(ch.QueuedAt) {
+			return nil
+		}
+
+		return update(txn)
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.count()
+}
+
+// count recounts pending changes, the caller holds the lock
+func (r *Replica) count() error {
+	return r.db.View(func(txn *badger.Txn) error {
+		changes, err := listChanges(txn)
+		if err != nil {
+			return err
+		}
+
+		r.changes, r.conflicts = len(changes), 0
+		for _, ch := range changes {
+			if ch.Conflict != "" {
+				r.conflicts++
+			}
+		}
+
+		return nil
+	})
+}
+
+// applyChanges returns metadata of the secrets as they are with the offline changes
+func applyChanges(txn *badger.Txn, metas []*api.SecretMeta) ([]*api.SecretMeta, error) {
+	changes, err := listChanges(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ch := range changes {
+		i := slices.IndexFunc(metas, func(meta *api.SecretMeta) bool {
+			return bytes.Equal(meta.GetKey(), ch.Key)
+		})
+
+		switch {
+		case ch.Op == ChangeDelete && i >= 0:
+			metas = slices.Delete(metas, i, i+1)
+		case ch.Op != ChangeDelete && i >= 0:
+			metas[i] = ch.meta(metas[i])
+		case ch.Op != ChangeDelete:
+			metas = append(metas, ch.meta(nil))
+		}
+	}
+
+	slices.SortFunc(metas, func(a, b *api.SecretMeta) int {
+		return bytes.Compare(a.GetKey(), b.GetKey())
+	})
+
+	return metas, nil
+}
+
+// applyChange returns the cached secret as it is with the offline change
+func applyChange(txn *badger.Txn, key []byte, cached *api.GetSecretResponse) (*api.GetSecretResponse, error) {
+	ch, err := getChange(txn, key)
+	if err != nil || ch == nil {
+		return cached, err
+	}
+
+	if ch.Op == ChangeDelete {
+		return nil, status.Error(codes.NotFound, "no such secret")
+	}
+
+	if ch.Request.GetSecret() == nil {
+		if cached == nil {
+			return nil, ErrNotReplicated
+		}
+
+		return &api.GetSecretResponse{
+			Secret:     cached.GetSecret(),
+			SecretMeta: ch.meta(cached.GetSecretMeta()),
+		}, nil
+	}
+
+	return &api.GetSecretResponse{
+		Secret:     ch.Request.GetSecret(),
+		SecretMeta: ch.meta(cached.GetSecretMeta()),
+	}, nil
+}
+
+// meta returns metadata of the secret after the change in the same way the vault would set it,
+// revision stays the base one until the change is written
+func (ch *Change) meta(base *api.SecretMeta) *api.SecretMeta {
+	meta := &api.SecretMeta{}
+	if base != nil {
+		meta = proto.Clone(base).(*api.SecretMeta)
+	}
+
+	meta.Key = ch.Key
+	if ch.Request.Name != nil {
+		meta.Name = ch.Request.Name
+	}
+	meta.Timestamp = timestamppb.New(ch.QueuedAt)
+	meta.Revision = ch.Request.GetRevision()
+
+	if ch.Request.GetSecret() == nil {
+		return meta
+	}
+
+	if ch.Request.SecretType != nil {
+		meta.Type = ch.Request.GetSecretType()
+	}
+	meta.BlobId = ""
+	meta.Size = 0
+	meta.Preview = nil
+
+	switch {
+	case ch.Request.GetCardPreview() != nil:
+		meta.Preview = &api.SecretMeta_Card{Card: ch.Request.GetCardPreview()}
+	case ch.Request.GetSshKeyPreview() != nil:
+		meta.Preview = &api.SecretMeta_SshKey{SshKey: ch.Request.GetSshKeyPreview()}
+	}
+
+	return meta
+}
+
+func (ch *Change) addRequest() *api.AddSecretRequest {
+	return &api.AddSecretRequest{
+		Key:           ch.Key,
+		Name:          ch.Request.GetName(),
+		SecretType:    ch.Request.GetSecretType(),
+		Secret:        ch.Request.GetSecret(),
+		CardPreview:   ch.Request.GetCardPreview(),
+		SshKeyPreview: ch.Request.GetSshKeyPreview(),
+	}
+}
+
+func listChanges(txn *badger.Txn) ([]*Change, error) {
+	var changes []*Change
+
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	for it.Seek([]byte(replicaQueuePrefix)); it.ValidForPrefix([]byte(replicaQueuePrefix)); it.Next() {
+		ch, err := readChange(it.Item())
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, ch)
+	}
+
+	return changes, nil
+}
+
+// getChange returns the change of the secret, nil if there is none
+func getChange(txn *badger.Txn, key []byte) (*Change, error) {
+	item, err := txn.Get(replicaPath(replicaQueuePrefix, key))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return readChange(item)
+}
+
+func readChange(item *badger.Item) (*Change, error) {
+	record := &changeRecord{}
+	if err := item.Value(func(val []byte) error {
+		return json.Unmarshal(val, record)
+	}); err != nil {
+		return nil, err
+	}
+
+	req := &api.UpdateSecretRequest{}
+	if err := proto.Unmarshal(record.Request, req); err != nil {
+		return nil, err
+	}
+
+	return &Change{
+		Key:      req.GetKey(),
+		Op:       record.Op,
+		Request:  req,
+		QueuedAt: record.QueuedAt,
+		Conflict: record.Conflict,
+	}, nil
+}
+
+func putChange(txn *badger.Txn, ch *Change) error {
+	req, err := proto.Marshal(ch.Request)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(&changeRecord{
+		Op:       ch.Op,
+		Request:  req,
+		QueuedAt: ch.QueuedAt,
+		Conflict: ch.Conflict,
+	})
+	if err != nil {
+		return err
+	}
+
+	return txn.Set(replicaPath(replicaQueuePrefix, ch.Key), raw)
+}
+
+// getReplicaMeta returns metadata of the secret from the last sync, nil if there is none
+func getReplicaMeta(txn *badger.Txn, key []byte) (*api.SecretMeta, error) {
+	item, err := txn.Get(replicaPath(replicaMetaPrefix, key))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &api.SecretMeta{}
+	err = item.Value(func(val []byte) error {
+		return proto.Unmarshal(val, meta)
+	})
+
+	return meta, err
+}
+
+func replaying(ctx context.Context) bool {
+	replay, _ := ctx.Value(replayKey{}).(bool)
+	return replay
+}
@@ -0,0 +1,327 @@
+package client
+
+import (
+	"context"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/e2e"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"testing"
+)
+
+// baseRevision is the revision of the secret replicated before going offline
+const baseRevision = 3
+
+func openTestReplica(t *testing.T) *Replica {
+	t.Helper()
+
+	cipher, err := e2e.NewCipher([]byte("master"), []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewCipher() error = %v", err)
+	}
+
+	r := NewReplica(t.TempDir())
+	if err = r.Open([]byte("alice"), cipher); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		r.Close()
+	})
+
+	return r
+}
+
+func encrypted(content string) *api.Secret {
+	return &api.Secret{Secret: &api.Secret_Encrypted{Encrypted: []byte(content)}}
+}
+
+func TestEnqueue(t *testing.T) {
+	key := []byte("key")
+
+	add := func(name, content string) proto.Message {
+		return &api.AddSecretRequest{Key: key, Name: []byte(name), SecretType: api.SecretType_TYPE_TEXT, Secret: encrypted(content)}
+	}
+	rename := func(revision uint64, name string) proto.Message {
+		return &api.UpdateSecretRequest{Key: key, Name: []byte(name), Revision: revision}
+	}
+	update := func(revision uint64, content string) proto.Message {
+		return &api.UpdateSecretRequest{Key: key, Secret: encrypted(content), Revision: revision}
+	}
+	remove := func(revision uint64) proto.Message {
+		return &api.DeleteSecretRequest{Key: key, Revision: revision}
+	}
+
+	type queued struct {
+		req  proto.Message
+		want codes.Code
+	}
+
+	// want is the change left in the queue, nil if there is none
+	type change struct {
+		op       ChangeOp
+		revision uint64
+		name     string
+		content  string
+	}
+
+	tests := []struct {
+		name       string
+		replicated bool
+		queue      []queued
+		want       *change
+	}{
+		{
+			name:       "update of the replicated secret",
+			replicated: true,
+			queue:      []queued{{req: update(baseRevision, "new")}},
+			want:       &change{op: ChangeUpdate, revision: baseRevision, name: "base", content: "new"},
+		},
+		{
+			name:       "update of the stale revision",
+			replicated: true,
+			queue:      []queued{{req: update(baseRevision-1, "new"), want: codes.Aborted}},
+		},
+		{
+			name:  "update of the unknown secret",
+			queue: []queued{{req: update(baseRevision, "new"), want: codes.NotFound}},
+		},
+		{
+			name:       "add of the existing secret",
+			replicated: true,
+			queue:      []queued{{req: add("name", "new"), want: codes.AlreadyExists}},
+		},
+		{
+			name:  "add then rename",
+			queue: []queued{{req: add("name", "new")}, {req: rename(0, "renamed")}},
+			want:  &change{op: ChangeAdd, name: "renamed", content: "new"},
+		},
+		{
+			name:  "add then update keeps the name",
+			queue: []queued{{req: add("name", "first")}, {req: update(0, "second")}},
+			want:  &change{op: ChangeAdd, name: "name", content: "second"},
+		},
+		{
+			name:  "add then delete",
+			queue: []queued{{req: add("name", "new")}, {req: remove(0)}},
+		},
+		{
+			name:       "update then rename keeps the content",
+			replicated: true,
+			queue:      []queued{{req: update(baseRevision, "new")}, {req: rename(baseRevision, "renamed")}},
+			want:       &change{op: ChangeUpdate, revision: baseRevision, name: "renamed", content: "new"},
+		},
+		{
+			name:       "rename then update keeps the name",
+			replicated: true,
+			queue:      []queued{{req: rename(baseRevision, "renamed")}, {req: update(baseRevision, "new")}},
+			want:       &change{op: ChangeUpdate, revision: baseRevision, name: "renamed", content: "new"},
+		},
+		{
+			name:       "update then delete",
+			replicated: true,
+			queue:      []queued{{req: update(baseRevision, "new")}, {req: remove(0)}},
+			want:       &change{op: ChangeDelete, revision: baseRevision},
+		},
+		{
+			name:       "delete of the current revision",
+			replicated: true,
+			queue:      []queued{{req: remove(baseRevision)}},
+			want:       &change{op: ChangeDelete, revision: baseRevision},
+		},
+		{
+			name:       "delete of the stale revision",
+			replicated: true,
+			queue:      []queued{{req: remove(baseRevision - 1), want: codes.Aborted}},
+		},
+		{
+			name:       "delete twice",
+			replicated: true,
+			queue:      []queued{{req: remove(0)}, {req: remove(0), want: codes.NotFound}},
+			want:       &change{op: ChangeDelete, revision: baseRevision},
+		},
+		{
+			name:       "delete then update",
+			replicated: true,
+			queue:      []queued{{req: remove(0)}, {req: update(baseRevision, "new"), want: codes.NotFound}},
+			want:       &change{op: ChangeDelete, revision: baseRevision},
+		},
+		{
+			name:       "delete then add replaces the secret",
+			replicated: true,
+			queue:      []queued{{req: remove(0)}, {req: add("name", "new")}},
+			want:       &change{op: ChangeUpdate, revision: baseRevision, name: "name", content: "new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := openTestReplica(t)
+
+			if tt.replicated {
+				err := r.SyncMeta([]*api.SecretMeta{{Key: key, Name: []byte("base"), Revision: baseRevision}})
+				if err != nil {
+					t.Fatalf("SyncMeta() error = %v", err)
+				}
+			}
+
+			for i, q := range tt.queue {
+				op, req := queuedRequest(q.req)
+				if _, err := r.enqueue(op, req); status.Code(err) != q.want {
+					t.Fatalf("change %d: enqueue(%s) error = %v, want %s", i+1, op, err, q.want)
+				}
+			}
+
+			changes, err := r.Changes()
+			if err != nil {
+				t.Fatalf("Changes() error = %v", err)
+			}
+
+			if tt.want == nil {
+				if len(changes) != 0 {
+					t.Errorf("Changes() = %d changes, want none", len(changes))
+				}
+				return
+			}
+
+			if len(changes) != 1 {
+				t.Fatalf("Changes() = %d changes, want one", len(changes))
+			}
+
+			ch := changes[0]
+			got := &change{
+				op:       ch.Op,
+				revision: ch.Request.GetRevision(),
+				content:  string(ch.Request.GetSecret().GetEncrypted()),
+			}
+
+			// name is the one the replica shows offline, deleted secret is not shown
+			metas, err := r.ListSecretsMeta()
+			if err != nil {
+				t.Fatalf("ListSecretsMeta() error = %v", err)
+			}
+			for _, meta := range metas {
+				got.name = string(meta.GetName())
+			}
+
+			if *got != *tt.want {
+				t.Errorf("Changes() = %+v, want %+v", got, tt.want)
+			}
+
+			if changes, conflicts := r.Pending(); changes != 1 || conflicts != 0 {
+				t.Errorf("Pending() = %d, %d, want 1, 0", changes, conflicts)
+			}
+		})
+	}
+}
+
+// raceVault is the vault where the secret is changed by another client right before every write, races times
+type raceVault struct {
+	api.NedoVaultClient
+
+	exists   bool
+	revision uint64
+	races    int
+}
+
+func (v *raceVault) race() bool {
+	if v.races == 0 {
+		return false
+	}
+
+	v.races--
+	v.revision++
+
+	return true
+}
+
+func (v *raceVault) GetSecret(ctx context.Context, in *api.GetSecretRequest, opts ...grpc.CallOption) (*api.GetSecretResponse, error) {
+	if !v.exists {
+		return nil, status.Error(codes.NotFound, "no such secret")
+	}
+
+	return &api.GetSecretResponse{SecretMeta: &api.SecretMeta{Key: in.GetKey(), Revision: v.revision}}, nil
+}
+
+func (v *raceVault) AddSecret(ctx context.Context, in *api.AddSecretRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	if v.exists {
+		return nil, status.Error(codes.AlreadyExists, "secret already exists")
+	}
+
+	v.exists, v.revision = true, 1
+
+	return &emptypb.Empty{}, nil
+}
+
+func (v *raceVault) UpdateSecret(ctx context.Context, in *api.UpdateSecretRequest, opts ...grpc.CallOption) (*api.SecretMeta, error) {
+	if v.race() || in.GetRevision() != v.revision {
+		return nil, status.Error(codes.Aborted, "secret was changed by another client")
+	}
+
+	v.revision++
+
+	return &api.SecretMeta{Key: in.GetKey(), Revision: v.revision}, nil
+}
+
+func (v *raceVault) DeleteSecret(ctx context.Context, in *api.DeleteSecretRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	if !v.exists {
+		return nil, status.Error(codes.NotFound, "no such secret")
+	}
+	if v.race() || (in.GetRevision() != 0 && in.GetRevision() != v.revision) {
+		return nil, status.Error(codes.Aborted, "secret was changed by another client")
+	}
+
+	v.exists = false
+
+	return &emptypb.Empty{}, nil
+}
+
+func TestKeepMine(t *testing.T) {
+	key := []byte("key")
+
+	tests := []struct {
+		name   string
+		op     ChangeOp
+		exists bool
+		races  int
+		want   codes.Code
+		// wantExists tells if the secret is in the vault after the change
+		wantExists bool
+	}{
+		{name: "update", op: ChangeUpdate, exists: true, wantExists: true},
+		{name: "update while the secret changes", op: ChangeUpdate, exists: true, races: keepMineAttempts - 1, wantExists: true},
+		{name: "update while the secret keeps changing", op: ChangeUpdate, exists: true, races: keepMineAttempts, want: codes.Aborted, wantExists: true},
+		{name: "update of the deleted secret", op: ChangeUpdate, wantExists: true},
+		{name: "delete", op: ChangeDelete, exists: true},
+		{name: "delete while the secret changes", op: ChangeDelete, exists: true, races: keepMineAttempts - 1},
+		{name: "delete while the secret keeps changing", op: ChangeDelete, exists: true, races: keepMineAttempts, want: codes.Aborted, wantExists: true},
+		{name: "delete of the deleted secret", op: ChangeDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &raceVault{exists: tt.exists, revision: baseRevision + 1, races: tt.races}
+
+			ch := &Change{
+				Key: key,
+				Op:  tt.op,
+				Request: &api.UpdateSecretRequest{
+					Key:      key,
+					Revision: baseRevision,
+				},
+			}
+			if tt.op != ChangeDelete {
+				ch.Request.Secret = encrypted("mine")
+			}
+
+			if err := keepMine(context.Background(), v, ch); status.Code(err) != tt.want {
+				t.Errorf("keepMine() error = %v, want %s", err, tt.want)
+			}
+			if v.exists != tt.wantExists {
+				t.Errorf("secret exists = %v, want %v", v.exists, tt.wantExists)
+			}
+		})
+	}
+}
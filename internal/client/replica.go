@@ -0,0 +1,555 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/e2e"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// replicaKeyPurpose separates the key of the replica from other keys derived from the encryption key
+const replicaKeyPurpose = "nedovault replica"
+
+// Key prefixes of the replica records
+const (
+	replicaMetaPrefix   = "meta/"
+	replicaSecretPrefix = "secret/"
+	replicaQueuePrefix  = "queue/"
+	replicaSyncedAt     = "synced_at"
+)
+
+var (
+	ErrReplicaClosed = errors.New("offline replica is not opened")
+	ErrNotReplicated = errors.New("secret is not available offline, it was never opened online")
+	ErrReplicaKey    = errors.New("offline replica is encrypted with another key, wrong master password")
+)
+
+// Replica is an encrypted local copy of the secrets, it is updated from responses of the vault
+// and serves ListSecretsMeta and GetSecret when the vault is unreachable. Changes made offline are queued in it
+// until they are replayed. Secrets stay sealed by the user cipher in the replica, the replica itself
+// is encrypted with the key derived from it
+type Replica struct {
+	mu     sync.RWMutex
+	dir    string
+	db     *badger.DB
+	cipher *e2e.Cipher
+
+	offline  bool
+	syncedAt time.Time
+	// number of queued changes and conflicts among them
+	changes   int
+	conflicts int
+}
+
+// NewReplica creates a closed replica, every user of the profile has own database inside dir
+func NewReplica(dir string) *Replica {
+	return &Replica{
+		dir: dir,
+	}
+}
+
+// Open opens the replica of the user, the previously opened one is closed
+func (r *Replica) Open(username []byte, cipher *e2e.Cipher) error {
+	key, err := cipher.DeriveKey(replicaKeyPurpose, 32)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(r.dir, hex.EncodeToString(username))
+	if err = os.MkdirAll(path, 0700); err != nil {
+		return err
+	}
+
+	opts := badger.DefaultOptions(path).
+		WithEncryptionKey(key).
+		WithIndexCacheSize(16 << 20).
+		WithLogger(nil)
+
+	db, err := badger.Open(opts)
+	if errors.Is(err, badger.ErrEncryptionKeyMismatch) {
+		return ErrReplicaKey
+	}
+	if err != nil {
+		return err
+	}
+
+	var syncedAt time.Time
+	err = db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(replicaSyncedAt))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			syncedAt = time.Unix(0, int64(binary.BigEndian.Uint64(val)))
+			return nil
+		})
+	})
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db != nil {
+		r.db.Close()
+	}
+
+	r.db = db
+	r.cipher = cipher
+	r.syncedAt = syncedAt
+	r.offline = false
+
+	return r.count()
+}
+
+// Close closes the replica, it is kept on disk for the next login
+func (r *Replica) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return nil
+	}
+
+	err := r.db.Close()
+	r.db = nil
+	r.cipher = nil
+	r.changes, r.conflicts = 0, 0
+
+	return err
+}
+
+// Status reports whether the last request failed to reach the vault and when the secrets were synced last time
+func (r *Replica) Status() (offline bool, syncedAt time.Time) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.offline, r.syncedAt
+}
+
+// SyncMeta replaces metadata of the secrets, cached secrets are dropped if they were changed or deleted
+func (r *Replica) SyncMeta(metas []*api.SecretMeta) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return ErrReplicaClosed
+	}
+
+	current := make(map[string]*api.SecretMeta, len(metas))
+	for _, meta := range metas {
+		current[string(meta.GetKey())] = meta
+	}
+
+	now := time.Now()
+
+	err := r.db.Update(func(txn *badger.Txn) error {
+		var stale [][]byte
+
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		for it.Seek([]byte(replicaSecretPrefix)); it.ValidForPrefix([]byte(replicaSecretPrefix)); it.Next() {
+			cached := &api.GetSecretResponse{}
+			if err := it.Item().Value(func(val []byte) error {
+				return proto.Unmarshal(val, cached)
+			}); err != nil {
+				it.Close()
+				return err
+			}
+
+			meta, ok := current[string(cached.GetSecretMeta().GetKey())]
+			if !ok || meta.GetRevision() != cached.GetSecretMeta().GetRevision() {
+				stale = append(stale, it.Item().KeyCopy(nil))
+			}
+		}
+
+		for it.Seek([]byte(replicaMetaPrefix)); it.ValidForPrefix([]byte(replicaMetaPrefix)); it.Next() {
+			if _, ok := current[string(it.Item().Key()[len(replicaMetaPrefix):])]; !ok {
+				stale = append(stale, it.Item().KeyCopy(nil))
+			}
+		}
+		it.Close()
+
+		for _, key := range stale {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		for _, meta := range metas {
+			raw, err := proto.Marshal(meta)
+			if err != nil {
+				return err
+			}
+
+			if err = txn.Set(replicaPath(replicaMetaPrefix, meta.GetKey()), raw); err != nil {
+				return err
+			}
+		}
+
+		return txn.Set([]byte(replicaSyncedAt), binary.BigEndian.AppendUint64(nil, uint64(now.UnixNano())))
+	})
+	if err != nil {
+		return err
+	}
+
+	r.syncedAt = now
+
+	return nil
+}
+
+// SyncChanges applies changes of the metadata streamed by SyncStream, cached secrets are dropped if they were changed
+// or deleted. Reset replaces metadata of all secrets
+func (r *Replica) SyncChanges(resp *api.SyncResponse) error {
+	if resp.GetReset_() {
+		metas := make([]*api.SecretMeta, 0, len(resp.GetChanges()))
+		for _, change := range resp.GetChanges() {
+			metas = append(metas, change.GetSecretMeta())
+		}
+
+		return r.SyncMeta(metas)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return ErrReplicaClosed
+	}
+
+	now := time.Now()
+
+	err := r.db.Update(func(txn *badger.Txn) error {
+		for _, change := range resp.GetChanges() {
+			secretPath := replicaPath(replicaSecretPrefix, change.GetKey())
+
+			if change.GetKind() == api.ChangeKind_CHANGE_KIND_DELETE {
+				for _, path := range [][]byte{replicaPath(replicaMetaPrefix, change.GetKey()), secretPath} {
+					if err := txn.Delete(path); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			raw, err := proto.Marshal(change.GetSecretMeta())
+			if err != nil {
+				return err
+			}
+
+			if err = txn.Set(replicaPath(replicaMetaPrefix, change.GetKey()), raw); err != nil {
+				return err
+			}
+
+			if err = dropStaleSecret(txn, secretPath, change.GetSecretMeta().GetRevision()); err != nil {
+				return err
+			}
+		}
+
+		return txn.Set([]byte(replicaSyncedAt), binary.BigEndian.AppendUint64(nil, uint64(now.UnixNano())))
+	})
+	if err != nil {
+		return err
+	}
+
+	r.syncedAt = now
+
+	return nil
+}
+
+// dropStaleSecret deletes the cached secret if it was opened at another revision
+func dropStaleSecret(txn *badger.Txn, secretPath []byte, revision uint64) error {
+	item, err := txn.Get(secretPath)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cached := &api.GetSecretResponse{}
+	if err = item.Value(func(val []byte) error {
+		return proto.Unmarshal(val, cached)
+	}); err != nil {
+		return err
+	}
+
+	if cached.GetSecretMeta().GetRevision() == revision {
+		return nil
+	}
+
+	return txn.Delete(secretPath)
+}
+
+// ListSecretsMeta returns metadata of the secrets from the last sync with the changes made offline
+func (r *Replica) ListSecretsMeta() ([]*api.SecretMeta, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.db == nil {
+		return nil, ErrReplicaClosed
+	}
+
+	var metas []*api.SecretMeta
+
+	err := r.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+
+		for it.Seek([]byte(replicaMetaPrefix)); it.ValidForPrefix([]byte(replicaMetaPrefix)); it.Next() {
+			meta := &api.SecretMeta{}
+			if err := it.Item().Value(func(val []byte) error {
+				return proto.Unmarshal(val, meta)
+			}); err != nil {
+				it.Close()
+				return err
+			}
+
+			metas = append(metas, meta)
+		}
+		it.Close()
+
+		var err error
+		metas, err = applyChanges(txn, metas)
+
+		return err
+	})
+
+	return metas, err
+}
+
+// PutSecret caches the sealed secret as it is returned by the vault
+func (r *Replica) PutSecret(resp *api.GetSecretResponse) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.db == nil {
+		return ErrReplicaClosed
+	}
+
+	raw, err := proto.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(replicaPath(replicaSecretPrefix, resp.GetSecretMeta().GetKey()), raw)
+	})
+}
+
+// GetSecret returns the sealed secret cached by PutSecret or changed offline
+func (r *Replica) GetSecret(key []byte) (*api.GetSecretResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.db == nil {
+		return nil, ErrReplicaClosed
+	}
+
+	var resp *api.GetSecretResponse
+
+	err := r.db.View(func(txn *badger.Txn) error {
+		var cached *api.GetSecretResponse
+
+		item, err := txn.Get(replicaPath(replicaSecretPrefix, key))
+		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+		if err == nil {
+			cached = &api.GetSecretResponse{}
+			if err = item.Value(func(val []byte) error {
+				return proto.Unmarshal(val, cached)
+			}); err != nil {
+				return err
+			}
+		}
+
+		resp, err = applyChange(txn, key, cached)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, ErrNotReplicated
+	}
+
+	return resp, nil
+}
+
+// DialOptions returns options installing the replica interceptors into the client connection,
+// they have to be installed before the session ones to see the result of the retried requests
+func (r *Replica) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(r.UnaryInterceptor()),
+		grpc.WithChainStreamInterceptor(r.StreamInterceptor()),
+	}
+}
+
+// UnaryInterceptor mirrors secrets returned by the vault into the opened replica and
+// serves them from it when the vault is unavailable. Changes of the secrets are queued offline, other requests fail
+func (r *Replica) UnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		r.setOffline(status.Code(err) == codes.Unavailable)
+
+		if !r.opened() {
+			return err
+		}
+
+		switch method {
+		case api.NedoVault_ListSecretsMeta_FullMethodName:
+			res := reply.(*api.ListSecretsMetaResponse)
+
+			if err == nil {
+				// vault stays usable if the replica can't be written
+				_ = r.SyncMeta(res.GetSecretsMeta())
+			} else if status.Code(err) == codes.Unavailable {
+				metas, replicaErr := r.ListSecretsMeta()
+				if replicaErr != nil {
+					return err
+				}
+
+				res.SecretsMeta = metas
+				return nil
+			}
+		case api.NedoVault_GetSecret_FullMethodName:
+			res := reply.(*api.GetSecretResponse)
+
+			if err == nil {
+				_ = r.PutSecret(res)
+			} else if status.Code(err) == codes.Unavailable {
+				cached, replicaErr := r.GetSecret(req.(*api.GetSecretRequest).GetKey())
+				if replicaErr != nil {
+					// secret deleted offline is not found as in the vault
+					if _, ok := status.FromError(replicaErr); ok {
+						return replicaErr
+					}
+					return status.Error(codes.Unavailable, replicaErr.Error())
+				}
+
+				proto.Merge(res, cached)
+				return nil
+			}
+		case api.NedoVault_AddSecret_FullMethodName, api.NedoVault_UpdateSecret_FullMethodName, api.NedoVault_DeleteSecret_FullMethodName:
+			if status.Code(err) != codes.Unavailable || replaying(ctx) {
+				return err
+			}
+
+			op, change := queuedRequest(req)
+
+			meta, queueErr := r.enqueue(op, change)
+			if queueErr != nil {
+				return queueErr
+			}
+
+			if res, ok := reply.(*api.SecretMeta); ok {
+				proto.Merge(res, meta)
+			}
+			return nil
+		}
+
+		return err
+	}
+}
+
+// StreamInterceptor mirrors metadata snapshots of ListSecretsMetaStream and changes of SyncStream into the opened replica
+func (r *Replica) StreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		r.setOffline(status.Code(err) == codes.Unavailable)
+
+		if err != nil || (method != api.NedoVault_ListSecretsMetaStream_FullMethodName && method != api.NedoVault_SyncStream_FullMethodName) {
+			return stream, err
+		}
+
+		return &replicaStream{ClientStream: stream, replica: r}, nil
+	}
+}
+
+func (r *Replica) opened() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.db != nil
+}
+
+func (r *Replica) setOffline(offline bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.offline = offline
+}
+
+// replicaStream syncs the replica with every received snapshot or change of the metadata
+type replicaStream struct {
+	grpc.ClientStream
+	replica *Replica
+}
+
+func (s *replicaStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if status.Code(err) == codes.Unavailable {
+		s.replica.setOffline(true)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.replica.setOffline(false)
+
+	if !s.replica.opened() {
+		return nil
+	}
+
+	switch res := m.(type) {
+	case *api.ListSecretsMetaResponse:
+		_ = s.replica.SyncMeta(res.GetSecretsMeta())
+	case *api.SyncResponse:
+		_ = s.replica.SyncChanges(res)
+	}
+
+	return nil
+}
+
+// queuedRequest converts the request changing the secret to the one stored in the queue
+func queuedRequest(req any) (ChangeOp, *api.UpdateSecretRequest) {
+	switch req := req.(type) {
+	case *api.AddSecretRequest:
+		return ChangeAdd, &api.UpdateSecretRequest{
+			Key:           req.GetKey(),
+			Name:          req.GetName(),
+			SecretType:    req.GetSecretType().Enum(),
+			Secret:        req.GetSecret(),
+			CardPreview:   req.GetCardPreview(),
+			SshKeyPreview: req.GetSshKeyPreview(),
+		}
+	case *api.UpdateSecretRequest:
+		return ChangeUpdate, proto.Clone(req).(*api.UpdateSecretRequest)
+	}
+
+	return ChangeDelete, &api.UpdateSecretRequest{
+		Key:      req.(*api.DeleteSecretRequest).GetKey(),
+		Revision: req.(*api.DeleteSecretRequest).GetRevision(),
+	}
+}
+
+func replicaPath(prefix string, key []byte) []byte {
+	return append([]byte(prefix), key...)
+}
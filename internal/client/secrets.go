@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/e2e"
+	"github.com/renatus-cartesius/nedovault/internal/secrets"
+	"time"
+)
+
+// AddSecret validates the secret, makes its preview and adds it to the vault encrypted
+func AddSecret(ctx context.Context, client api.NedoVaultClient, cipher *e2e.Cipher, in *api.AddSecretRequest) error {
+	secret := in.GetSecret()
+
+	if err := secrets.Validate(secret, time.Now()); err != nil {
+		return err
+	}
+
+	preview := &api.SecretMeta{}
+	secrets.FillPreview(preview, secret)
+
+	sealed, err := cipher.Seal(in.GetKey(), secret)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.AddSecret(ctx, &api.AddSecretRequest{
+		Key:           in.GetKey(),
+		Name:          in.GetName(),
+		SecretType:    in.GetSecretType(),
+		Secret:        sealed,
+		CardPreview:   preview.GetCard(),
+		SshKeyPreview: preview.GetSshKey(),
+	})
+
+	return err
+}
+
+// UpdateSecret validates and encrypts the new content of the secret before replacing it in the vault.
+// Nil secret changes only name of the secret
+func UpdateSecret(ctx context.Context, client api.NedoVaultClient, cipher *e2e.Cipher, in *api.UpdateSecretRequest) (*api.SecretMeta, error) {
+	secret := in.GetSecret()
+
+	if secret == nil {
+		return client.UpdateSecret(ctx, in)
+	}
+
+	if err := secrets.Validate(secret, time.Now()); err != nil {
+		return nil, err
+	}
+
+	preview := &api.SecretMeta{}
+	secrets.FillPreview(preview, secret)
+
+	sealed, err := cipher.Seal(in.GetKey(), secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.UpdateSecret(ctx, &api.UpdateSecretRequest{
+		Key:           in.GetKey(),
+		Name:          in.Name,
+		SecretType:    in.SecretType,
+		Secret:        sealed,
+		Revision:      in.GetRevision(),
+		CardPreview:   preview.GetCard(),
+		SshKeyPreview: preview.GetSshKey(),
+	})
+}
+
+// GetSecret gets the secret from the vault and decrypts it
+func GetSecret(ctx context.Context, client api.NedoVaultClient, cipher *e2e.Cipher, key []byte) (*api.GetSecretResponse, error) {
+	resp, err := client.GetSecret(ctx, &api.GetSecretRequest{
+		Key: key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openResponse(cipher, resp)
+}
+
+// GetSecretVersion gets the previous version of the secret from the vault and decrypts it
+func GetSecretVersion(ctx context.Context, client api.NedoVaultClient, cipher *e2e.Cipher, key []byte, revision uint64) (*api.GetSecretResponse, error) {
+	resp, err := client.GetSecretVersion(ctx, &api.GetSecretVersionRequest{
+		Key:      key,
+		Revision: revision,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openResponse(cipher, resp)
+}
+
+func openResponse(cipher *e2e.Cipher, resp *api.GetSecretResponse) (*api.GetSecretResponse, error) {
+	secret, err := cipher.Open(resp.GetSecretMeta().GetKey(), resp.GetSecret())
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Secret = secret
+	return resp, nil
+}
@@ -0,0 +1,370 @@
+package client
+
+import (
+	"context"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/renatus-cartesius/nedovault/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"sync"
+	"time"
+)
+
+// refreshMargin is how long before expiration the access token is refreshed before opening a stream
+const refreshMargin = time.Second * 30
+
+// Metadata keys of the client info, the server shows them in the list of sessions
+const (
+	deviceHeader        = "device-name"
+	clientVersionHeader = "client-version"
+)
+
+// authMethods return new tokens, which are captured by the session
+var authMethods = map[string]struct{}{
+	api.NedoVault_Authorize_FullMethodName:    {},
+	api.NedoVault_Register_FullMethodName:     {},
+	api.NedoVault_Login_FullMethodName:        {},
+	api.NedoVault_RefreshToken_FullMethodName: {},
+	api.NedoVault_VerifyTOTP_FullMethodName:   {},
+}
+
+// logoutMethods revoke tokens of the session
+var logoutMethods = map[string]struct{}{
+	api.NedoVault_Logout_FullMethodName:         {},
+	api.NedoVault_LogoutAll_FullMethodName:      {},
+	api.NedoVault_ChangePassword_FullMethodName: {},
+	api.NedoVault_DeleteAccount_FullMethodName:  {},
+}
+
+// Session keeps tokens of the logged in user and refreshes the short-lived access token transparently.
+// Tokens are captured from responses of the auth methods, so the session works with Login and Register helpers
+type Session struct {
+	mu      sync.Mutex
+	access  string
+	refresh string
+
+	// refreshing serializes refreshes, as concurrent use of the refresh token revokes the session.
+	// Processes sharing the credential store are serialized by its lock
+	refreshing sync.Mutex
+
+	device  string
+	version string
+
+	// username and salt of the logged in user are saved with the tokens, so the session can be restored
+	username []byte
+	salt     []byte
+	store    *CredentialStore
+	profile  string
+}
+
+type SessionOption func(s *Session)
+
+// refreshKey marks the context of the refresh made by the session, its response is handled by the session itself
+type refreshKey struct{}
+
+// WithClientInfo sets device name and client version reported on login, so the user can tell sessions apart
+func WithClientInfo(device, version string) SessionOption {
+	return func(s *Session) {
+		s.device = device
+		s.version = version
+	}
+}
+
+// WithCredentialStore saves tokens of the session as credentials of the profile on every change
+func WithCredentialStore(store *CredentialStore, profile string) SessionOption {
+	return func(s *Session) {
+		s.store = store
+		s.profile = profile
+	}
+}
+
+func NewSession(opts ...SessionOption) *Session {
+	s := &Session{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// DialOptions returns options installing the session interceptors into the client connection
+func (s *Session) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(s.UnaryInterceptor()),
+		grpc.WithChainStreamInterceptor(s.StreamInterceptor()),
+	}
+}
+
+// Token returns the current access token or empty string if the user is not logged in
+func (s *Session) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.access
+}
+
+// Clear forgets tokens of the session
+func (s *Session) Clear() {
+	s.set("", "")
+}
+
+// Restore loads the saved credentials of the profile, ErrNoCredentials is returned if there are none or they are expired
+func (s *Session) Restore() error {
+	if s.store == nil {
+		return ErrNoCredentials
+	}
+
+	creds, err := s.store.Load(s.profile)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.access, s.refresh = creds.AccessToken, creds.RefreshToken
+	s.username, s.salt = []byte(creds.Username), creds.Salt
+
+	return nil
+}
+
+// Credentials returns tokens of the logged in user or nil if the user is not logged in
+func (s *Session) Credentials() *Credentials {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.credentials()
+}
+
+// UnaryInterceptor sets the access token of the session to requests and retries
+// the request with the refreshed token once when it fails with Unauthenticated
+func (s *Session) UnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := authMethods[method]; ok {
+			err := invoker(s.withClientInfo(ctx), method, req, reply, cc, opts...)
+			if ctx.Value(refreshKey{}) != nil {
+				return err
+			}
+
+			res := reply.(*api.AuthResponse)
+
+			// username is remembered on the login challenged by the second factor, as VerifyTOTP does not pass it
+			if err == nil {
+				var username []byte
+				if authReq, ok := req.(*api.AuthRequest); ok {
+					username = authReq.GetUsername()
+				}
+				s.setUser(username, res.GetSalt())
+			}
+
+			// login challenged by the second factor has no tokens yet
+			if err == nil && res.GetToken() != "" {
+				s.set(res.GetToken(), res.GetRefreshToken())
+			}
+			return err
+		}
+
+		token := s.Token()
+		if token == "" {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		err := invoker(withToken(ctx, token), method, req, reply, cc, opts...)
+		if status.Code(err) == codes.Unauthenticated {
+			refreshed, refreshErr := s.refreshToken(ctx, cc, token)
+			if refreshErr != nil {
+				return err
+			}
+
+			err = invoker(withToken(ctx, refreshed), method, req, reply, cc, opts...)
+		}
+
+		if _, ok := logoutMethods[method]; ok && err == nil {
+			s.Clear()
+		}
+
+		return err
+	}
+}
+
+// StreamInterceptor sets the access token of the session to streams. Token is checked only when the stream is opened,
+// so it is refreshed in advance if it is about to expire
+func (s *Session) StreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		token := s.Token()
+		if token == "" {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		if expiresSoon(token) {
+			if refreshed, err := s.refreshToken(ctx, cc, token); err == nil {
+				token = refreshed
+			}
+		}
+
+		return streamer(withToken(ctx, token), desc, cc, method, opts...)
+	}
+}
+
+// refreshToken exchanges the refresh token for new tokens, unless the expired token was already replaced by concurrent call.
+// Tokens saved by another process of the profile are adopted, as the refresh token they replaced is spent
+func (s *Session) refreshToken(ctx context.Context, cc *grpc.ClientConn, expired string) (string, error) {
+	s.refreshing.Lock()
+	defer s.refreshing.Unlock()
+
+	s.mu.Lock()
+	access, refresh, username := s.access, s.refresh, string(s.username)
+	s.mu.Unlock()
+
+	if access != expired {
+		return access, nil
+	}
+
+	if s.store == nil {
+		return s.exchange(ctx, cc, refresh)
+	}
+
+	var (
+		token   string
+		err     error
+		updated bool
+	)
+
+	saveErr := s.store.Update(s.profile, func(saved *Credentials) *Credentials {
+		updated = true
+
+		if saved != nil && saved.Username == username && saved.RefreshToken != refresh {
+			s.setTokens(saved.AccessToken, saved.RefreshToken)
+
+			if !expiresSoon(saved.AccessToken) {
+				token = saved.AccessToken
+				return saved
+			}
+
+			refresh = saved.RefreshToken
+		}
+
+		token, err = s.exchange(ctx, cc, refresh)
+
+		return s.Credentials()
+	})
+	// session keeps working in memory if the credentials can't be saved
+	if !updated {
+		return "", saveErr
+	}
+
+	return token, err
+}
+
+// exchange exchanges the refresh token for new tokens of the session without saving them
+func (s *Session) exchange(ctx context.Context, cc *grpc.ClientConn, refresh string) (string, error) {
+	res := &api.AuthResponse{}
+
+	err := cc.Invoke(context.WithValue(ctx, refreshKey{}, true), api.NedoVault_RefreshToken_FullMethodName, &api.RefreshTokenRequest{
+		RefreshToken: refresh,
+	}, res)
+	if err != nil {
+		// refresh token is expired or revoked, the user has to log in again
+		if status.Code(err) == codes.Unauthenticated {
+			s.setTokens("", "")
+		}
+		return "", err
+	}
+
+	s.setTokens(res.GetToken(), res.GetRefreshToken())
+
+	return res.GetToken(), nil
+}
+
+func (s *Session) set(access, refresh string) {
+	creds := s.setTokens(access, refresh)
+
+	if s.store == nil {
+		return
+	}
+
+	// session keeps working in memory if the credentials can't be saved, the user logs in again on the next start
+	if creds != nil {
+		_ = s.store.Save(s.profile, creds)
+	} else {
+		_ = s.store.Delete(s.profile)
+	}
+}
+
+// setTokens replaces tokens of the session in memory and returns the credentials to save
+func (s *Session) setTokens(access, refresh string) *Credentials {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.access = access
+	s.refresh = refresh
+
+	return s.credentials()
+}
+
+// setUser remembers the logged in user, nil values keep the current ones
+func (s *Session) setUser(username, salt []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if username != nil {
+		s.username = username
+	}
+	if salt != nil {
+		s.salt = salt
+	}
+}
+
+func (s *Session) credentials() *Credentials {
+	if s.access == "" {
+		return nil
+	}
+
+	// access token is refreshed until the refresh token expires
+	expiresAt := tokenExpiry(s.refresh)
+	if s.refresh == "" {
+		expiresAt = tokenExpiry(s.access)
+	}
+
+	return &Credentials{
+		Username:     string(s.username),
+		AccessToken:  s.access,
+		RefreshToken: s.refresh,
+		Salt:         s.salt,
+		ExpiresAt:    expiresAt,
+	}
+}
+
+// withClientInfo adds device name and client version to the outgoing metadata
+func (s *Session) withClientInfo(ctx context.Context) context.Context {
+	if s.device != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, deviceHeader, s.device)
+	}
+
+	if s.version != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, clientVersionHeader, s.version)
+	}
+
+	return ctx
+}
+
+// withToken replaces the token in the outgoing metadata
+func withToken(ctx context.Context, token string) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set("token", token)
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func expiresSoon(token string) bool {
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil || claims.ExpiresAt == nil {
+		return false
+	}
+
+	return time.Until(claims.ExpiresAt.Time) < refreshMargin
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"google.golang.org/grpc/credentials"
+	"os"
+)
+
+var (
+	ErrNoCertificates = errors.New("no certificates found in the CA bundle")
+	ErrKeyPairPartial = errors.New("client certificate and key must be set together")
+)
+
+// TransportCredentials returns TLS credentials of the vault connection. Server certificate is verified only against
+// the CA bundle if it is set, so the client trusts only the vault CA instead of the system roots.
+// Client certificate is presented if the server requires mutual TLS
+func TransportCredentials(caPath, certPath, keyPath string) (credentials.TransportCredentials, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if caPath != "" {
+		bundle, err := os.ReadFile(caPath)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, ErrNoCertificates
+		}
+
+		config.RootCAs = pool
+	}
+
+	if (certPath == "") != (keyPath == "") {
+		return nil, ErrKeyPairPartial
+	}
+
+	if certPath != "" {
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(config), nil
+}
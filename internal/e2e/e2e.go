@@ -0,0 +1,198 @@
+// Package e2e implements client side encryption of vault secrets, so the server stores only ciphertext
+package e2e
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"github.com/renatus-cartesius/nedovault/api"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"google.golang.org/protobuf/proto"
+	"io"
+)
+
+// argon2id parameters of the key derivation, changing them makes existing secrets unreadable
+const (
+	argonTime    = 3
+	argonMemory  = 64 << 10
+	argonThreads = 4
+)
+
+// SaltSize is a size of per-user salt generated by the server
+const SaltSize = 16
+
+// keyCheckPlaintext is sealed as the key check of the account
+const keyCheckPlaintext = "nedovault key check"
+
+var (
+	ErrNoSalt              = errors.New("server returned no salt for key derivation")
+	ErrDecrypt             = errors.New("error decrypting secret: wrong master password or corrupted data")
+	ErrWrongMasterPassword = errors.New("wrong master password")
+)
+
+// Cipher seals and opens secrets with a key derived from the master password
+type Cipher struct {
+	aead cipher.AEAD
+	key  []byte
+}
+
+// NewCipher derives the encryption key from master password and user salt with argon2id
+func NewCipher(masterPassword, salt []byte) (*Cipher, error) {
+	if len(salt) == 0 {
+		return nil, ErrNoSalt
+	}
+
+	key := argon2.IDKey(masterPassword, salt, argonTime, argonMemory, argonThreads, chacha20poly1305.KeySize)
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{
+		aead: aead,
+		key:  key,
+	}, nil
+}
+
+// DeriveKey returns a key for the purpose derived from the encryption key with HKDF,
+// so other client side stores are encrypted without the master password being asked again
+func (c *Cipher) DeriveKey(purpose string, size int) ([]byte, error) {
+	key := make([]byte, size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, c.key, nil, []byte(purpose)), key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// KeyCheck seals the constant checked by VerifyKeyCheck, the server keeps it next to the salt
+func (c *Cipher) KeyCheck() ([]byte, error) {
+	return c.seal([]byte(keyCheckPlaintext), []byte(keyCheckPlaintext))
+}
+
+// VerifyKeyCheck returns ErrWrongMasterPassword if the key check was sealed with another key
+func (c *Cipher) VerifyKeyCheck(keyCheck []byte) error {
+	plaintext, err := c.open(keyCheck, []byte(keyCheckPlaintext))
+	if err != nil || string(plaintext) != keyCheckPlaintext {
+		return ErrWrongMasterPassword
+	}
+
+	return nil
+}
+
+// NewSalt generates random salt for a new user
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	return salt, nil
+}
+
+// Seal encrypts the serialized secret. Ciphertext is bound to the secret key,
+// so the server can't swap contents of different secrets
+func (c *Cipher) Seal(key []byte, secret *api.Secret) (*api.Secret, error) {
+	raw, err := proto.Marshal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := c.seal(raw, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Secret{
+		Secret: &api.Secret_Encrypted{
+			Encrypted: sealed,
+		},
+	}, nil
+}
+
+// Open decrypts the secret sealed by Seal, secrets stored before encryption was introduced are returned as is
+func (c *Cipher) Open(key []byte, secret *api.Secret) (*api.Secret, error) {
+	sealed, ok := secret.GetSecret().(*api.Secret_Encrypted)
+	if !ok {
+		return secret, nil
+	}
+
+	raw, err := c.open(sealed.Encrypted, key)
+	if err != nil {
+		return nil, err
+	}
+
+	opened := &api.Secret{}
+	if err = proto.Unmarshal(raw, opened); err != nil {
+		return nil, err
+	}
+
+	return opened, nil
+}
+
+// SealChunk encrypts chunk of the file secret, index prevents reordering of the chunks
+// and the mark of the last chunk prevents truncation of the file
+func (c *Cipher) SealChunk(key []byte, index uint64, last bool, chunk []byte) ([]byte, error) {
+	return c.seal(chunk, markedChunkAD(key, index, last))
+}
+
+// OpenChunk decrypts chunk sealed by SealChunk and reports if it is the last chunk of the file
+func (c *Cipher) OpenChunk(key []byte, index uint64, chunk []byte) ([]byte, bool, error) {
+	for _, last := range []bool{false, true} {
+		if plaintext, err := c.open(chunk, markedChunkAD(key, index, last)); err == nil {
+			return plaintext, last, nil
+		}
+	}
+
+	return nil, false, ErrDecrypt
+}
+
+// OpenUnmarkedChunk decrypts chunk of the file uploaded before the last chunk was marked,
+// truncation of such files can't be detected
+func (c *Cipher) OpenUnmarkedChunk(key []byte, index uint64, chunk []byte) ([]byte, error) {
+	return c.open(chunk, chunkAD(key, index))
+}
+
+func (c *Cipher) seal(plaintext, ad []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, ad), nil
+}
+
+func (c *Cipher) open(sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, ad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
+
+func chunkAD(key []byte, index uint64) []byte {
+	ad := make([]byte, 0, len(key)+9)
+	ad = append(ad, key...)
+
+	return binary.BigEndian.AppendUint64(ad, index)
+}
+
+func markedChunkAD(key []byte, index uint64, last bool) []byte {
+	if last {
+		return append(chunkAD(key, index), 1)
+	}
+
+	return append(chunkAD(key, index), 0)
+}
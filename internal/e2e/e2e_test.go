@@ -0,0 +1,183 @@
+package e2e
+
+import (
+	"bytes"
+	"errors"
+	"github.com/renatus-cartesius/nedovault/api"
+	"google.golang.org/protobuf/proto"
+	"testing"
+)
+
+func newTestCipher(t *testing.T, masterPassword string) *Cipher {
+	t.Helper()
+
+	c, err := NewCipher([]byte(masterPassword), []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewCipher() error = %v", err)
+	}
+
+	return c
+}
+
+func TestNewCipherNoSalt(t *testing.T) {
+	if _, err := NewCipher([]byte("master"), nil); !errors.Is(err, ErrNoSalt) {
+		t.Errorf("NewCipher() error = %v, want %v", err, ErrNoSalt)
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	c := newTestCipher(t, "master")
+	other := newTestCipher(t, "another master")
+
+	secret := &api.Secret{
+		Secret: &api.Secret_Text{
+			Text: &api.Text{Data: "top secret"},
+		},
+	}
+
+	sealed, err := c.Seal([]byte("key"), secret)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	if bytes.Contains(sealed.GetEncrypted(), []byte("top secret")) {
+		t.Fatal("sealed secret contains the plaintext")
+	}
+
+	tamper := func(s *api.Secret) *api.Secret {
+		raw := bytes.Clone(s.GetEncrypted())
+		raw[len(raw)-1] ^= 1
+		return &api.Secret{Secret: &api.Secret_Encrypted{Encrypted: raw}}
+	}
+
+	tests := []struct {
+		name   string
+		cipher *Cipher
+		key    string
+		secret *api.Secret
+		want   error
+	}{
+		{name: "same key", cipher: c, key: "key", secret: sealed},
+		{name: "swapped with another secret", cipher: c, key: "other key", secret: sealed, want: ErrDecrypt},
+		{name: "wrong master password", cipher: other, key: "key", secret: sealed, want: ErrDecrypt},
+		{name: "tampered", cipher: c, key: "key", secret: tamper(sealed), want: ErrDecrypt},
+		{name: "too short", cipher: c, key: "key", secret: &api.Secret{Secret: &api.Secret_Encrypted{Encrypted: []byte("short")}}, want: ErrDecrypt},
+		{name: "stored before encryption", cipher: c, key: "key", secret: secret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opened, err := tt.cipher.Open([]byte(tt.key), tt.secret)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Open() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && !proto.Equal(opened, secret) {
+				t.Errorf("Open() = %v, want %v", opened, secret)
+			}
+		})
+	}
+}
+
+func TestKeyCheck(t *testing.T) {
+	c := newTestCipher(t, "master")
+
+	keyCheck, err := c.KeyCheck()
+	if err != nil {
+		t.Fatalf("KeyCheck() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		cipher *Cipher
+		check  []byte
+		want   error
+	}{
+		{name: "same master password", cipher: c, check: keyCheck},
+		{name: "same master password again", cipher: newTestCipher(t, "master"), check: keyCheck},
+		{name: "wrong master password", cipher: newTestCipher(t, "wrong"), check: keyCheck, want: ErrWrongMasterPassword},
+		{name: "empty", cipher: c, want: ErrWrongMasterPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cipher.VerifyKeyCheck(tt.check); !errors.Is(err, tt.want) {
+				t.Errorf("VerifyKeyCheck() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunks(t *testing.T) {
+	c := newTestCipher(t, "master")
+	key := []byte("file")
+
+	middle, err := c.SealChunk(key, 1, false, []byte("middle"))
+	if err != nil {
+		t.Fatalf("SealChunk() error = %v", err)
+	}
+
+	last, err := c.SealChunk(key, 2, true, []byte("last"))
+	if err != nil {
+		t.Fatalf("SealChunk() error = %v", err)
+	}
+
+	unmarked, err := c.seal([]byte("legacy"), chunkAD(key, 0))
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		key      []byte
+		index    uint64
+		chunk    []byte
+		want     string
+		wantLast bool
+		err      error
+	}{
+		{name: "middle chunk", key: key, index: 1, chunk: middle, want: "middle"},
+		{name: "last chunk", key: key, index: 2, chunk: last, want: "last", wantLast: true},
+		{name: "reordered", key: key, index: 2, chunk: middle, err: ErrDecrypt},
+		{name: "moved to another file", key: []byte("another file"), index: 1, chunk: middle, err: ErrDecrypt},
+		{name: "unmarked chunk", key: key, index: 0, chunk: unmarked, err: ErrDecrypt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isLast, err := c.OpenChunk(tt.key, tt.index, tt.chunk)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("OpenChunk() error = %v, want %v", err, tt.err)
+			}
+			if string(got) != tt.want || isLast != tt.wantLast {
+				t.Errorf("OpenChunk() = %q, %v, want %q, %v", got, isLast, tt.want, tt.wantLast)
+			}
+		})
+	}
+
+	// unmarked chunks are opened only as legacy ones, and marked chunks can't be passed as legacy
+	if got, err := c.OpenUnmarkedChunk(key, 0, unmarked); err != nil || string(got) != "legacy" {
+		t.Errorf("OpenUnmarkedChunk() = %q, %v, want %q", got, err, "legacy")
+	}
+	if _, err := c.OpenUnmarkedChunk(key, 2, last); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("OpenUnmarkedChunk() of marked chunk error = %v, want %v", err, ErrDecrypt)
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	c := newTestCipher(t, "master")
+
+	a, err := c.DeriveKey("replica", 32)
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+
+	again, _ := c.DeriveKey("replica", 32)
+	other, _ := c.DeriveKey("credentials", 32)
+
+	if !bytes.Equal(a, again) {
+		t.Error("DeriveKey() is not deterministic")
+	}
+	if bytes.Equal(a, other) {
+		t.Error("DeriveKey() returned the same key for different purposes")
+	}
+}
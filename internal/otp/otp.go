@@ -0,0 +1,183 @@
+// Package otp implements RFC 6238 time-based one-time passwords
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/renatus-cartesius/nedovault/api"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDigits = 6
+	DefaultPeriod = 30
+)
+
+var (
+	ErrInvalidSecret    = errors.New("otp secret is not a valid base32 string")
+	ErrInvalidDigits    = errors.New("otp digits must be between 6 and 8")
+	ErrInvalidAlgorithm = errors.New("unsupported otp algorithm")
+	ErrInvalidURI       = errors.New("invalid otpauth uri")
+)
+
+// Validate checks that codes can be generated with the passed parameters
+func Validate(o *api.Otp) error {
+	if _, err := decodeSecret(o.GetSecret()); err != nil {
+		return err
+	}
+
+	if d := o.GetDigits(); d != 0 && (d < 6 || d > 8) {
+		return ErrInvalidDigits
+	}
+
+	if _, ok := api.OtpAlgorithm_name[int32(o.GetAlgorithm())]; !ok {
+		return ErrInvalidAlgorithm
+	}
+
+	return nil
+}
+
+// Generate returns a code valid at the moment t
+func Generate(o *api.Otp, t time.Time) (string, error) {
+	key, err := decodeSecret(o.GetSecret())
+	if err != nil {
+		return "", err
+	}
+
+	var h func() hash.Hash
+	switch o.GetAlgorithm() {
+	case api.OtpAlgorithm_OTP_ALGORITHM_SHA1:
+		h = sha1.New
+	case api.OtpAlgorithm_OTP_ALGORITHM_SHA256:
+		h = sha256.New
+	case api.OtpAlgorithm_OTP_ALGORITHM_SHA512:
+		h = sha512.New
+	default:
+		return "", ErrInvalidAlgorithm
+	}
+
+	counter := uint64(t.Unix()) / uint64(period(o))
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+
+	mac := hmac.New(h, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation from RFC 4226
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	d := digits(o)
+	mod := uint32(1)
+	for i := 0; i < d; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", d, code%mod), nil
+}
+
+// Remaining returns time left until the code generated at the moment t expires
+func Remaining(o *api.Otp, t time.Time) time.Duration {
+	p := int64(period(o))
+	return time.Duration(p-t.Unix()%p) * time.Second
+}
+
+// ParseURI parses key uri of format otpauth://totp/Issuer:account?secret=...&issuer=...
+func ParseURI(uri string) (*api.Otp, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		return nil, ErrInvalidURI
+	}
+
+	q := u.Query()
+
+	o := &api.Otp{
+		Secret: strings.ToUpper(q.Get("secret")),
+		Issuer: q.Get("issuer"),
+	}
+
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		if o.Issuer == "" {
+			o.Issuer = issuer
+		}
+		o.Account = strings.TrimSpace(account)
+	} else {
+		o.Account = label
+	}
+
+	if v := q.Get("digits"); v != "" {
+		d, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return nil, ErrInvalidDigits
+		}
+		o.Digits = uint32(d)
+	}
+
+	if v := q.Get("period"); v != "" {
+		p, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || p == 0 {
+			return nil, ErrInvalidURI
+		}
+		o.Period = uint32(p)
+	}
+
+	switch strings.ToUpper(q.Get("algorithm")) {
+	case "", "SHA1":
+		o.Algorithm = api.OtpAlgorithm_OTP_ALGORITHM_SHA1
+	case "SHA256":
+		o.Algorithm = api.OtpAlgorithm_OTP_ALGORITHM_SHA256
+	case "SHA512":
+		o.Algorithm = api.OtpAlgorithm_OTP_ALGORITHM_SHA512
+	default:
+		return nil, ErrInvalidAlgorithm
+	}
+
+	if err = Validate(o); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+
+	return key, nil
+}
+
+func digits(o *api.Otp) int {
+	if o.GetDigits() == 0 {
+		return DefaultDigits
+	}
+	return int(o.GetDigits())
+}
+
+func period(o *api.Otp) uint32 {
+	if o.GetPeriod() == 0 {
+		return DefaultPeriod
+	}
+	return o.GetPeriod()
+}
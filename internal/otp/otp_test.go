@@ -0,0 +1,169 @@
+package otp
+
+import (
+	"encoding/base32"
+	"errors"
+	"github.com/renatus-cartesius/nedovault/api"
+	"testing"
+	"time"
+)
+
+// seeds of the RFC 6238 test vectors, a longer seed is used for each longer hash
+var (
+	seedSHA1   = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	seedSHA256 = base32.StdEncoding.EncodeToString([]byte("12345678901234567890123456789012"))
+	seedSHA512 = base32.StdEncoding.EncodeToString([]byte("1234567890123456789012345678901234567890123456789012345678901234"))
+)
+
+func TestGenerateRFC6238(t *testing.T) {
+	tests := []struct {
+		unix   int64
+		sha1   string
+		sha256 string
+		sha512 string
+	}{
+		{unix: 59, sha1: "94287082", sha256: "46119246", sha512: "90693936"},
+		{unix: 1111111109, sha1: "07081804", sha256: "68084774", sha512: "25091201"},
+		{unix: 1111111111, sha1: "14050471", sha256: "67062674", sha512: "99943326"},
+		{unix: 1234567890, sha1: "89005924", sha256: "91819424", sha512: "93441116"},
+		{unix: 2000000000, sha1: "69279037", sha256: "90698825", sha512: "38618901"},
+		{unix: 20000000000, sha1: "65353130", sha256: "77737706", sha512: "47863826"},
+	}
+
+	for _, tt := range tests {
+		for _, c := range []struct {
+			algorithm api.OtpAlgorithm
+			secret    string
+			want      string
+		}{
+			{algorithm: api.OtpAlgorithm_OTP_ALGORITHM_SHA1, secret: seedSHA1, want: tt.sha1},
+			{algorithm: api.OtpAlgorithm_OTP_ALGORITHM_SHA256, secret: seedSHA256, want: tt.sha256},
+			{algorithm: api.OtpAlgorithm_OTP_ALGORITHM_SHA512, secret: seedSHA512, want: tt.sha512},
+		} {
+			o := &api.Otp{
+				Secret:    c.secret,
+				Algorithm: c.algorithm,
+				Digits:    8,
+			}
+
+			got, err := Generate(o, time.Unix(tt.unix, 0))
+			if err != nil {
+				t.Fatalf("Generate(%s, %d) error = %v", c.algorithm, tt.unix, err)
+			}
+			if got != c.want {
+				t.Errorf("Generate(%s, %d) = %s, want %s", c.algorithm, tt.unix, got, c.want)
+			}
+		}
+	}
+}
+
+func TestGenerateRFC4226(t *testing.T) {
+	// HOTP values of RFC 4226 appendix D, counter is the time step of the default period
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	o := &api.Otp{Secret: seedSHA1}
+
+	for counter, code := range want {
+		got, err := Generate(o, time.Unix(int64(counter*DefaultPeriod), 0))
+		if err != nil {
+			t.Fatalf("Generate(%d) error = %v", counter, err)
+		}
+		if got != code {
+			t.Errorf("Generate(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		otp  *api.Otp
+		want error
+	}{
+		{name: "defaults", otp: &api.Otp{Secret: seedSHA1}},
+		{name: "lowercase with spaces", otp: &api.Otp{Secret: "gezd gnbv gy3t qojq"}},
+		{name: "padded", otp: &api.Otp{Secret: "GEZDGNBVGY======"}},
+		{name: "empty secret", otp: &api.Otp{}, want: ErrInvalidSecret},
+		{name: "not base32", otp: &api.Otp{Secret: "12345678"}, want: ErrInvalidSecret},
+		{name: "five digits", otp: &api.Otp{Secret: seedSHA1, Digits: 5}, want: ErrInvalidDigits},
+		{name: "nine digits", otp: &api.Otp{Secret: seedSHA1, Digits: 9}, want: ErrInvalidDigits},
+		{name: "unknown algorithm", otp: &api.Otp{Secret: seedSHA1, Algorithm: 42}, want: ErrInvalidAlgorithm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.otp); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		want *api.Otp
+		err  error
+	}{
+		{
+			name: "issuer in label",
+			uri:  "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP",
+			want: &api.Otp{Secret: "JBSWY3DPEHPK3PXP", Issuer: "Example", Account: "alice@example.com"},
+		},
+		{
+			name: "issuer parameter wins",
+			uri:  "otpauth://totp/Label:alice?secret=jbswy3dpehpk3pxp&issuer=Example&digits=8&period=60&algorithm=SHA256",
+			want: &api.Otp{
+				Secret:    "JBSWY3DPEHPK3PXP",
+				Issuer:    "Example",
+				Account:   "alice",
+				Digits:    8,
+				Period:    60,
+				Algorithm: api.OtpAlgorithm_OTP_ALGORITHM_SHA256,
+			},
+		},
+		{name: "hotp", uri: "otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP&counter=1", err: ErrInvalidURI},
+		{name: "zero period", uri: "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&period=0", err: ErrInvalidURI},
+		{name: "bad digits", uri: "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&digits=x", err: ErrInvalidDigits},
+		{name: "md5", uri: "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&algorithm=MD5", err: ErrInvalidAlgorithm},
+		{name: "no secret", uri: "otpauth://totp/alice", err: ErrInvalidSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseURI(tt.uri)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseURI() error = %v, want %v", err, tt.err)
+			}
+			if tt.want == nil {
+				return
+			}
+
+			if got.GetSecret() != tt.want.GetSecret() || got.GetIssuer() != tt.want.GetIssuer() ||
+				got.GetAccount() != tt.want.GetAccount() || got.GetDigits() != tt.want.GetDigits() ||
+				got.GetPeriod() != tt.want.GetPeriod() || got.GetAlgorithm() != tt.want.GetAlgorithm() {
+				t.Errorf("ParseURI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	tests := []struct {
+		unix   int64
+		period uint32
+		want   time.Duration
+	}{
+		{unix: 0, want: time.Second * 30},
+		{unix: 29, want: time.Second},
+		{unix: 30, want: time.Second * 30},
+		{unix: 61, period: 60, want: time.Second * 59},
+	}
+
+	for _, tt := range tests {
+		if got := Remaining(&api.Otp{Period: tt.period}, time.Unix(tt.unix, 0)); got != tt.want {
+			t.Errorf("Remaining(%d, %d) = %v, want %v", tt.unix, tt.period, got, tt.want)
+		}
+	}
+}
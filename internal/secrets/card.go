@@ -0,0 +1,149 @@
+package secrets
+
+import (
+	"errors"
+	"github.com/renatus-cartesius/nedovault/api"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCardNumber = errors.New("card number is invalid")
+	ErrInvalidCardExpiry = errors.New("card expiry date is invalid")
+	ErrCardExpired       = errors.New("card is expired")
+	ErrInvalidCVV        = errors.New("card cvv is invalid")
+)
+
+// ValidateCard checks card number with Luhn algorithm, expiry date against now and cvv format
+func ValidateCard(card *api.Card, now time.Time) error {
+	number := NormalizeCardNumber(card.GetNumber())
+	if len(number) < 12 || len(number) > 19 || !isDigits(number) || !Luhn(number) {
+		return ErrInvalidCardNumber
+	}
+
+	if err := validateExpiry(card.GetExpiryMonth(), card.GetExpiryYear(), now); err != nil {
+		return err
+	}
+
+	cvv := card.GetCvv()
+	if cvv != "" && (len(cvv) < 3 || len(cvv) > 4 || !isDigits(cvv)) {
+		return ErrInvalidCVV
+	}
+
+	return nil
+}
+
+// validateExpiry checks the expiry month and year, two-digit year is in the 2000s
+func validateExpiry(month, year uint32, now time.Time) error {
+	if month < 1 || month > 12 {
+		return ErrInvalidCardExpiry
+	}
+
+	if year < 100 {
+		year += 2000
+	}
+	if year < 2000 {
+		return ErrInvalidCardExpiry
+	}
+
+	// card is valid through the last day of expiry month
+	expiresAt := time.Date(int(year), time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+	if !now.Before(expiresAt) {
+		return ErrCardExpired
+	}
+
+	return nil
+}
+
+// Luhn validates number checksum with Luhn algorithm, number must contain digits only
+func Luhn(number string) bool {
+	sum := 0
+	double := false
+
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// CardBrand detects payment system by card number prefix
+func CardBrand(number string) string {
+	number = NormalizeCardNumber(number)
+
+	switch {
+	case strings.HasPrefix(number, "4"):
+		return "visa"
+	case hasPrefixInRange(number, 2, 51, 55), hasPrefixInRange(number, 4, 2221, 2720):
+		return "mastercard"
+	case hasPrefixInRange(number, 4, 2200, 2204):
+		return "mir"
+	case strings.HasPrefix(number, "34"), strings.HasPrefix(number, "37"):
+		return "amex"
+	case hasPrefixInRange(number, 4, 3528, 3589):
+		return "jcb"
+	case strings.HasPrefix(number, "6011"), strings.HasPrefix(number, "65"), hasPrefixInRange(number, 3, 644, 649):
+		return "discover"
+	case strings.HasPrefix(number, "62"):
+		return "unionpay"
+	case hasPrefixInRange(number, 2, 56, 69), strings.HasPrefix(number, "50"):
+		return "maestro"
+	}
+
+	return "unknown"
+}
+
+// NewCardPreview returns masked card data for secret metadata
+func NewCardPreview(card *api.Card) *api.CardPreview {
+	number := NormalizeCardNumber(card.GetNumber())
+
+	lastFour := number
+	if len(number) > 4 {
+		lastFour = number[len(number)-4:]
+	}
+
+	return &api.CardPreview{
+		LastFour:    lastFour,
+		Brand:       CardBrand(number),
+		ExpiryMonth: card.GetExpiryMonth(),
+		ExpiryYear:  card.GetExpiryYear(),
+	}
+}
+
+// NormalizeCardNumber removes spaces and dashes usually used to group card number digits
+func NormalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+func hasPrefixInRange(number string, digits, from, to int) bool {
+	if len(number) < digits {
+		return false
+	}
+
+	prefix := 0
+	for _, c := range number[:digits] {
+		prefix = prefix*10 + int(c-'0')
+	}
+
+	return prefix >= from && prefix <= to
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,170 @@
+package secrets
+
+import (
+	"errors"
+	"github.com/renatus-cartesius/nedovault/api"
+	"testing"
+	"time"
+)
+
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		name   string
+		number string
+		want   bool
+	}{
+		{name: "visa", number: "4111111111111111", want: true},
+		{name: "mastercard", number: "5555555555554444", want: true},
+		{name: "amex", number: "378282246310005", want: true},
+		{name: "mir", number: "2200000000000004", want: true},
+		{name: "wrong check digit", number: "4111111111111112", want: false},
+		{name: "swapped digits", number: "4111111111111161", want: false},
+		{name: "zeros", number: "0000000000000000", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Luhn(tt.number); got != tt.want {
+				t.Errorf("Luhn(%q) = %v, want %v", tt.number, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCard(t *testing.T) {
+	now := time.Date(2025, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		card *api.Card
+		want error
+	}{
+		{
+			name: "valid",
+			card: &api.Card{Number: "4111 1111 1111 1111", ExpiryMonth: 12, ExpiryYear: 2027, Cvv: "123"},
+		},
+		{
+			name: "two-digit year",
+			card: &api.Card{Number: "4111-1111-1111-1111", ExpiryMonth: 1, ExpiryYear: 27},
+		},
+		{
+			name: "valid through the expiry month",
+			card: &api.Card{Number: "4111111111111111", ExpiryMonth: 3, ExpiryYear: 2025},
+		},
+		{
+			name: "expired last month",
+			card: &api.Card{Number: "4111111111111111", ExpiryMonth: 2, ExpiryYear: 2025},
+			want: ErrCardExpired,
+		},
+		{
+			name: "luhn mismatch",
+			card: &api.Card{Number: "4111111111111112", ExpiryMonth: 12, ExpiryYear: 2027},
+			want: ErrInvalidCardNumber,
+		},
+		{
+			name: "too short",
+			card: &api.Card{Number: "42424242424", ExpiryMonth: 12, ExpiryYear: 2027},
+			want: ErrInvalidCardNumber,
+		},
+		{
+			name: "letters",
+			card: &api.Card{Number: "4111a11111111111", ExpiryMonth: 12, ExpiryYear: 2027},
+			want: ErrInvalidCardNumber,
+		},
+		{
+			name: "zero month",
+			card: &api.Card{Number: "4111111111111111", ExpiryMonth: 0, ExpiryYear: 2027},
+			want: ErrInvalidCardExpiry,
+		},
+		{
+			name: "thirteenth month",
+			card: &api.Card{Number: "4111111111111111", ExpiryMonth: 13, ExpiryYear: 2027},
+			want: ErrInvalidCardExpiry,
+		},
+		{
+			name: "year before 2000",
+			card: &api.Card{Number: "4111111111111111", ExpiryMonth: 12, ExpiryYear: 1999},
+			want: ErrInvalidCardExpiry,
+		},
+		{
+			name: "short cvv",
+			card: &api.Card{Number: "4111111111111111", ExpiryMonth: 12, ExpiryYear: 2027, Cvv: "12"},
+			want: ErrInvalidCVV,
+		},
+		{
+			name: "cvv with letters",
+			card: &api.Card{Number: "4111111111111111", ExpiryMonth: 12, ExpiryYear: 2027, Cvv: "12a"},
+			want: ErrInvalidCVV,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateCard(tt.card, now); !errors.Is(err, tt.want) {
+				t.Errorf("ValidateCard() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCardPreview(t *testing.T) {
+	tests := []struct {
+		name   string
+		number string
+		want   *api.CardPreview
+	}{
+		{name: "visa", number: "4111 1111 1111 1111", want: &api.CardPreview{LastFour: "1111", Brand: "visa"}},
+		{name: "mastercard 2-series", number: "2221000000000009", want: &api.CardPreview{LastFour: "0009", Brand: "mastercard"}},
+		{name: "mir", number: "2200000000000004", want: &api.CardPreview{LastFour: "0004", Brand: "mir"}},
+		{name: "amex", number: "378282246310005", want: &api.CardPreview{LastFour: "0005", Brand: "amex"}},
+		{name: "unknown", number: "9999999999999995", want: &api.CardPreview{LastFour: "9995", Brand: "unknown"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewCardPreview(&api.Card{Number: tt.number, ExpiryMonth: 12, ExpiryYear: 2027})
+			if got.GetLastFour() != tt.want.GetLastFour() || got.GetBrand() != tt.want.GetBrand() {
+				t.Errorf("NewCardPreview() = %v, want %v", got, tt.want)
+			}
+			if got.GetExpiryMonth() != 12 || got.GetExpiryYear() != 2027 {
+				t.Errorf("NewCardPreview() expiry = %d/%d, want 12/2027", got.GetExpiryMonth(), got.GetExpiryYear())
+			}
+
+			if err := ValidatePreview(api.SecretType_TYPE_CARD, got, nil, time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)); err != nil {
+				t.Errorf("ValidatePreview() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestValidatePreview(t *testing.T) {
+	now := time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)
+	card := &api.CardPreview{LastFour: "1111", Brand: "visa", ExpiryMonth: 12, ExpiryYear: 2027}
+
+	tests := []struct {
+		name       string
+		secretType api.SecretType
+		card       *api.CardPreview
+		sshKey     *api.SshKeyPreview
+		want       error
+	}{
+		{name: "no preview", secretType: api.SecretType_TYPE_TEXT},
+		{name: "card", secretType: api.SecretType_TYPE_CARD, card: card},
+		{name: "card preview of text", secretType: api.SecretType_TYPE_TEXT, card: card, want: ErrInvalidPreview},
+		{name: "full number", secretType: api.SecretType_TYPE_CARD, card: &api.CardPreview{LastFour: "4111111111111111", Brand: "visa", ExpiryMonth: 12, ExpiryYear: 2027}, want: ErrInvalidPreview},
+		{name: "letters in last four", secretType: api.SecretType_TYPE_CARD, card: &api.CardPreview{LastFour: "11a1", Brand: "visa", ExpiryMonth: 12, ExpiryYear: 2027}, want: ErrInvalidPreview},
+		{name: "unknown brand", secretType: api.SecretType_TYPE_CARD, card: &api.CardPreview{LastFour: "1111", Brand: "cvv 123", ExpiryMonth: 12, ExpiryYear: 2027}, want: ErrInvalidPreview},
+		{name: "valid through the expiry month", secretType: api.SecretType_TYPE_CARD, card: &api.CardPreview{LastFour: "1111", Brand: "visa", ExpiryMonth: 3, ExpiryYear: 25}},
+		{name: "expired", secretType: api.SecretType_TYPE_CARD, card: &api.CardPreview{LastFour: "1111", Brand: "visa", ExpiryMonth: 2, ExpiryYear: 2025}, want: ErrCardExpired},
+		{name: "no expiry", secretType: api.SecretType_TYPE_CARD, card: &api.CardPreview{LastFour: "1111", Brand: "visa"}, want: ErrInvalidCardExpiry},
+		{name: "both previews", secretType: api.SecretType_TYPE_CARD, card: card, sshKey: &api.SshKeyPreview{}, want: ErrInvalidPreview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePreview(tt.secretType, tt.card, tt.sshKey, now); !errors.Is(err, tt.want) {
+				t.Errorf("ValidatePreview() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
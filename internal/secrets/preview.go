@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"errors"
+	"github.com/renatus-cartesius/nedovault/api"
+	"golang.org/x/crypto/ssh"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidPreview = errors.New("secret preview is invalid")
+)
+
+// cardBrands are brands returned by CardBrand
+var cardBrands = map[string]struct{}{
+	"visa":       {},
+	"mastercard": {},
+	"mir":        {},
+	"amex":       {},
+	"jcb":        {},
+	"discover":   {},
+	"unionpay":   {},
+	"maestro":    {},
+	"unknown":    {},
+}
+
+// ValidatePreview checks previews passed by the client with the encrypted secret, as the server can't make them itself.
+// Card preview may carry only the last four digits, a known brand and the expiry date, which must not be passed by now.
+// Ssh key preview may carry only a public key with its fingerprint. Preview must match the type of the secret
+func ValidatePreview(secretType api.SecretType, card *api.CardPreview, sshKey *api.SshKeyPreview, now time.Time) error {
+	if card != nil {
+		if secretType != api.SecretType_TYPE_CARD || sshKey != nil {
+			return ErrInvalidPreview
+		}
+
+		return validateCardPreview(card, now)
+	}
+
+	if sshKey != nil {
+		if secretType != api.SecretType_TYPE_SSH_KEY {
+			return ErrInvalidPreview
+		}
+
+		return validateSSHKeyPreview(sshKey)
+	}
+
+	return nil
+}
+
+func validateCardPreview(card *api.CardPreview, now time.Time) error {
+	if len(card.GetLastFour()) != 4 || !isDigits(card.GetLastFour()) {
+		return ErrInvalidPreview
+	}
+
+	if _, ok := cardBrands[card.GetBrand()]; !ok {
+		return ErrInvalidPreview
+	}
+
+	return validateExpiry(card.GetExpiryMonth(), card.GetExpiryYear(), now)
+}
+
+func validateSSHKeyPreview(sshKey *api.SshKeyPreview) error {
+	pub, comment, options, rest, err := ssh.ParseAuthorizedKey([]byte(sshKey.GetPublicKey()))
+	if err != nil || comment != "" || options != nil || len(rest) != 0 {
+		return ErrInvalidPreview
+	}
+
+	// public key is kept in the form made by NewSSHKeyPreview
+	if sshKey.GetPublicKey() != strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub))) {
+		return ErrInvalidPreview
+	}
+
+	if sshKey.GetFingerprint() != ssh.FingerprintSHA256(pub) {
+		return ErrInvalidPreview
+	}
+
+	return nil
+}
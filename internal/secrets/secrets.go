@@ -0,0 +1,39 @@
+// Package secrets contains type specific validation and previews of vault secrets
+package secrets
+
+import (
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/otp"
+	"time"
+)
+
+// Validate checks secret content depending on its type
+func Validate(secret *api.Secret, now time.Time) error {
+	switch s := secret.GetSecret().(type) {
+	case *api.Secret_Card:
+		return ValidateCard(s.Card, now)
+	case *api.Secret_Otp:
+		return otp.Validate(s.Otp)
+	case *api.Secret_SshKey:
+		_, err := ParseSSHKey(s.SshKey)
+		return err
+	}
+
+	return nil
+}
+
+// FillPreview sets masked preview of the secret to its metadata
+func FillPreview(meta *api.SecretMeta, secret *api.Secret) {
+	switch s := secret.GetSecret().(type) {
+	case *api.Secret_Card:
+		meta.Preview = &api.SecretMeta_Card{
+			Card: NewCardPreview(s.Card),
+		}
+	case *api.Secret_SshKey:
+		if preview, err := NewSSHKeyPreview(s.SshKey); err == nil {
+			meta.Preview = &api.SecretMeta_SshKey{
+				SshKey: preview,
+			}
+		}
+	}
+}
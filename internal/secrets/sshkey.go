@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"errors"
+	"github.com/renatus-cartesius/nedovault/api"
+	"golang.org/x/crypto/ssh"
+	"strings"
+)
+
+var (
+	ErrInvalidSSHKey = errors.New("ssh private key is invalid or passphrase is wrong")
+)
+
+// ParseSSHKey decodes private key of the secret, decrypting it with passphrase if it is set
+func ParseSSHKey(key *api.SshKey) (any, error) {
+	var (
+		raw any
+		err error
+	)
+
+	if key.GetPassphrase() != "" {
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(key.GetPrivateKey()), []byte(key.GetPassphrase()))
+	} else {
+		raw, err = ssh.ParseRawPrivateKey([]byte(key.GetPrivateKey()))
+	}
+
+	if err != nil {
+		return nil, ErrInvalidSSHKey
+	}
+
+	return raw, nil
+}
+
+// NewSSHKeySigner returns signer for the private key of the secret
+func NewSSHKeySigner(key *api.SshKey) (ssh.Signer, error) {
+	raw, err := ParseSSHKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return ssh.NewSignerFromKey(raw)
+}
+
+// NewSSHKeyPreview returns public part of the key for secret metadata
+func NewSSHKeyPreview(key *api.SshKey) (*api.SshKeyPreview, error) {
+	signer, err := NewSSHKeySigner(key)
+	if err != nil {
+		return nil, err
+	}
+
+	pub := signer.PublicKey()
+
+	return &api.SshKeyPreview{
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub))),
+		Fingerprint: ssh.FingerprintSHA256(pub),
+	}, nil
+}
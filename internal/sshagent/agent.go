@@ -0,0 +1,340 @@
+// Package sshagent implements ssh-agent serving private keys stored in the vault
+package sshagent
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/renatus-cartesius/metricserv/pkg/logger"
+	"github.com/renatus-cartesius/nedovault/api"
+	vault "github.com/renatus-cartesius/nedovault/internal/client"
+	"github.com/renatus-cartesius/nedovault/internal/e2e"
+	"github.com/renatus-cartesius/nedovault/internal/secrets"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	ErrReadOnly    = errors.New("vault agent is read-only, add keys to the vault instead")
+	ErrLocked      = errors.New("agent is locked")
+	ErrNotLocked   = errors.New("agent is not locked")
+	ErrBadPass     = errors.New("incorrect passphrase")
+	ErrKeyNotFound = errors.New("key not found in the vault")
+	ErrSocketInUse = errors.New("agent socket is served by another process")
+	ErrNotSocket   = errors.New("agent socket path is taken by a file that is not a socket")
+)
+
+// Agent is a read-only ssh agent, keys are fetched from the vault on every request
+// so the private keys never touch the disk
+type Agent struct {
+	client api.NedoVaultClient
+	cipher *e2e.Cipher
+	// ctx carries token of the logged in user
+	ctx context.Context
+
+	mx         sync.Mutex
+	passphrase []byte
+}
+
+var _ agent.ExtendedAgent = (*Agent)(nil)
+
+func NewAgent(ctx context.Context, client api.NedoVaultClient, cipher *e2e.Cipher) *Agent {
+	return &Agent{
+		client: client,
+		cipher: cipher,
+		ctx:    ctx,
+	}
+}
+
+// Serve listens unix socket at path and serves agent protocol until ctx is done
+func (a *Agent) Serve(ctx context.Context, path string) error {
+	lis, err := listen(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	go func() {
+		<-ctx.Done()
+		lis.Close()
+	}()
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		go func() {
+			defer conn.Close()
+
+			if err := agent.ServeAgent(a, conn); err != nil && !errors.Is(err, net.ErrClosed) {
+				logger.Log.Debug(
+					"agent connection closed",
+					zap.Error(err),
+				)
+			}
+		}()
+	}
+}
+
+// listen creates the socket in a directory only the user can enter and moves it to path once it is readable
+// only by the user, so no one connects to it meanwhile. Socket left at path by the agent that has not exited cleanly is replaced
+func listen(path string) (net.Listener, error) {
+	if err := removeStale(path); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".nedovault-agent-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "agent.sock")
+
+	lis, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmp, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	// the socket is moved, so it is removed at path by Serve
+	lis.SetUnlinkOnClose(false)
+
+	if err = os.Chmod(tmp, 0600); err != nil {
+		lis.Close()
+		return nil, err
+	}
+
+	if err = os.Rename(tmp, path); err != nil {
+		lis.Close()
+		return nil, err
+	}
+
+	return lis, nil
+}
+
+// removeStale removes the socket at path if nobody serves it
+func removeStale(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.Mode().Type() != os.ModeSocket {
+		return fmt.Errorf("%w: %s", ErrNotSocket, path)
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("%w: %s", ErrSocketInUse, path)
+	}
+
+	return os.Remove(path)
+}
+
+func (a *Agent) List() ([]*agent.Key, error) {
+	if a.isLocked() {
+		return []*agent.Key{}, nil
+	}
+
+	metas, err := a.listKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*agent.Key, 0, len(metas))
+
+	for _, meta := range metas {
+		pub, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(meta.GetSshKey().GetPublicKey()))
+		if err != nil {
+			logger.Log.Error(
+				"error parsing public key from secret metadata",
+				zap.String("key", string(meta.GetKey())),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		if comment == "" {
+			comment = string(meta.GetKey())
+		}
+
+		keys = append(keys, &agent.Key{
+			Format:  pub.Type(),
+			Blob:    pub.Marshal(),
+			Comment: comment,
+		})
+	}
+
+	return keys, nil
+}
+
+func (a *Agent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+func (a *Agent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	if a.isLocked() {
+		return nil, ErrLocked
+	}
+
+	metas, err := a.listKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := key.Marshal()
+
+	for _, meta := range metas {
+		pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(meta.GetSshKey().GetPublicKey()))
+		if err != nil || !bytes.Equal(pub.Marshal(), wanted) {
+			continue
+		}
+
+		signer, err := a.signer(meta.GetKey())
+		if err != nil {
+			return nil, err
+		}
+
+		var algorithm string
+		switch {
+		case flags&agent.SignatureFlagRsaSha256 != 0:
+			algorithm = ssh.KeyAlgoRSASHA256
+		case flags&agent.SignatureFlagRsaSha512 != 0:
+			algorithm = ssh.KeyAlgoRSASHA512
+		}
+
+		if algorithm != "" {
+			if algorithmSigner, ok := signer.(ssh.AlgorithmSigner); ok {
+				return algorithmSigner.SignWithAlgorithm(nil, data, algorithm)
+			}
+		}
+
+		return signer.Sign(nil, data)
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+func (a *Agent) Signers() ([]ssh.Signer, error) {
+	if a.isLocked() {
+		return nil, ErrLocked
+	}
+
+	metas, err := a.listKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	signers := make([]ssh.Signer, 0, len(metas))
+
+	for _, meta := range metas {
+		signer, err := a.signer(meta.GetKey())
+		if err != nil {
+			return nil, err
+		}
+
+		signers = append(signers, signer)
+	}
+
+	return signers, nil
+}
+
+func (a *Agent) Add(key agent.AddedKey) error {
+	return ErrReadOnly
+}
+
+func (a *Agent) Remove(key ssh.PublicKey) error {
+	return ErrReadOnly
+}
+
+func (a *Agent) RemoveAll() error {
+	return ErrReadOnly
+}
+
+func (a *Agent) Lock(passphrase []byte) error {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	if a.passphrase != nil {
+		return ErrLocked
+	}
+
+	a.passphrase = bytes.Clone(passphrase)
+
+	return nil
+}
+
+func (a *Agent) Unlock(passphrase []byte) error {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	if a.passphrase == nil {
+		return ErrNotLocked
+	}
+
+	if subtle.ConstantTimeCompare(a.passphrase, passphrase) != 1 {
+		return ErrBadPass
+	}
+
+	a.passphrase = nil
+
+	return nil
+}
+
+func (a *Agent) Extension(extensionType string, contents []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
+
+func (a *Agent) isLocked() bool {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	return a.passphrase != nil
+}
+
+// listKeys returns metadata of ssh key secrets having public key preview
+func (a *Agent) listKeys() ([]*api.SecretMeta, error) {
+	resp, err := a.client.ListSecretsMeta(a.ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	metas := make([]*api.SecretMeta, 0)
+
+	for _, meta := range resp.GetSecretsMeta() {
+		if meta.GetType() == api.SecretType_TYPE_SSH_KEY && meta.GetSshKey() != nil {
+			metas = append(metas, meta)
+		}
+	}
+
+	return metas, nil
+}
+
+func (a *Agent) signer(key []byte) (ssh.Signer, error) {
+	resp, err := vault.GetSecret(a.ctx, a.client, a.cipher, key)
+	if err != nil {
+		return nil, err
+	}
+
+	sshKey := resp.GetSecret().GetSshKey()
+	if sshKey == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	return secrets.NewSSHKeySigner(sshKey)
+}
@@ -0,0 +1,131 @@
+package sshagent
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitSocket waits until the agent serves the socket
+func waitSocket(t *testing.T, path string) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	t.Fatalf("agent does not serve %s", path)
+}
+
+func TestServeSocket(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare leaves a file at the socket path
+		prepare func(t *testing.T, path string)
+		want    error
+	}{
+		{
+			name:    "no file",
+			prepare: func(t *testing.T, path string) {},
+		},
+		{
+			name: "stale socket",
+			prepare: func(t *testing.T, path string) {
+				lis, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				lis.SetUnlinkOnClose(false)
+				lis.Close()
+			},
+		},
+		{
+			name: "served socket",
+			prepare: func(t *testing.T, path string) {
+				lis, err := net.Listen("unix", path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() {
+					lis.Close()
+				})
+			},
+			want: ErrSocketInUse,
+		},
+		{
+			name: "regular file",
+			prepare: func(t *testing.T, path string) {
+				if err := os.WriteFile(path, []byte("keep me"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: ErrNotSocket,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := os.MkdirTemp("", "agent")
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				os.RemoveAll(dir)
+			})
+
+			path := filepath.Join(dir, "agent.sock")
+			tt.prepare(t, path)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- NewAgent(ctx, nil, nil).Serve(ctx, path)
+			}()
+
+			if tt.want != nil {
+				if err = <-done; !errors.Is(err, tt.want) {
+					t.Errorf("Serve() error = %v, want %v", err, tt.want)
+				}
+				return
+			}
+
+			waitSocket(t, path)
+
+			info, err := os.Lstat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("socket permissions = %o, want 600", info.Mode().Perm())
+			}
+
+			// only the socket is left in the directory
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("directory of the socket has %d entries, want 1", len(entries))
+			}
+
+			cancel()
+			if err = <-done; err != nil {
+				t.Errorf("Serve() error = %v", err)
+			}
+
+			if _, err = os.Lstat(path); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("socket is not removed after Serve returns: %v", err)
+			}
+		})
+	}
+}
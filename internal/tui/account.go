@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"strings"
+)
+
+// deleteConfirmation has to be typed to delete the account
+const deleteConfirmation = "DELETE"
+
+var (
+	ErrPasswordsMismatch = errors.New("new passwords do not match")
+	ErrNotConfirmed      = fmt.Errorf("type %s to confirm deletion", deleteConfirmation)
+)
+
+type accountAction int
+
+const (
+	actionChangePassword accountAction = iota
+	actionDeleteAccount
+)
+
+// accountPrompt asks the passwords to change the password or delete the account
+type accountPrompt struct {
+	action  accountAction
+	inputs  []textinput.Model
+	current int
+	active  bool
+	err     error
+}
+
+func newAccountPrompt(action accountAction) accountPrompt {
+	prompts := []string{"Current password: ", "New password: ", "Repeat new password: "}
+	if action == actionDeleteAccount {
+		prompts = []string{"Password: ", "Two-factor code: ", fmt.Sprintf("Type %s to confirm: ", deleteConfirmation)}
+	}
+
+	inputs := make([]textinput.Model, 0, len(prompts))
+
+	for i, prompt := range prompts {
+		ti := textinput.New()
+		ti.Prompt = prompt
+		ti.CharLimit = 100
+		ti.Width = 100
+		ti.TextStyle = noStyle
+		ti.PromptStyle = noStyle
+
+		if strings.Contains(strings.ToLower(prompt), "password") {
+			ti.EchoMode = textinput.EchoPassword
+		}
+
+		if action == actionDeleteAccount && i == 1 {
+			ti.Placeholder = "only if two-factor authentication is enabled"
+		}
+
+		inputs = append(inputs, ti)
+	}
+
+	ap := accountPrompt{
+		action: action,
+		inputs: inputs,
+		active: true,
+	}
+	ap.focus(0)
+
+	return ap
+}
+
+// focus moves the cursor to the input, wrapping around
+func (ap *accountPrompt) focus(i int) tea.Cmd {
+	ap.current = (i + len(ap.inputs)) % len(ap.inputs)
+
+	var cmd tea.Cmd
+	for j := range ap.inputs {
+		if j == ap.current {
+			cmd = ap.inputs[j].Focus()
+			ap.inputs[j].PromptStyle = focusedStyle
+			ap.inputs[j].TextStyle = focusedStyle
+			continue
+		}
+
+		ap.inputs[j].Blur()
+		ap.inputs[j].PromptStyle = noStyle
+		ap.inputs[j].TextStyle = noStyle
+	}
+
+	return cmd
+}
+
+func (ap *accountPrompt) value(i int) string {
+	return ap.inputs[i].Value()
+}
+
+func (ap accountPrompt) View() string {
+	var rend strings.Builder
+
+	if ap.action == actionDeleteAccount {
+		rend.WriteString(headerStyle.Render("Delete account"))
+		rend.WriteString("\n\nall secrets, their history and trash are removed forever")
+	} else {
+		rend.WriteString(headerStyle.Render("Change password"))
+		rend.WriteString("\n\nall sessions are closed, master password is not changed")
+	}
+
+	if ap.err != nil {
+		rend.WriteString(fmt.Sprint("\n\nERROR: ", ap.err))
+	}
+
+	rend.WriteString("\n")
+	for _, i := range ap.inputs {
+		rend.WriteString(fmt.Sprintf("\n%s", i.View()))
+	}
+
+	rend.WriteString("\n\nenter: submit • tab: next field • esc: cancel")
+
+	return historyStyle.Render(rend.String())
+}
@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/renatus-cartesius/nedovault/internal/client"
+	"strings"
+	"time"
+)
+
+// conflictsView is a pane with changes made offline to the secrets that were changed in the vault meanwhile
+type conflictsView struct {
+	changes []*client.Change
+	current int
+	active  bool
+}
+
+// newConflictsView shows conflicting changes of the replica
+func newConflictsView(changes []*client.Change) conflictsView {
+	cv := conflictsView{active: true}
+
+	for _, ch := range changes {
+		if ch.Conflict != "" {
+			cv.changes = append(cv.changes, ch)
+		}
+	}
+
+	return cv
+}
+
+func (cv *conflictsView) selected() *client.Change {
+	if cv.current < 0 || cv.current >= len(cv.changes) {
+		return nil
+	}
+
+	return cv.changes[cv.current]
+}
+
+func (cv *conflictsView) move(delta int) {
+	cv.current += delta
+
+	if cv.current >= len(cv.changes) {
+		cv.current = len(cv.changes) - 1
+	}
+	if cv.current < 0 {
+		cv.current = 0
+	}
+}
+
+// remove drops the change from the pane after the conflict was resolved
+func (cv *conflictsView) remove(key []byte) {
+	for i, ch := range cv.changes {
+		if string(ch.Key) == string(key) {
+			cv.changes = append(cv.changes[:i], cv.changes[i+1:]...)
+			break
+		}
+	}
+
+	cv.move(0)
+}
+
+func (cv *conflictsView) View() string {
+	var rend strings.Builder
+
+	rend.WriteString(headerStyle.Render("Sync conflicts"))
+	rend.WriteString("\n\n")
+
+	if len(cv.changes) == 0 {
+		rend.WriteString("no conflicts")
+	}
+
+	for i, ch := range cv.changes {
+		line := fmt.Sprintf("%s  %s offline at %s", ch.Key, ch.Op, ch.QueuedAt.Local().Format(time.DateTime))
+		line += fmt.Sprintf("\n    %s", ch.Conflict)
+
+		if i == cv.current {
+			line = focusedStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+
+		rend.WriteString(line + "\n")
+	}
+
+	rend.WriteString("\nm: keep mine • t: keep theirs • b: keep both • esc: close")
+
+	return historyStyle.Render(rend.String())
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/renatus-cartesius/nedovault/api"
+	"strings"
+	"time"
+)
+
+var (
+	historyStyle = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("63")).Padding(1, 2)
+)
+
+// historyView is a pane with previous versions of the secret
+type historyView struct {
+	meta     *api.SecretMeta
+	versions []*api.SecretMeta
+	current  int
+	active   bool
+}
+
+func (hv *historyView) selected() *api.SecretMeta {
+	if hv.current < 0 || hv.current >= len(hv.versions) {
+		return nil
+	}
+
+	return hv.versions[hv.current]
+}
+
+func (hv *historyView) move(delta int) {
+	hv.current += delta
+
+	if hv.current >= len(hv.versions) {
+		hv.current = len(hv.versions) - 1
+	}
+	if hv.current < 0 {
+		hv.current = 0
+	}
+}
+
+func (hv *historyView) View() string {
+	var rend strings.Builder
+
+	rend.WriteString(headerStyle.Render(fmt.Sprintf("History of %s (revision %d)", hv.meta.GetKey(), hv.meta.GetRevision())))
+	rend.WriteString("\n\n")
+
+	if len(hv.versions) == 0 {
+		rend.WriteString("no previous versions")
+	}
+
+	for i, v := range hv.versions {
+		line := fmt.Sprintf("rev %d  %s  %s", v.GetRevision(), v.GetTimestamp().AsTime().Format(time.DateTime), v.GetName())
+
+		if i == hv.current {
+			line = focusedStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+
+		rend.WriteString(line + "\n")
+	}
+
+	rend.WriteString("\nenter: show • R: restore • esc: close")
+
+	return historyStyle.Render(rend.String())
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/client"
+)
+
+// DialFunc connects to the vault the profile points to
+type DialFunc func(profile *client.Profile) (*client.Conn, error)
+
+// profilePicker switches the vault the login page authorizes against
+type profilePicker struct {
+	profiles []*client.Profile
+	current  int
+	dial     DialFunc
+	// clients are dialed once per profile, so switching back keeps the connection
+	conns map[string]*client.Conn
+}
+
+func newProfilePicker(profiles []*client.Profile, current *client.Profile, dial DialFunc) *profilePicker {
+	pp := &profilePicker{
+		profiles: profiles,
+		dial:     dial,
+		conns:    make(map[string]*client.Conn),
+	}
+
+	for i, p := range profiles {
+		if p.Name == current.Name {
+			pp.current = i
+		}
+	}
+
+	return pp
+}
+
+func (pp *profilePicker) selected() *client.Profile {
+	return pp.profiles[pp.current]
+}
+
+// client returns the client of the selected profile dialing it on first use
+func (pp *profilePicker) client() (api.NedoVaultClient, error) {
+	p := pp.selected()
+
+	if conn, ok := pp.conns[p.Name]; ok {
+		return conn, nil
+	}
+
+	conn, err := pp.dial(p)
+	if err != nil {
+		return nil, err
+	}
+
+	pp.conns[p.Name] = conn
+
+	return conn, nil
+}
+
+// replica returns the offline replica of the selected profile, nil if the profile was not dialed
+func (pp *profilePicker) replica() *client.Replica {
+	conn, ok := pp.conns[pp.selected().Name]
+	if !ok {
+		return nil
+	}
+
+	return conn.Replica
+}
+
+// credentials returns the saved session of the selected profile, nil if the user has to log in
+func (pp *profilePicker) credentials() *client.Credentials {
+	conn, ok := pp.conns[pp.selected().Name]
+	if !ok {
+		return nil
+	}
+
+	return conn.Session.Credentials()
+}
+
+// next selects the following profile, the selection wraps around
+func (pp *profilePicker) next() {
+	pp.current = (pp.current + 1) % len(pp.profiles)
+}
+
+func (pp *profilePicker) View() string {
+	p := pp.selected()
+
+	view := fmt.Sprintf("Profile: %s (%s)", p.Name, p.Address)
+	if len(pp.profiles) > 1 {
+		view += fmt.Sprintf(" [%d/%d]", pp.current+1, len(pp.profiles))
+	}
+
+	return view
+}
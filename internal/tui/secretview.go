@@ -1,16 +1,26 @@
 package tui
 
 import (
+	"fmt"
 	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/otp"
+	"time"
 )
 
+// otpTick redraws otp code countdown, ticks of previously shown secrets are ignored by seq
+type otpTick struct {
+	seq int
+}
+
 type SecretView struct {
 	Secret *api.Secret
 	style  lipgloss.Style
 
-	ta textarea.Model
+	ta  textarea.Model
+	seq int
 }
 
 func NewSecretView() *SecretView {
@@ -25,11 +35,54 @@ func NewSecretView() *SecretView {
 	}
 }
 
-func (sv *SecretView) Update(secret *api.Secret) {
-	sv.ta, _ = sv.ta.Update("sdf")
+// Update shows the passed secret and returns command for refreshing it if needed
+func (sv *SecretView) Update(secret *api.Secret) tea.Cmd {
+	sv.Secret = secret
+	sv.seq++
+
+	if secret.GetOtp() != nil {
+		return sv.tick()
+	}
+
+	return nil
+}
+
+// HandleTick reschedules countdown while the same otp secret is shown
+func (sv *SecretView) HandleTick(t otpTick) tea.Cmd {
+	if t.seq != sv.seq || sv.Secret.GetOtp() == nil {
+		return nil
+	}
+
+	return sv.tick()
+}
+
+func (sv *SecretView) tick() tea.Cmd {
+	seq := sv.seq
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return otpTick{seq: seq}
+	})
 }
 
 func (sv *SecretView) View() string {
+	if o := sv.Secret.GetOtp(); o != nil {
+		return sv.style.Render(renderOtp(o, time.Now()))
+	}
 
 	return sv.style.Render(sv.Secret.String())
 }
+
+func renderOtp(o *api.Otp, now time.Time) string {
+	code, err := otp.Generate(o, now)
+	if err != nil {
+		return fmt.Sprint("ERROR: ", err)
+	}
+
+	var s string
+	if o.GetIssuer() != "" || o.GetAccount() != "" {
+		s += fmt.Sprintf("%s %s\n\n", o.GetIssuer(), o.GetAccount())
+	}
+
+	s += fmt.Sprintf("%s\n\nexpires in %ds", focusedStyle.Render(code), int(otp.Remaining(o, now).Seconds()))
+
+	return s
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/renatus-cartesius/nedovault/api"
+	"strings"
+	"time"
+)
+
+// sessionsView is a pane with devices the user is logged in on, their sessions can be revoked
+type sessionsView struct {
+	sessions []*api.Session
+	current  int
+	active   bool
+}
+
+func (sv *sessionsView) selected() *api.Session {
+	if sv.current < 0 || sv.current >= len(sv.sessions) {
+		return nil
+	}
+
+	return sv.sessions[sv.current]
+}
+
+func (sv *sessionsView) move(delta int) {
+	sv.current += delta
+
+	if sv.current >= len(sv.sessions) {
+		sv.current = len(sv.sessions) - 1
+	}
+	if sv.current < 0 {
+		sv.current = 0
+	}
+}
+
+// remove drops the session from the pane after it was revoked
+func (sv *sessionsView) remove(id string) {
+	for i, s := range sv.sessions {
+		if s.GetId() == id {
+			sv.sessions = append(sv.sessions[:i], sv.sessions[i+1:]...)
+			break
+		}
+	}
+
+	sv.move(0)
+}
+
+func (sv *sessionsView) View() string {
+	var rend strings.Builder
+
+	rend.WriteString(headerStyle.Render("Sessions"))
+	rend.WriteString("\n\n")
+
+	if len(sv.sessions) == 0 {
+		rend.WriteString("no active sessions")
+	}
+
+	for i, s := range sv.sessions {
+		device := s.GetDevice()
+		if device == "" {
+			device = "unknown device"
+		}
+
+		line := fmt.Sprintf("%s  %s  %s", device, s.GetClientVersion(), s.GetAddress())
+		line += fmt.Sprintf("\n    logged in %s, last seen %s", s.GetCreatedAt().AsTime().Local().Format(time.DateTime), s.GetLastSeen().AsTime().Local().Format(time.DateTime))
+
+		if s.GetCurrent() {
+			line += " (this session)"
+		}
+
+		if i == sv.current {
+			line = focusedStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+
+		rend.WriteString(line + "\n")
+	}
+
+	rend.WriteString("\nx: revoke • esc: close")
+
+	return historyStyle.Render(rend.String())
+}
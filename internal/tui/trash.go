@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/renatus-cartesius/nedovault/api"
+	"strings"
+	"time"
+)
+
+// trashView is a pane with deleted secrets which can be restored or purged
+type trashView struct {
+	secrets []*api.SecretMeta
+	current int
+	active  bool
+}
+
+func (tv *trashView) selected() *api.SecretMeta {
+	if tv.current < 0 || tv.current >= len(tv.secrets) {
+		return nil
+	}
+
+	return tv.secrets[tv.current]
+}
+
+func (tv *trashView) move(delta int) {
+	tv.current += delta
+
+	if tv.current >= len(tv.secrets) {
+		tv.current = len(tv.secrets) - 1
+	}
+	if tv.current < 0 {
+		tv.current = 0
+	}
+}
+
+// remove drops the secret from the pane after it was restored or purged
+func (tv *trashView) remove(key []byte) {
+	for i, s := range tv.secrets {
+		if string(s.GetKey()) == string(key) {
+			tv.secrets = append(tv.secrets[:i], tv.secrets[i+1:]...)
+			break
+		}
+	}
+
+	tv.move(0)
+}
+
+func (tv *trashView) View() string {
+	var rend strings.Builder
+
+	rend.WriteString(headerStyle.Render("Trash"))
+	rend.WriteString("\n\n")
+
+	if len(tv.secrets) == 0 {
+		rend.WriteString("trash is empty")
+	}
+
+	for i, s := range tv.secrets {
+		line := fmt.Sprintf("%s  %s  deleted %s", s.GetKey(), s.GetType(), s.GetDeletedAt().AsTime().Format(time.DateTime))
+
+		if i == tv.current {
+			line = focusedStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+
+		rend.WriteString(line + "\n")
+	}
+
+	rend.WriteString("\nu: restore • P: purge forever • esc: close")
+
+	return historyStyle.Render(rend.String())
+}
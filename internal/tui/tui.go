@@ -2,18 +2,27 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/brianvoe/gofakeit"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/google/uuid"
 	"github.com/renatus-cartesius/metricserv/pkg/logger"
 	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/client"
+	"github.com/renatus-cartesius/nedovault/internal/e2e"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"io"
+	"maps"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -43,7 +52,17 @@ type SecretItem struct {
 
 func (i *SecretItem) Title() string { return string(i.SecretMeta.Key) }
 func (i *SecretItem) Description() string {
-	return fmt.Sprintf("type: %s, updated: %s", i.SecretMeta.Type, i.SecretMeta.Timestamp.AsTime().Format(time.RFC850))
+	desc := fmt.Sprintf("type: %s, updated: %s", i.SecretMeta.Type, i.SecretMeta.Timestamp.AsTime().Format(time.RFC850))
+
+	if card := i.SecretMeta.GetCard(); card != nil {
+		desc += fmt.Sprintf(", %s **** %s", card.Brand, card.LastFour)
+	}
+
+	if sshKey := i.SecretMeta.GetSshKey(); sshKey != nil {
+		desc += fmt.Sprintf(", %s", sshKey.Fingerprint)
+	}
+
+	return desc
 }
 func (i *SecretItem) FilterValue() string { return string(i.SecretMeta.Key) }
 
@@ -51,6 +70,54 @@ type loginPage struct {
 	inputs  []textinput.Model
 	lastErr error
 	current int
+	// register creates a new account instead of logging in
+	register bool
+	// challenge of the password check waiting for the two-factor authentication code
+	challenge string
+	code      textinput.Model
+	// notice tells why the user was logged out
+	notice string
+	// unlock is the saved session of the profile, only the master password is asked to open it
+	unlock *client.Credentials
+}
+
+// focus moves the cursor to the input
+func (lp *loginPage) focus(i int) tea.Cmd {
+	lp.current = i
+
+	var cmd tea.Cmd
+	for j := range lp.inputs {
+		if j == i {
+			// Set focused state
+			cmd = lp.inputs[j].Focus()
+			lp.inputs[j].PromptStyle = focusedStyle
+			lp.inputs[j].TextStyle = focusedStyle
+			continue
+		}
+		// Remove focused state
+		lp.inputs[j].Blur()
+		lp.inputs[j].PromptStyle = noStyle
+		lp.inputs[j].TextStyle = noStyle
+	}
+
+	return cmd
+}
+
+// setUnlock switches the login page to unlocking of the saved session, nil returns to the login form
+func (lp *loginPage) setUnlock(creds *client.Credentials) tea.Cmd {
+	lp.unlock = creds
+
+	if creds == nil {
+		return lp.focus(0)
+	}
+
+	lp.inputs[0].SetValue(creds.Username)
+	return lp.focus(2)
+}
+
+type uploadPrompt struct {
+	input  textinput.Model
+	active bool
 }
 
 type model struct {
@@ -59,11 +126,31 @@ type model struct {
 	//Secret editor view
 	sv *SecretView
 
-	lp         loginPage
-	client     api.NedoVaultClient
+	lp loginPage
+	// File upload prompt
+	up uploadPrompt
+	// Secret history pane
+	hv historyView
+	// Trash pane
+	tv trashView
+	// Sessions pane
+	ss sessionsView
+	// Offline changes conflicting with the vault
+	cv conflictsView
+	// Change password and delete account prompt
+	ap accountPrompt
+	// Secret waiting for delete confirmation, it is deleted only if not changed meanwhile
+	pendingDelete *api.SecretMeta
+	status        string
+
+	client api.NedoVaultClient
+	// Profiles the login page may switch between
+	pp *profilePicker
+	// Cipher derived from master password, secrets are encrypted and decrypted only on the client
+	cipher     *e2e.Cipher
 	token      string
 	username   string
-	tokench    chan string
+	tokench    chan streamLogin
 	isLoggedIn bool
 
 	mx *sync.Mutex
@@ -76,12 +163,26 @@ func (m model) Init() tea.Cmd {
 func (m model) updateLoginPage(msg tea.Msg) (tea.Model, tea.Cmd) {
 	ctx := context.Background()
 
+	if m.lp.challenge != "" {
+		return m.updateCodePrompt(msg)
+	}
+
+	if m.lp.unlock != nil {
+		return m.updateUnlockPrompt(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 
 		switch msg.String() {
 		case "ctrl+t":
 			m.isLoggedIn = !m.isLoggedIn
+		case "ctrl+r":
+			m.lp.register = !m.lp.register
+			m.lp.lastErr = nil
+			return m, nil
+		case "ctrl+p":
+			return m, m.switchProfile()
 		case "ctrl+c":
 			return m, tea.Quit
 		case "enter", "tab", "up", "down":
@@ -94,7 +195,16 @@ func (m model) updateLoginPage(msg tea.Msg) (tea.Model, tea.Cmd) {
 				username := m.lp.inputs[0].Value()
 				password := m.lp.inputs[1].Value()
 
-				res, err := m.client.Authorize(
+				if m.client == nil {
+					return m, nil
+				}
+
+				authorize := m.client.Login
+				if m.lp.register {
+					authorize = m.client.Register
+				}
+
+				res, err := authorize(
 					ctx,
 					&api.AuthRequest{
 						Username: []byte(username),
@@ -107,29 +217,18 @@ func (m model) updateLoginPage(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 
-				ctx = metadata.AppendToOutgoingContext(ctx, "token", res.Token)
-				resp, err := m.client.ListSecretsMeta(
-					ctx,
-					&emptypb.Empty{},
-				)
-
-				var secrets []list.Item
-				for _, sm := range resp.SecretsMeta {
-					secrets = append(secrets, &SecretItem{sm})
+				// account with two-factor authentication is logged in after the code is passed
+				if res.Challenge != "" {
+					m.lp.challenge = res.Challenge
+					m.lp.lastErr = nil
+					m.lp.code.SetValue("")
+					return m, m.lp.code.Focus()
 				}
 
-				m.sp.SetItems(secrets)
-
-				m.lp.lastErr = nil
-				m.token = res.Token
-				m.username = username
-
-				m.lp.inputs[0].SetValue("")
-				m.lp.inputs[1].SetValue("")
-
-				m.tokench <- res.Token
-
-				m.isLoggedIn = true
+				if err = m.completeLogin(ctx, res); err != nil {
+					m.lp.lastErr = err
+					return m, nil
+				}
 			}
 
 			// Cycle indexes
@@ -145,52 +244,707 @@ func (m model) updateLoginPage(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.lp.current = len(m.lp.inputs) - 1
 			}
 
-			cmds := make([]tea.Cmd, len(m.lp.inputs))
-			for i := 0; i <= len(m.lp.inputs)-1; i++ {
-				if i == m.lp.current {
-					// Set focused state
-					cmds[i] = m.lp.inputs[i].Focus()
-					m.lp.inputs[i].PromptStyle = focusedStyle
-					m.lp.inputs[i].TextStyle = focusedStyle
-					continue
+			return m, m.lp.focus(m.lp.current)
+
+		}
+
+	}
+
+	var cmd tea.Cmd
+	m.lp.inputs[m.lp.current], cmd = m.lp.inputs[m.lp.current].Update(msg)
+
+	return m, cmd
+}
+
+// completeLogin derives the cipher from master password and opens the vault with the issued token
+func (m *model) completeLogin(ctx context.Context, res *api.AuthResponse) error {
+	username := m.lp.inputs[0].Value()
+	masterPassword := m.lp.inputs[2].Value()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", res.Token)
+
+	cipher, err := client.Unlock(ctx, m.client, []byte(masterPassword), res.Salt)
+	if err != nil {
+		return err
+	}
+
+	// replica is opened before the first request, so the secrets are listed from it if the vault is unreachable
+	if replica := m.pp.replica(); replica != nil {
+		err = replica.Open([]byte(username), cipher)
+		if errors.Is(err, client.ErrReplicaKey) {
+			return err
+		}
+		if err != nil {
+			logger.Log.Error(
+				"error opening offline replica",
+				zap.Error(err),
+			)
+		}
+	}
+
+	resp, err := m.client.ListSecretsMeta(
+		ctx,
+		&emptypb.Empty{},
+	)
+	if err != nil {
+		if replica := m.pp.replica(); replica != nil {
+			replica.Close()
+		}
+		return err
+	}
+
+	var secrets []list.Item
+	for _, sm := range resp.SecretsMeta {
+		secrets = append(secrets, &SecretItem{sm})
+	}
+
+	m.sp.SetItems(secrets)
+
+	m.lp.lastErr = nil
+	m.lp.notice = ""
+	m.lp.register = false
+	m.lp.unlock = nil
+	m.cipher = cipher
+	m.token = res.Token
+	m.username = username
+
+	m.lp.inputs[0].SetValue("")
+	m.lp.inputs[1].SetValue("")
+	m.lp.inputs[2].SetValue("")
+
+	m.tokench <- streamLogin{client: m.client, replica: m.pp.replica(), token: res.Token}
+
+	m.isLoggedIn = true
+
+	return nil
+}
+
+// resetSession forgets the logged in user and returns to the login page
+func (m *model) resetSession(notice string) {
+	m.token = ""
+	m.cipher = nil
+	m.username = ""
+	m.status = ""
+	m.sv.Secret = nil
+	m.sp.SetItems(nil)
+	m.lp.notice = notice
+	if replica := m.pp.replica(); replica != nil {
+		replica.Close()
+	}
+	m.lp.inputs[0].SetValue(m.pp.selected().Username)
+	m.lp.setUnlock(m.pp.credentials())
+	m.isLoggedIn = false
+}
+
+// switchProfile selects the next profile on the login page and prefills its username,
+// saved session of the profile is offered to unlock instead
+func (m *model) switchProfile() tea.Cmd {
+	m.pp.next()
+
+	m.lp.lastErr = nil
+	m.lp.inputs[0].SetValue(m.pp.selected().Username)
+
+	c, err := m.pp.client()
+	if err != nil {
+		m.client = nil
+		m.lp.lastErr = err
+		return m.lp.setUnlock(nil)
+	}
+
+	m.client = c
+
+	return m.lp.setUnlock(m.pp.credentials())
+}
+
+// updateUnlockPrompt opens the saved session with the master password
+func (m model) updateUnlockPrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "ctrl+p":
+			return m, m.switchProfile()
+		case "ctrl+l":
+			m.lp.lastErr = nil
+			m.lp.inputs[0].SetValue(m.pp.selected().Username)
+			return m, m.lp.setUnlock(nil)
+		case "enter":
+			if m.lp.inputs[2].Value() == "" {
+				return m, nil
+			}
+
+			// tokens are taken from the session by the client interceptors, they are refreshed if needed
+			err := m.completeLogin(context.Background(), &api.AuthResponse{
+				Token: m.lp.unlock.AccessToken,
+				Salt:  m.lp.unlock.Salt,
+			})
+			if err == nil {
+				return m, nil
+			}
+
+			m.lp.lastErr = err
+
+			// session was revoked or expired while the client was closed
+			if m.pp.credentials() == nil {
+				m.lp.lastErr = nil
+				m.lp.notice = "saved session has expired, log in again"
+				return m, m.lp.setUnlock(nil)
+			}
+
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.lp.inputs[2], cmd = m.lp.inputs[2].Update(msg)
+
+	return m, cmd
+}
+
+// updateAccountPrompt changes the password or deletes the account, the user is logged out after both
+func (m model) updateAccountPrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.ap.active = false
+			return m, nil
+		case "tab", "down":
+			return m, m.ap.focus(m.ap.current + 1)
+		case "shift+tab", "up":
+			return m, m.ap.focus(m.ap.current - 1)
+		case "enter":
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "token", m.token)
+
+			if m.ap.action == actionChangePassword {
+				if m.ap.value(1) != m.ap.value(2) {
+					m.ap.err = ErrPasswordsMismatch
+					return m, nil
+				}
+
+				_, err := m.client.ChangePassword(ctx, &api.ChangePasswordRequest{
+					OldPassword: []byte(m.ap.value(0)),
+					NewPassword: []byte(m.ap.value(1)),
+				})
+				if err != nil {
+					m.ap.err = err
+					return m, nil
 				}
-				// Remove focused state
-				m.lp.inputs[i].Blur()
-				m.lp.inputs[i].PromptStyle = noStyle
-				m.lp.inputs[i].TextStyle = noStyle
+
+				m.ap = accountPrompt{}
+				m.resetSession("password changed, log in with the new password")
+				return m, nil
 			}
 
-			return m, tea.Batch(cmds...)
+			if m.ap.value(2) != deleteConfirmation {
+				m.ap.err = ErrNotConfirmed
+				return m, nil
+			}
 
+			_, err := m.client.DeleteAccount(ctx, &api.DeleteAccountRequest{
+				Password: []byte(m.ap.value(0)),
+				Code:     strings.TrimSpace(m.ap.value(1)),
+			})
+			if err != nil {
+				m.ap.err = err
+				return m, nil
+			}
+
+			m.ap = accountPrompt{}
+			m.resetSession("account deleted")
+			return m, nil
 		}
+	}
+
+	var cmd tea.Cmd
+	m.ap.inputs[m.ap.current], cmd = m.ap.inputs[m.ap.current].Update(msg)
+
+	return m, cmd
+}
+
+// updateCodePrompt completes login with the two-factor authentication code
+func (m model) updateCodePrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.lp.challenge = ""
+			m.lp.code.Blur()
+			return m, nil
+		case "enter":
+			ctx := context.Background()
+
+			res, err := m.client.VerifyTOTP(ctx, &api.VerifyTOTPRequest{
+				Challenge: m.lp.challenge,
+				Code:      strings.TrimSpace(m.lp.code.Value()),
+			})
 
+			// challenge is single-use, so the password is checked again after a wrong code
+			m.lp.challenge = ""
+			m.lp.code.SetValue("")
+			m.lp.code.Blur()
+
+			if err != nil {
+				m.lp.lastErr = err
+				return m, nil
+			}
+
+			if err = m.completeLogin(ctx, res); err != nil {
+				m.lp.lastErr = err
+			}
+
+			return m, nil
+		}
 	}
 
 	var cmd tea.Cmd
-	m.lp.inputs[m.lp.current], cmd = m.lp.inputs[m.lp.current].Update(msg)
+	m.lp.code, cmd = m.lp.code.Update(msg)
 
 	return m, cmd
 }
 
+func (m model) updateUploadPrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.up.active = false
+			m.up.input.Blur()
+			return m, nil
+		case "enter":
+			path := m.up.input.Value()
+			m.up.active = false
+			m.up.input.Blur()
+			m.up.input.SetValue("")
+
+			if path == "" {
+				return m, nil
+			}
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "token", m.token)
+			key := []byte(fmt.Sprintf("%s-%s", "file", uuid.NewString()))
+
+			if err := client.UploadFile(ctx, m.client, m.cipher, key, []byte(filepath.Base(path)), 0, path); err != nil {
+				m.status = fmt.Sprint("upload failed: ", err)
+				return m, nil
+			}
+
+			m.status = fmt.Sprint("uploaded ", path)
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.up.input, cmd = m.up.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateHistoryPane(msg tea.Msg) (tea.Model, tea.Cmd) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "token", m.token)
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "h":
+		m.hv.active = false
+		m.sv.Secret = nil
+	case "up", "k":
+		m.hv.move(-1)
+	case "down", "j":
+		m.hv.move(1)
+	case "enter":
+		version := m.hv.selected()
+		if version == nil {
+			return m, nil
+		}
+
+		resp, err := client.GetSecretVersion(ctx, m.client, m.cipher, version.Key, version.Revision)
+		if err != nil {
+			m.status = fmt.Sprint("error getting version: ", err)
+			return m, nil
+		}
+
+		return m, m.sv.Update(resp.Secret)
+	case "R":
+		version := m.hv.selected()
+		if version == nil {
+			return m, nil
+		}
+
+		meta, err := m.client.RestoreSecretVersion(ctx, &api.RestoreSecretVersionRequest{
+			Key:             version.Key,
+			Revision:        version.Revision,
+			CurrentRevision: m.hv.meta.Revision,
+		})
+		if err != nil {
+			m.status = fmt.Sprint("restore failed: ", err)
+			return m, nil
+		}
+
+		m.hv.active = false
+		m.sv.Secret = nil
+		m.status = fmt.Sprintf("restored revision %d as revision %d", version.Revision, meta.Revision)
+	}
+
+	return m, nil
+}
+
+func (m model) updateTrashPane(msg tea.Msg) (tea.Model, tea.Cmd) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "token", m.token)
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "t":
+		m.tv.active = false
+	case "up", "k":
+		m.tv.move(-1)
+	case "down", "j":
+		m.tv.move(1)
+	case "u":
+		secret := m.tv.selected()
+		if secret == nil {
+			return m, nil
+		}
+
+		if _, err := m.client.RestoreSecret(ctx, &api.RestoreSecretRequest{Key: secret.Key}); err != nil {
+			m.status = fmt.Sprint("restore failed: ", err)
+			return m, nil
+		}
+
+		m.tv.remove(secret.Key)
+		m.status = fmt.Sprintf("restored %s", secret.Key)
+	case "P":
+		secret := m.tv.selected()
+		if secret == nil {
+			return m, nil
+		}
+
+		if _, err := m.client.PurgeSecret(ctx, &api.PurgeSecretRequest{Key: secret.Key}); err != nil {
+			m.status = fmt.Sprint("purge failed: ", err)
+			return m, nil
+		}
+
+		m.tv.remove(secret.Key)
+		m.status = fmt.Sprintf("purged %s", secret.Key)
+	}
+
+	return m, nil
+}
+
+// updateSessionsPane revokes sessions of the user, revoking the current one logs the user out
+func (m model) updateSessionsPane(msg tea.Msg) (tea.Model, tea.Cmd) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "token", m.token)
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "s":
+		m.ss.active = false
+	case "up", "k":
+		m.ss.move(-1)
+	case "down", "j":
+		m.ss.move(1)
+	case "x":
+		session := m.ss.selected()
+		if session == nil {
+			return m, nil
+		}
+
+		if _, err := m.client.RevokeSession(ctx, &api.RevokeSessionRequest{Id: session.Id}); err != nil {
+			m.status = fmt.Sprint("revoke failed: ", err)
+			return m, nil
+		}
+
+		if session.Current {
+			m.ss = sessionsView{}
+			m.resetSession("this session was revoked")
+			return m, nil
+		}
+
+		m.ss.remove(session.Id)
+		m.status = fmt.Sprintf("revoked session of %s", session.Device)
+	}
+
+	return m, nil
+}
+
+// updateConflictsPane resolves conflicts of the changes made offline
+func (m model) updateConflictsPane(msg tea.Msg) (tea.Model, tea.Cmd) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "token", m.token)
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	resolutions := map[string]client.Resolution{
+		"m": client.KeepMine,
+		"t": client.KeepTheirs,
+		"b": client.KeepBoth,
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "c":
+		m.cv.active = false
+	case "up", "k":
+		m.cv.move(-1)
+	case "down", "j":
+		m.cv.move(1)
+	case "m", "t", "b":
+		change := m.cv.selected()
+		replica := m.pp.replica()
+		if change == nil || replica == nil {
+			return m, nil
+		}
+
+		if err := replica.Resolve(ctx, m.client, change.Key, resolutions[keyMsg.String()]); err != nil {
+			m.status = fmt.Sprint("resolve failed: ", err)
+			return m, nil
+		}
+
+		m.cv.remove(change.Key)
+		m.status = fmt.Sprintf("resolved conflict of %s", change.Key)
+	}
+
+	return m, nil
+}
+
+// refreshOffline lists secrets from the replica after the change was queued offline,
+// online they are updated by the stream
+func (m *model) refreshOffline(ctx context.Context) bool {
+	replica := m.pp.replica()
+	if replica == nil {
+		return false
+	}
+
+	if offline, _ := replica.Status(); !offline {
+		return false
+	}
+
+	resp, err := m.client.ListSecretsMeta(ctx, &emptypb.Empty{})
+	if err != nil {
+		return true
+	}
+
+	var secrets []list.Item
+	for _, sm := range resp.SecretsMeta {
+		secrets = append(secrets, &SecretItem{sm})
+	}
+
+	m.sp.SetItems(secrets)
+
+	return true
+}
+
+func (m model) updateDeleteConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	pending := m.pendingDelete
+	m.pendingDelete = nil
+	key := pending.GetKey()
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "y", "Y":
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "token", m.token)
+		if _, err := m.client.DeleteSecret(ctx, &api.DeleteSecretRequest{Key: key, Revision: pending.GetRevision()}); err != nil {
+			m.status = fmt.Sprint("delete failed: ", err)
+			return m, nil
+		}
+
+		m.sv.Secret = nil
+		m.status = fmt.Sprintf("moved %s to trash, press t to open it", key)
+
+		if m.refreshOffline(ctx) {
+			m.status = fmt.Sprintf("%s will be moved to trash when the vault is reachable", key)
+		}
+	default:
+		m.status = ""
+	}
+
+	return m, nil
+}
+
 func (m model) updateSecretsPage(msg tea.Msg) (tea.Model, tea.Cmd) {
 	ctx := context.Background()
 
+	if m.up.active {
+		return m.updateUploadPrompt(msg)
+	}
+
+	if m.hv.active {
+		return m.updateHistoryPane(msg)
+	}
+
+	if m.tv.active {
+		return m.updateTrashPane(msg)
+	}
+
+	if m.ss.active {
+		return m.updateSessionsPane(msg)
+	}
+
+	if m.cv.active {
+		return m.updateConflictsPane(msg)
+	}
+
+	if m.ap.active {
+		return m.updateAccountPrompt(msg)
+	}
+
+	if m.pendingDelete != nil {
+		return m.updateDeleteConfirm(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 
 		s := msg.String()
 		switch s {
+		case "h":
+			item, ok := m.sp.SelectedItem().(*SecretItem)
+			if !ok {
+				return m, nil
+			}
+
+			ctx = metadata.AppendToOutgoingContext(ctx, "token", m.token)
+			resp, err := m.client.ListSecretVersions(ctx, &api.ListSecretVersionsRequest{
+				Key: item.SecretMeta.Key,
+			})
+			if err != nil {
+				m.status = fmt.Sprint("error listing versions: ", err)
+				return m, nil
+			}
+
+			m.hv = historyView{
+				meta:     item.SecretMeta,
+				versions: resp.Versions,
+				active:   true,
+			}
+			m.sv.Secret = nil
+			m.status = ""
+			return m, nil
+		case "u":
+			m.up.active = true
+			m.status = ""
+			return m, m.up.input.Focus()
+		case "d":
+			item, ok := m.sp.SelectedItem().(*SecretItem)
+			if !ok || item.SecretMeta.Type != api.SecretType_TYPE_FILE {
+				return m, nil
+			}
+
+			ctx = metadata.AppendToOutgoingContext(ctx, "token", m.token)
+			path, err := client.DownloadFileTo(ctx, m.client, m.cipher, item.SecretMeta.Key, 0, ".")
+			if err != nil {
+				m.status = fmt.Sprint("download failed: ", err)
+				return m, nil
+			}
+
+			m.status = fmt.Sprint("downloaded to ", path)
+			return m, nil
 		case "ctrl+c":
 			return m, tea.Quit
 		case "ctrl+l":
 			m.isLoggedIn = !m.isLoggedIn
+		case "L":
+			ctx = metadata.AppendToOutgoingContext(ctx, "token", m.token)
+			if _, err := m.client.Logout(ctx, &emptypb.Empty{}); err != nil {
+				m.status = fmt.Sprint("logout failed: ", err)
+				return m, nil
+			}
+
+			m.resetSession("")
+			return m, nil
+		case "C":
+			m.ap = newAccountPrompt(actionChangePassword)
+			m.sv.Secret = nil
+			m.status = ""
+			return m, nil
+		case "D":
+			m.ap = newAccountPrompt(actionDeleteAccount)
+			m.sv.Secret = nil
+			m.status = ""
+			return m, nil
 		case "r":
-			item := m.sp.Items()[m.sp.GlobalIndex()].(*SecretItem)
+			item, ok := m.sp.SelectedItem().(*SecretItem)
+			if !ok {
+				return m, nil
+			}
 
+			m.pendingDelete = item.SecretMeta
+			m.status = fmt.Sprintf("move %s to trash? (y/n)", item.SecretMeta.Key)
+			return m, nil
+		case "t":
 			ctx = metadata.AppendToOutgoingContext(ctx, "token", m.token)
-			_, _ = m.client.DeleteSecret(ctx, &api.DeleteSecretRequest{
-				Key: item.SecretMeta.Key,
-			})
+			resp, err := m.client.ListTrash(ctx, &emptypb.Empty{})
+			if err != nil {
+				m.status = fmt.Sprint("error listing trash: ", err)
+				return m, nil
+			}
+
+			m.tv = trashView{
+				secrets: resp.SecretsMeta,
+				active:  true,
+			}
+			m.status = ""
+			return m, nil
+		case "s":
+			ctx = metadata.AppendToOutgoingContext(ctx, "token", m.token)
+			resp, err := m.client.ListSessions(ctx, &emptypb.Empty{})
+			if err != nil {
+				m.status = fmt.Sprint("error listing sessions: ", err)
+				return m, nil
+			}
+
+			m.ss = sessionsView{
+				sessions: resp.Sessions,
+				active:   true,
+			}
+			m.sv.Secret = nil
+			m.status = ""
+			return m, nil
+		case "c":
+			replica := m.pp.replica()
+			if replica == nil {
+				return m, nil
+			}
+
+			changes, err := replica.Changes()
+			if err != nil {
+				m.status = fmt.Sprint("error listing offline changes: ", err)
+				return m, nil
+			}
+
+			m.cv = newConflictsView(changes)
+			m.sv.Secret = nil
+			m.status = ""
+			return m, nil
 		case "esc":
 			m.sv.Secret = nil
 			return m, nil
@@ -201,7 +955,7 @@ func (m model) updateSecretsPage(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			var addRequest api.AddSecretRequest
 
-			switch rand.Intn(2) {
+			switch rand.Intn(3) {
 			case 0:
 				addRequest = api.AddSecretRequest{
 					Key: []byte(fmt.Sprintf("%s-%s", "text", uuid.NewString())),
@@ -225,25 +979,49 @@ func (m model) updateSecretsPage(msg tea.Msg) (tea.Model, tea.Cmd) {
 						},
 					},
 				}
+			case 2:
+				addRequest = api.AddSecretRequest{
+					Key:        []byte(fmt.Sprintf("%s-%s", "card", uuid.NewString())),
+					SecretType: api.SecretType_TYPE_CARD,
+					Secret: &api.Secret{
+						Secret: &api.Secret_Card{
+							Card: &api.Card{
+								Number:      strconv.Itoa(gofakeit.CreditCardNumberLuhn()),
+								Holder:      strings.ToUpper(gofakeit.Name()),
+								ExpiryMonth: uint32(gofakeit.Number(1, 12)),
+								ExpiryYear:  uint32(time.Now().Year() + gofakeit.Number(1, 5)),
+								Cvv:         gofakeit.CreditCardCvv(),
+							},
+						},
+					},
+				}
 			}
 
-			_, _ = m.client.AddSecret(ctx, &addRequest)
+			if err := client.AddSecret(ctx, m.client, m.cipher, &addRequest); err != nil {
+				m.status = fmt.Sprint("error adding secret: ", err)
+				return m, nil
+			}
+
+			if m.refreshOffline(ctx) {
+				m.status = fmt.Sprintf("%s will be added when the vault is reachable", addRequest.Key)
+			}
 
 		case "enter":
 			item := m.sp.Items()[m.sp.GlobalIndex()].(*SecretItem)
 
 			ctx = metadata.AppendToOutgoingContext(ctx, "token", m.token)
-			getSecretResponse, err := m.client.GetSecret(ctx, &api.GetSecretRequest{
-				Key: item.SecretMeta.Key,
-			})
+			getSecretResponse, err := client.GetSecret(ctx, m.client, m.cipher, item.SecretMeta.Key)
 			if err != nil {
 				m.sv.Secret = nil
+				m.status = fmt.Sprint("error getting secret: ", err)
 				return m, nil
 			}
 
-			m.sv.Update(getSecretResponse.Secret)
-			m.sv.Secret = getSecretResponse.Secret
+			svCmd := m.sv.Update(getSecretResponse.Secret)
 
+			var cmd tea.Cmd
+			m.sp, cmd = m.sp.Update(msg)
+			return m, tea.Batch(cmd, svCmd)
 		}
 
 	}
@@ -262,6 +1040,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case secretsUpdate:
 		m.sp.SetItems(mtype.secrets)
 		return m, nil
+	case connectivityUpdate:
+		return m, nil
+	case otpTick:
+		return m, m.sv.HandleTick(mtype)
 	}
 
 	if !m.isLoggedIn {
@@ -281,14 +1063,51 @@ func (m model) View() string {
 
 		var rend strings.Builder
 
-		rend.WriteString(headerStyle.Render("Authorization"))
+		if m.lp.challenge != "" {
+			rend.WriteString(headerStyle.Render("Two-factor authentication"))
+
+			rend.WriteString(fmt.Sprintf("\n%s", m.lp.code.View()))
+			rend.WriteString("\n\nesc: back to login")
+
+			return loginStyle.Render(rend.String())
+		}
+
+		if m.lp.unlock != nil {
+			rend.WriteString(headerStyle.Render("Unlock"))
+		} else if m.lp.register {
+			rend.WriteString(headerStyle.Render("Registration"))
+		} else {
+			rend.WriteString(headerStyle.Render("Authorization"))
+		}
 
 		if m.lp.lastErr != nil {
 			rend.WriteString(fmt.Sprint("\nERROR:", m.lp.lastErr, "\n\n"))
+		} else if m.lp.notice != "" {
+			rend.WriteString(fmt.Sprint("\n", m.lp.notice, "\n\n"))
 		}
 
-		for _, i := range m.lp.inputs {
-			rend.WriteString(fmt.Sprintf("\n%s", i.View()))
+		rend.WriteString(fmt.Sprintf("\n%s\n", m.pp.View()))
+
+		if m.lp.unlock != nil {
+			rend.WriteString(fmt.Sprintf("\nLogged in as %s\n", m.lp.unlock.Username))
+			rend.WriteString(fmt.Sprintf("\n%s", m.lp.inputs[2].View()))
+		} else {
+			for _, i := range m.lp.inputs {
+				rend.WriteString(fmt.Sprintf("\n%s", i.View()))
+			}
+		}
+
+		switch {
+		case m.lp.unlock != nil:
+			rend.WriteString("\n\nctrl+l: log in as another user")
+		case m.lp.register:
+			rend.WriteString("\n\nctrl+r: back to login")
+		default:
+			rend.WriteString("\n\nctrl+r: create account")
+		}
+
+		if len(m.pp.profiles) > 1 {
+			rend.WriteString(", ctrl+p: switch profile")
 		}
 
 		return loginStyle.Render(rend.String())
@@ -301,13 +1120,57 @@ func (m model) View() string {
 		if m.username == "" {
 			m.sp.Title += "unauthorized"
 		} else {
-			m.sp.Title += "user: " + string(m.username)
+			m.sp.Title += "user: " + string(m.username) + "@" + m.pp.selected().Name
 		}
 
-		if m.sv.Secret == nil {
-			s += m.sp.View()
-		} else {
-			s += lipgloss.JoinHorizontal(lipgloss.Top, m.sp.View(), m.sv.View())
+		if replica := m.pp.replica(); replica != nil {
+			if offline, syncedAt := replica.Status(); offline {
+				m.sp.Title += " [offline"
+				if !syncedAt.IsZero() {
+					m.sp.Title += ", last synced at " + syncedAt.Local().Format(time.DateTime)
+				}
+				m.sp.Title += "]"
+			}
+
+			if changes, conflicts := replica.Pending(); conflicts > 0 {
+				m.sp.Title += fmt.Sprintf(" [%d conflicts, press c to resolve]", conflicts)
+			} else if changes > 0 {
+				m.sp.Title += fmt.Sprintf(" [%d unsynced changes]", changes)
+			}
+		}
+
+		panes := []string{m.sp.View()}
+
+		if m.hv.active {
+			panes = append(panes, m.hv.View())
+		}
+
+		if m.tv.active {
+			panes = append(panes, m.tv.View())
+		}
+
+		if m.ss.active {
+			panes = append(panes, m.ss.View())
+		}
+
+		if m.cv.active {
+			panes = append(panes, m.cv.View())
+		}
+
+		if m.ap.active {
+			panes = append(panes, m.ap.View())
+		}
+
+		if m.sv.Secret != nil {
+			panes = append(panes, m.sv.View())
+		}
+
+		s += lipgloss.JoinHorizontal(lipgloss.Top, panes...)
+
+		if m.up.active {
+			s += "\n" + m.up.input.View()
+		} else if m.status != "" {
+			s += "\n" + m.status
 		}
 
 		return docStyle.Render(s)
@@ -323,54 +1186,159 @@ type secretsUpdate struct {
 	secrets []list.Item
 }
 
+// connectivityUpdate redraws the offline indicator when the stream is lost and the pending changes after replay
+type connectivityUpdate struct{}
+
+// streamRetryInterval is how often the stream is reopened while the vault is unreachable
+const streamRetryInterval = time.Second * 5
+
+// streamLogin is sent on login, updates are streamed from the vault of the profile the user logged in to
+type streamLogin struct {
+	client  api.NedoVaultClient
+	replica *client.Replica
+	token   string
+}
+
+// CheckUpdates streams secrets of the logged in user, the stream is restarted on every login
 func (u *UI) CheckUpdates(ctx context.Context, wg *sync.WaitGroup, p *tea.Program) {
 	//defer wg.Done()
 
-	// TODO: add dispatch goroutine for controlling checkupdates goroutines
-	token := <-u.m.tokench
+	cancel := func() {}
+
+	for {
+		select {
+		case <-ctx.Done():
+			cancel()
+			return
+		case login := <-u.m.tokench:
+			cancel()
 
-	ctx = metadata.AppendToOutgoingContext(ctx, "token", token)
+			var streamCtx context.Context
+			streamCtx, cancel = context.WithCancel(ctx)
 
-	stream, err := u.m.client.ListSecretsMetaStream(ctx, &emptypb.Empty{})
-	if err != nil {
-		panic(err)
+			go u.streamUpdates(streamCtx, login, p)
+		}
+	}
+}
+
+// streamUpdates keeps the stream open while the user is logged in, it is reopened when the vault becomes reachable again
+func (u *UI) streamUpdates(ctx context.Context, login streamLogin, p *tea.Program) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "token", login.token)
+
+	// reopened stream catches up from the last received change
+	synced := &syncedSecrets{
+		metas: make(map[string]*api.SecretMeta),
 	}
 
-	// TOOD: add gracefull shutdown
 	for {
+		err := u.receiveUpdates(ctx, login, synced, p)
+
+		// stream of the previous login is canceled on the next one
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil && status.Code(err) != codes.Unavailable {
+			logger.Log.Error(
+				"error receiving metadata from server",
+				zap.Error(err),
+			)
+			return
+		}
+
+		// offline indicator is redrawn
+		p.Send(connectivityUpdate{})
 
 		select {
 		case <-ctx.Done():
 			return
-		default:
+		case <-time.After(streamRetryInterval):
+		}
+	}
+}
 
-			resp, err := stream.Recv()
-			if err == io.EOF {
-				return
-			}
+// syncedSecrets is metadata of the secrets received from the vault, the sequence number of the last change
+// and the epoch of the account it belongs to
+type syncedSecrets struct {
+	seq   uint64
+	epoch string
+	metas map[string]*api.SecretMeta
+}
 
-			if err != nil {
+// apply applies changes of the response and returns the secrets sorted by key
+func (s *syncedSecrets) apply(resp *api.SyncResponse) []list.Item {
+	if resp.GetReset_() {
+		clear(s.metas)
+	}
+
+	for _, change := range resp.GetChanges() {
+		if change.GetKind() == api.ChangeKind_CHANGE_KIND_DELETE {
+			delete(s.metas, string(change.GetKey()))
+			continue
+		}
+
+		s.metas[string(change.GetKey())] = change.GetSecretMeta()
+	}
+
+	s.seq, s.epoch = resp.GetSeq(), resp.GetEpoch()
+
+	secrets := make([]list.Item, 0, len(s.metas))
+	for _, key := range slices.Sorted(maps.Keys(s.metas)) {
+		secrets = append(secrets, &SecretItem{s.metas[key]})
+	}
+
+	return secrets
+}
+
+// receiveUpdates streams changes of the secrets, changes made offline are replayed once the vault is reached
+func (u *UI) receiveUpdates(ctx context.Context, login streamLogin, synced *syncedSecrets, p *tea.Program) error {
+	stream, err := login.client.SyncStream(ctx, &api.SyncRequest{
+		SinceSeq: synced.seq,
+		Epoch:    synced.epoch,
+	})
+	if err != nil {
+		return err
+	}
+
+	replayed := false
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !replayed && login.replica != nil {
+			replayed = true
+
+			if err = login.replica.Replay(ctx, login.client); err != nil && status.Code(err) != codes.Unavailable {
 				logger.Log.Error(
-					"error receiving metadata from server",
+					"error replaying offline changes",
 					zap.Error(err),
 				)
 			}
 
-			var secrets []list.Item
-
-			for _, sm := range resp.SecretsMeta {
-				secrets = append(secrets, &SecretItem{sm})
-			}
-
-			p.Send(secretsUpdate{
-				secrets: secrets,
-			})
+			p.Send(connectivityUpdate{})
 		}
 
+		p.Send(secretsUpdate{
+			secrets: synced.apply(resp),
+		})
 	}
 }
 
-func NewUI(client api.NedoVaultClient) *UI {
+// NewUI creates the UI logging in to the vault of the current profile, other profiles are dialed when picked
+func NewUI(profiles []*client.Profile, current *client.Profile, dial DialFunc) (*UI, error) {
+
+	pp := newProfilePicker(profiles, current, dial)
+
+	vaultClient, err := pp.client()
+	if err != nil {
+		return nil, err
+	}
 
 	var items []list.Item
 
@@ -384,6 +1352,7 @@ func NewUI(client api.NedoVaultClient) *UI {
 	ti.CharLimit = 100
 	ti.Width = 100
 	ti.Prompt = "Username: "
+	ti.SetValue(current.Username)
 	loginInputs = append(loginInputs, ti)
 
 	ti = textinput.New()
@@ -396,20 +1365,51 @@ func NewUI(client api.NedoVaultClient) *UI {
 	ti.Prompt = "Password: "
 	loginInputs = append(loginInputs, ti)
 
+	ti = textinput.New()
+	ti.Placeholder = "used only for encryption, never sent to server"
+	ti.EchoMode = textinput.EchoPassword
+	ti.CharLimit = 100
+	ti.TextStyle = noStyle
+	ti.PromptStyle = noStyle
+	ti.Width = 100
+	ti.Prompt = "Master password: "
+	loginInputs = append(loginInputs, ti)
+
+	code := textinput.New()
+	code.Placeholder = "code from the app or recovery code"
+	code.CharLimit = 32
+	code.Width = 100
+	code.TextStyle = focusedStyle
+	code.PromptStyle = focusedStyle
+	code.Prompt = "Code: "
+
 	sp := list.New(items, list.NewDefaultDelegate(), 0, 0)
 	//sp.SetSize(docStyle.GetFrameSize())
 
+	fp := textinput.New()
+	fp.Placeholder = "/path/to/file"
+	fp.CharLimit = 4096
+	fp.Width = 100
+	fp.Prompt = "Upload file: "
+
+	lp := loginPage{inputs: loginInputs, current: 0, code: code}
+
+	// saved session is unlocked by the master password, so the user does not log in on every start
+	lp.setUnlock(pp.credentials())
+
 	return &UI{
 		m: model{
 			sp:         sp,
 			sv:         NewSecretView(),
-			lp:         loginPage{inputs: loginInputs, current: 0},
+			lp:         lp,
+			up:         uploadPrompt{input: fp},
 			mx:         &sync.Mutex{},
 			isLoggedIn: false,
-			client:     client,
-			tokench:    make(chan string, 1),
+			client:     vaultClient,
+			pp:         pp,
+			tokench:    make(chan streamLogin, 1),
 		},
-	}
+	}, nil
 }
 
 func (u *UI) Run() {
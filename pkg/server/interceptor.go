@@ -14,10 +14,19 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// publicMethods are called without token
+var publicMethods = map[string]struct{}{
+	api.NedoVault_Authorize_FullMethodName:    {},
+	api.NedoVault_Register_FullMethodName:     {},
+	api.NedoVault_Login_FullMethodName:        {},
+	api.NedoVault_RefreshToken_FullMethodName: {},
+	api.NedoVault_VerifyTOTP_FullMethodName:   {},
+}
+
 func NewAuthUnaryInterceptor(a Auth) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 
-		if info.FullMethod == api.NedoVault_Authorize_FullMethodName {
+		if _, ok := publicMethods[info.FullMethod]; ok {
 			return handler(ctx, req)
 		}
 
@@ -38,6 +47,10 @@ func NewAuthUnaryInterceptor(a Auth) grpc.UnaryServerInterceptor {
 				return nil, status.Errorf(codes.Unauthenticated, "request with invalid token")
 			}
 
+			if errors.Is(err, auth.ErrRevokedToken) {
+				return nil, status.Errorf(codes.Unauthenticated, "request with revoked token")
+			}
+
 			logger.Log.Error(
 				"error parsing client token",
 				zap.Error(err),
@@ -53,6 +66,7 @@ func NewAuthUnaryInterceptor(a Auth) grpc.UnaryServerInterceptor {
 		)
 
 		ctx = context.WithValue(ctx, auth.Username("username"), []byte(claims.Username))
+		ctx = context.WithValue(ctx, auth.TokenID("token_id"), claims.ID)
 
 		return handler(ctx, req)
 	}
@@ -62,7 +76,7 @@ func NewAuthStreamInterceptor(a Auth) grpc.StreamServerInterceptor {
 
 		ctx := ss.Context()
 
-		if info.FullMethod == api.NedoVault_Authorize_FullMethodName {
+		if _, ok := publicMethods[info.FullMethod]; ok {
 			return handler(srv, ss)
 		}
 
@@ -83,6 +97,10 @@ func NewAuthStreamInterceptor(a Auth) grpc.StreamServerInterceptor {
 				return status.Errorf(codes.Unauthenticated, "request with invalid token")
 			}
 
+			if errors.Is(err, auth.ErrRevokedToken) {
+				return status.Errorf(codes.Unauthenticated, "request with revoked token")
+			}
+
 			logger.Log.Error(
 				"error parsing client token",
 				zap.Error(err),
@@ -104,8 +122,12 @@ func NewAuthStreamInterceptor(a Auth) grpc.StreamServerInterceptor {
 		}
 
 		return handler(srv, &grpc_middleware.WrappedServerStream{
-			ServerStream:   ss,
-			WrappedContext: context.WithValue(ctx, auth.Username("username"), []byte(claims.Username)),
+			ServerStream: ss,
+			WrappedContext: context.WithValue(
+				context.WithValue(ctx, auth.Username("username"), []byte(claims.Username)),
+				auth.TokenID("token_id"),
+				claims.ID,
+			),
 		})
 	}
 }
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"fmt"
+	"github.com/renatus-cartesius/metricserv/pkg/logger"
+	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// JWKSPath is where the key set is published, as other services expect it
+const JWKSPath = "/.well-known/jwks.json"
+
+// KeySource publishes public keys verifying the vault tokens
+type KeySource interface {
+	JWKS() ([]byte, error)
+}
+
+// NewJWKSHandler serves public keys of the vault tokens in JSON Web Key Set format
+func NewJWKSHandler(keys KeySource) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET "+JWKSPath, func(w http.ResponseWriter, r *http.Request) {
+		jwks, err := keys.JWKS()
+		if err != nil {
+			logger.Log.Error(
+				"error encoding key set",
+				zap.Error(err),
+			)
+			http.Error(w, "error encoding key set", http.StatusInternalServerError)
+			return
+		}
+
+		// the next key is published a cache lifetime before it signs and retired keys are published
+		// until their tokens expire, so verifiers caching the set always know the signing key
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int(auth.JWKSCacheTTL.Seconds())))
+		w.Write(jwks)
+	})
+
+	return mux
+}
@@ -8,17 +8,23 @@ import (
 	"github.com/renatus-cartesius/metricserv/pkg/logger"
 	"github.com/renatus-cartesius/nedovault/api"
 	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"github.com/renatus-cartesius/nedovault/internal/secrets"
+	"github.com/renatus-cartesius/nedovault/pkg/storage"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"math"
+	"strconv"
 	"sync"
 	"time"
 )
 
 var (
 	ErrMetadataParseFail = status.Errorf(codes.Internal, "failed to parse request metadata")
+	ErrPlaintextSecret   = status.Errorf(codes.InvalidArgument, "secret must be encrypted by the client")
 )
 
 type Storage interface {
@@ -26,10 +32,34 @@ type Storage interface {
 	DeleteSecret(ctx context.Context, username []byte, in *api.DeleteSecretRequest) error
 	GetSecret(ctx context.Context, username, key []byte) (*api.Secret, *api.SecretMeta, error)
 	ListSecretsMeta(ctx context.Context, username []byte) ([]*api.SecretMeta, error)
+	UpdateSecret(ctx context.Context, username []byte, in *api.UpdateSecretRequest) (*api.SecretMeta, error)
+	UploadSecret(ctx context.Context, username []byte, header *api.UploadSecretHeader, next storage.ChunkFunc) error
+	ReadSecretChunks(ctx context.Context, username []byte, meta *api.SecretMeta, send func(chunk []byte) error) error
+	ListSecretVersions(ctx context.Context, username, key []byte) ([]*api.SecretMeta, error)
+	GetSecretVersion(ctx context.Context, username, key []byte, revision uint64) (*api.Secret, *api.SecretMeta, error)
+	RestoreSecretVersion(ctx context.Context, username []byte, in *api.RestoreSecretVersionRequest) (*api.SecretMeta, error)
+	ListTrash(ctx context.Context, username []byte) ([]*api.SecretMeta, error)
+	RestoreSecret(ctx context.Context, username, key []byte) (*api.SecretMeta, error)
+	PurgeSecret(ctx context.Context, username, key []byte) error
+	SyncSecrets(ctx context.Context, username []byte, since uint64, epoch string) (*api.SyncResponse, error)
 }
 
 type Auth interface {
-	Authorize(ctx context.Context, in *api.AuthRequest) (string, error)
+	Register(ctx context.Context, in *api.AuthRequest) (*auth.TokenPair, []byte, error)
+	Login(ctx context.Context, in *api.AuthRequest) (*auth.LoginResult, error)
+	VerifyTOTP(ctx context.Context, challenge []byte, code string) (*auth.LoginResult, error)
+	EnrollTOTP(ctx context.Context, username, password []byte) (*api.EnrollTOTPResponse, error)
+	ConfirmTOTP(ctx context.Context, username []byte, code string) error
+	DisableTOTP(ctx context.Context, username, password []byte, code string) error
+	ChangePassword(ctx context.Context, username, oldPassword, newPassword []byte) error
+	DeleteAccount(ctx context.Context, username, password []byte, code string) error
+	Refresh(ctx context.Context, token []byte) (*auth.TokenPair, error)
+	Logout(ctx context.Context, username []byte, tokenID string) error
+	LogoutAll(ctx context.Context, username []byte) error
+	ListSessions(ctx context.Context, username []byte, tokenID string) ([]*api.Session, error)
+	RevokeSession(ctx context.Context, username []byte, id string) error
+	KeyCheck(ctx context.Context, username []byte) ([]byte, error)
+	SetKeyCheck(ctx context.Context, username, keyCheck []byte) error
 	ParseToken(ctx context.Context, token []byte) (*auth.Claims, error)
 }
 
@@ -54,11 +84,15 @@ func (s *Server) DeleteSecret(ctx context.Context, in *api.DeleteSecretRequest)
 	)
 
 	if err := s.storage.DeleteSecret(ctx, username, in); err != nil {
+		if st := storageStatus(err); st != nil {
+			return &emptypb.Empty{}, st
+		}
+
 		logger.Log.Error(
 			"error when deleting secret",
 			zap.Error(err),
 		)
-		return &emptypb.Empty{}, status.Errorf(codes.Internal, "pair %s already exists", in.Key)
+		return &emptypb.Empty{}, status.Errorf(codes.Internal, "error deleting secret")
 	}
 
 	s.notifyMetadataStreams(username)
@@ -74,12 +108,50 @@ func NewServer(storage Storage, auth Auth) *Server {
 	}
 }
 
+// Authorize is kept for older clients and works as Login
 func (s *Server) Authorize(ctx context.Context, in *api.AuthRequest) (*api.AuthResponse, error) {
-	token, err := s.auth.Authorize(ctx, in)
+	return s.Login(ctx, in)
+}
+
+func (s *Server) Register(ctx context.Context, in *api.AuthRequest) (*api.AuthResponse, error) {
+	pair, salt, err := s.auth.Register(ctx, in)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrUserExists):
+			return nil, status.Errorf(codes.AlreadyExists, "user already exists")
+		case errors.Is(err, auth.ErrWeakPassword), errors.Is(err, auth.ErrEmptyUsername), errors.Is(err, auth.ErrInvalidUsername):
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+
+		logger.Log.Error(
+			"error when registering",
+			zap.Error(err),
+		)
+
+		return nil, status.Errorf(codes.Internal, "something went wrong when registering")
+	}
+
+	return &api.AuthResponse{
+		Token:        pair.Access,
+		Salt:         salt,
+		RefreshToken: pair.Refresh,
+	}, nil
+}
+
+func (s *Server) Login(ctx context.Context, in *api.AuthRequest) (*api.AuthResponse, error) {
+	result, err := s.auth.Login(ctx, in)
 	if err != nil {
+		if st := lockedOutStatus(ctx, err); st != nil {
+			return nil, st
+		}
 
-		if errors.Is(err, auth.ErrInvalidCredentials) {
-			return nil, status.Errorf(codes.Unauthenticated, "error authorizing")
+		switch {
+		case errors.Is(err, auth.ErrEmptyUsername), errors.Is(err, auth.ErrInvalidUsername):
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		case errors.Is(err, auth.ErrUserNotFound):
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			return nil, status.Errorf(codes.Unauthenticated, "invalid username or password")
 		}
 
 		logger.Log.Error(
@@ -90,8 +162,15 @@ func (s *Server) Authorize(ctx context.Context, in *api.AuthRequest) (*api.AuthR
 		return nil, status.Errorf(codes.Internal, "something went wrong when authorizing")
 	}
 
-	response := &api.AuthResponse{
-		Token: token,
+	if result.Challenge != "" {
+		logger.Log.Info(
+			"user passed password, waiting for second factor",
+			zap.String("username", string(in.Username)),
+		)
+
+		return &api.AuthResponse{
+			Challenge: result.Challenge,
+		}, nil
 	}
 
 	logger.Log.Info(
@@ -99,7 +178,334 @@ func (s *Server) Authorize(ctx context.Context, in *api.AuthRequest) (*api.AuthR
 		zap.String("username", string(in.Username)),
 	)
 
-	return response, nil
+	return loginResponse(result), nil
+}
+
+func (s *Server) VerifyTOTP(ctx context.Context, in *api.VerifyTOTPRequest) (*api.AuthResponse, error) {
+	result, err := s.auth.VerifyTOTP(ctx, []byte(in.GetChallenge()), in.GetCode())
+	if err != nil {
+		if st := lockedOutStatus(ctx, err); st != nil {
+			return nil, st
+		}
+
+		switch {
+		case errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrRevokedToken):
+			return nil, status.Errorf(codes.Unauthenticated, "invalid or expired challenge, log in again")
+		case errors.Is(err, auth.ErrInvalidCode):
+			return nil, status.Errorf(codes.Unauthenticated, "invalid code, log in again")
+		}
+
+		logger.Log.Error(
+			"error verifying second factor",
+			zap.Error(err),
+		)
+
+		return nil, status.Errorf(codes.Internal, "something went wrong when authorizing")
+	}
+
+	return loginResponse(result), nil
+}
+
+func (s *Server) EnrollTOTP(ctx context.Context, in *api.EnrollTOTPRequest) (*api.EnrollTOTPResponse, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	enrollment, err := s.auth.EnrollTOTP(ctx, username, in.GetPassword())
+	if err != nil {
+		if st := lockedOutStatus(ctx, err); st != nil {
+			return nil, st
+		}
+
+		switch {
+		case errors.Is(err, auth.ErrTOTPEnabled):
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			return nil, status.Errorf(codes.PermissionDenied, "invalid password")
+		}
+
+		logger.Log.Error(
+			"error enrolling second factor",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+
+		return nil, status.Errorf(codes.Internal, "error enrolling second factor")
+	}
+
+	return enrollment, nil
+}
+
+func (s *Server) ConfirmTOTP(ctx context.Context, in *api.ConfirmTOTPRequest) (*emptypb.Empty, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	if err := s.auth.ConfirmTOTP(ctx, username, in.GetCode()); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrTOTPNotEnrolled), errors.Is(err, auth.ErrTOTPEnabled):
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		case errors.Is(err, auth.ErrInvalidCode):
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+
+		logger.Log.Error(
+			"error confirming second factor",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+
+		return nil, status.Errorf(codes.Internal, "error confirming second factor")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) DisableTOTP(ctx context.Context, in *api.DisableTOTPRequest) (*emptypb.Empty, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	if err := s.auth.DisableTOTP(ctx, username, in.GetPassword(), in.GetCode()); err != nil {
+		if st := lockedOutStatus(ctx, err); st != nil {
+			return nil, st
+		}
+
+		switch {
+		case errors.Is(err, auth.ErrTOTPNotEnrolled), errors.Is(err, auth.ErrTOTPEnabled):
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		case errors.Is(err, auth.ErrInvalidCredentials), errors.Is(err, auth.ErrInvalidCode):
+			return nil, status.Errorf(codes.PermissionDenied, "invalid password or code")
+		}
+
+		logger.Log.Error(
+			"error disabling second factor",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+
+		return nil, status.Errorf(codes.Internal, "error disabling second factor")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) ChangePassword(ctx context.Context, in *api.ChangePasswordRequest) (*emptypb.Empty, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	if err := s.auth.ChangePassword(ctx, username, in.GetOldPassword(), in.GetNewPassword()); err != nil {
+		if st := lockedOutStatus(ctx, err); st != nil {
+			return nil, st
+		}
+
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			return nil, status.Errorf(codes.PermissionDenied, "invalid password")
+		case errors.Is(err, auth.ErrWeakPassword):
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+
+		logger.Log.Error(
+			"error changing password",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+
+		return nil, status.Errorf(codes.Internal, "error changing password")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) DeleteAccount(ctx context.Context, in *api.DeleteAccountRequest) (*emptypb.Empty, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	if err := s.auth.DeleteAccount(ctx, username, in.GetPassword(), in.GetCode()); err != nil {
+		if st := lockedOutStatus(ctx, err); st != nil {
+			return nil, st
+		}
+
+		if errors.Is(err, auth.ErrInvalidCredentials) || errors.Is(err, auth.ErrInvalidCode) {
+			return nil, status.Errorf(codes.PermissionDenied, "invalid password or code")
+		}
+
+		logger.Log.Error(
+			"error deleting account",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+
+		return nil, status.Errorf(codes.Internal, "error deleting account")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// lockedOutStatus returns ResourceExhausted status if the caller is locked out, retry-after header holds seconds to wait
+func lockedOutStatus(ctx context.Context, err error) error {
+	var locked *auth.LockedOutError
+	if !errors.As(err, &locked) {
+		return nil
+	}
+
+	retryAfter := int64(math.Ceil(locked.RetryAfter.Seconds()))
+
+	if err = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.FormatInt(retryAfter, 10))); err != nil {
+		logger.Log.Error(
+			"error setting retry-after header",
+			zap.Error(err),
+		)
+	}
+
+	return status.Errorf(codes.ResourceExhausted, "too many failed attempts, retry after %d seconds", retryAfter)
+}
+
+func loginResponse(result *auth.LoginResult) *api.AuthResponse {
+	return &api.AuthResponse{
+		Token:        result.Tokens.Access,
+		Salt:         result.Salt,
+		RefreshToken: result.Tokens.Refresh,
+	}
+}
+
+func (s *Server) RefreshToken(ctx context.Context, in *api.RefreshTokenRequest) (*api.AuthResponse, error) {
+	pair, err := s.auth.Refresh(ctx, []byte(in.GetRefreshToken()))
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidToken):
+			return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
+		case errors.Is(err, auth.ErrRevokedToken):
+			return nil, status.Errorf(codes.Unauthenticated, "revoked refresh token")
+		case errors.Is(err, auth.ErrTokenReused):
+			return nil, status.Errorf(codes.Unauthenticated, "reused refresh token, session is revoked")
+		}
+
+		logger.Log.Error(
+			"error refreshing token",
+			zap.Error(err),
+		)
+
+		return nil, status.Errorf(codes.Internal, "something went wrong when refreshing token")
+	}
+
+	return &api.AuthResponse{
+		Token:        pair.Access,
+		RefreshToken: pair.Refresh,
+	}, nil
+}
+
+func (s *Server) GetKeyCheck(ctx context.Context, e *emptypb.Empty) (*api.KeyCheck, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	keyCheck, err := s.auth.KeyCheck(ctx, username)
+	if err != nil {
+		logger.Log.Error(
+			"error getting key check",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error getting key check")
+	}
+
+	return &api.KeyCheck{
+		KeyCheck: keyCheck,
+	}, nil
+}
+
+func (s *Server) SetKeyCheck(ctx context.Context, in *api.KeyCheck) (*emptypb.Empty, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	if len(in.GetKeyCheck()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "key check must not be empty")
+	}
+
+	if err := s.auth.SetKeyCheck(ctx, username, in.GetKeyCheck()); err != nil {
+		if errors.Is(err, auth.ErrKeyCheckExists) {
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		}
+
+		logger.Log.Error(
+			"error setting key check",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error setting key check")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) Logout(ctx context.Context, e *emptypb.Empty) (*emptypb.Empty, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+	tokenID := ctx.Value(auth.TokenID("token_id")).(string)
+
+	if err := s.auth.Logout(ctx, username, tokenID); err != nil {
+		logger.Log.Error(
+			"error revoking token",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error logging out")
+	}
+
+	logger.Log.Info(
+		"user logged out",
+		zap.String("username", string(username)),
+	)
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) LogoutAll(ctx context.Context, e *emptypb.Empty) (*emptypb.Empty, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	if err := s.auth.LogoutAll(ctx, username); err != nil {
+		logger.Log.Error(
+			"error revoking tokens",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error logging out")
+	}
+
+	logger.Log.Info(
+		"user logged out from all sessions",
+		zap.String("username", string(username)),
+	)
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) ListSessions(ctx context.Context, e *emptypb.Empty) (*api.ListSessionsResponse, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+	tokenID := ctx.Value(auth.TokenID("token_id")).(string)
+
+	sessions, err := s.auth.ListSessions(ctx, username, tokenID)
+	if err != nil {
+		logger.Log.Error(
+			"error listing sessions",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error listing sessions")
+	}
+
+	return &api.ListSessionsResponse{
+		Sessions: sessions,
+	}, nil
+}
+
+func (s *Server) RevokeSession(ctx context.Context, in *api.RevokeSessionRequest) (*emptypb.Empty, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	if err := s.auth.RevokeSession(ctx, username, in.GetId()); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			return nil, status.Errorf(codes.NotFound, "no such session")
+		}
+
+		logger.Log.Error(
+			"error revoking session",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error revoking session")
+	}
+
+	return &emptypb.Empty{}, nil
 }
 
 func (s *Server) ListSecretsMetaStream(e *emptypb.Empty, g grpc.ServerStreamingServer[api.ListSecretsMetaResponse]) error {
@@ -109,11 +515,9 @@ func (s *Server) ListSecretsMetaStream(e *emptypb.Empty, g grpc.ServerStreamingS
 		username: username,
 	}
 
-	ch := make(chan any)
-	defer func() {
-		close(ch)
-		s.smap.Delete(sess)
-	}()
+	// channel is not closed, a notification may be sent after the stream is removed
+	ch := make(chan struct{}, 1)
+	defer s.smap.Delete(sess)
 	s.smap.Store(sess, ch)
 
 	ctx := g.Context()
@@ -158,6 +562,65 @@ func (s *Server) ListSecretsMetaStream(e *emptypb.Empty, g grpc.ServerStreamingS
 	}
 }
 
+func (s *Server) Sync(ctx context.Context, in *api.SyncRequest) (*api.SyncResponse, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	resp, err := s.storage.SyncSecrets(ctx, username, in.GetSinceSeq(), in.GetEpoch())
+	if err != nil {
+		logger.Log.Error(
+			"error syncing secrets",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error syncing secrets")
+	}
+
+	return resp, nil
+}
+
+// SyncStream sends changes after the requested sequence number and then every batch of changes once it is committed.
+// The first response is sent even if there are no changes, so the client knows the cursor
+func (s *Server) SyncStream(in *api.SyncRequest, g grpc.ServerStreamingServer[api.SyncResponse]) error {
+	username := g.Context().Value(auth.Username("username")).([]byte)
+
+	sess := &session{
+		username: username,
+	}
+
+	// registered before the first read, so changes committed meanwhile are not missed
+	ch := make(chan struct{}, 1)
+	defer s.smap.Delete(sess)
+	s.smap.Store(sess, ch)
+
+	ctx := g.Context()
+	since, epoch := in.GetSinceSeq(), in.GetEpoch()
+
+	for first := true; ; first = false {
+		resp, err := s.storage.SyncSecrets(ctx, username, since, epoch)
+		if err != nil {
+			logger.Log.Error(
+				"error syncing secrets",
+				zap.String("username", string(username)),
+				zap.Error(err),
+			)
+			return status.Errorf(codes.Internal, "error syncing secrets")
+		}
+
+		if first || len(resp.GetChanges()) > 0 {
+			if err = g.Send(resp); err != nil {
+				return err
+			}
+		}
+		since, epoch = resp.GetSeq(), resp.GetEpoch()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
 func (s *Server) GetSecret(ctx context.Context, request *api.GetSecretRequest) (*api.GetSecretResponse, error) {
 	username := ctx.Value(auth.Username("username")).([]byte)
 
@@ -170,7 +633,7 @@ func (s *Server) GetSecret(ctx context.Context, request *api.GetSecretRequest) (
 				zap.String("key", string(request.Key)),
 			)
 
-			return nil, status.Errorf(codes.Internal, "no such secret")
+			return nil, status.Errorf(codes.NotFound, "no such secret")
 		}
 
 		logger.Log.Error(
@@ -196,13 +659,25 @@ func (s *Server) AddSecret(ctx context.Context, in *api.AddSecretRequest) (*empt
 		zap.String("username", string(username)),
 	)
 
+	if in.GetSecret().GetEncrypted() == nil {
+		return &emptypb.Empty{}, ErrPlaintextSecret
+	}
+
+	if err := secrets.ValidatePreview(in.GetSecretType(), in.GetCardPreview(), in.GetSshKeyPreview(), time.Now()); err != nil {
+		return &emptypb.Empty{}, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
 	if err := s.storage.AddSecret(ctx, username, in); err != nil {
+		if st := storageStatus(err); st != nil {
+			return &emptypb.Empty{}, st
+		}
+
 		logger.Log.Error(
 			"error when adding secret",
 			zap.String("username", string(username)),
 			zap.Error(err),
 		)
-		return &emptypb.Empty{}, status.Errorf(codes.Internal, "pair %s already exists", in.Key)
+		return &emptypb.Empty{}, status.Errorf(codes.Internal, "error adding secret")
 	}
 
 	s.notifyMetadataStreams(username)
@@ -210,6 +685,302 @@ func (s *Server) AddSecret(ctx context.Context, in *api.AddSecretRequest) (*empt
 	return &emptypb.Empty{}, nil
 }
 
+func (s *Server) UpdateSecret(ctx context.Context, in *api.UpdateSecretRequest) (*api.SecretMeta, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	logger.Log.Info(
+		"updating secret",
+		zap.String("username", string(username)),
+		zap.Uint64("revision", in.GetRevision()),
+	)
+
+	// nil secret means only name is changed, previews are checked by the storage with the type the secret keeps
+	if in.GetSecret() != nil && in.GetSecret().GetEncrypted() == nil {
+		return nil, ErrPlaintextSecret
+	}
+
+	secretMeta, err := s.storage.UpdateSecret(ctx, username, in)
+	if err != nil {
+		if st := storageStatus(err); st != nil {
+			return nil, st
+		}
+
+		logger.Log.Error(
+			"error when updating secret",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error updating secret")
+	}
+
+	s.notifyMetadataStreams(username)
+
+	return secretMeta, nil
+}
+
+func (s *Server) UploadSecret(g grpc.ClientStreamingServer[api.UploadSecretRequest, emptypb.Empty]) error {
+	ctx := g.Context()
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	first, err := g.Recv()
+	if err != nil {
+		return err
+	}
+
+	header := first.GetHeader()
+	if header == nil {
+		return status.Errorf(codes.InvalidArgument, "upload must start with a header")
+	}
+
+	if !header.GetEncrypted() {
+		return ErrPlaintextSecret
+	}
+
+	logger.Log.Info(
+		"uploading file secret",
+		zap.String("username", string(username)),
+		zap.String("filename", header.GetFilename()),
+	)
+
+	next := func() ([]byte, error) {
+		req, err := g.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		if req.GetHeader() != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "header is allowed only as the first message")
+		}
+
+		return req.GetChunk(), nil
+	}
+
+	if err = s.storage.UploadSecret(ctx, username, header, next); err != nil {
+		if st := storageStatus(err); st != nil {
+			return st
+		}
+
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+
+		logger.Log.Error(
+			"error when uploading secret",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return status.Errorf(codes.Internal, "error uploading secret")
+	}
+
+	s.notifyMetadataStreams(username)
+
+	return g.SendAndClose(&emptypb.Empty{})
+}
+
+func (s *Server) DownloadSecret(in *api.DownloadSecretRequest, g grpc.ServerStreamingServer[api.DownloadSecretResponse]) error {
+	ctx := g.Context()
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	var (
+		secret     *api.Secret
+		secretMeta *api.SecretMeta
+		err        error
+	)
+
+	if in.GetRevision() != 0 {
+		secret, secretMeta, err = s.storage.GetSecretVersion(ctx, username, in.GetKey(), in.GetRevision())
+	} else {
+		secret, secretMeta, err = s.storage.GetSecret(ctx, username, in.GetKey())
+	}
+	if err != nil {
+		if st := storageStatus(err); st != nil {
+			return st
+		}
+
+		logger.Log.Error(
+			"error getting secret",
+			zap.Error(err),
+		)
+		return status.Errorf(codes.Internal, "error getting secret data")
+	}
+
+	if secretMeta.GetType() != api.SecretType_TYPE_FILE {
+		return status.Errorf(codes.FailedPrecondition, "secret is not a file")
+	}
+
+	err = g.Send(&api.DownloadSecretResponse{
+		Payload: &api.DownloadSecretResponse_Secret{
+			Secret: &api.GetSecretResponse{
+				Secret:     secret,
+				SecretMeta: secretMeta,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.storage.ReadSecretChunks(ctx, username, secretMeta, func(chunk []byte) error {
+		return g.Send(&api.DownloadSecretResponse{
+			Payload: &api.DownloadSecretResponse_Chunk{
+				Chunk: chunk,
+			},
+		})
+	})
+	if err != nil {
+		logger.Log.Error(
+			"error when downloading secret",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return status.Errorf(codes.Internal, "error downloading secret")
+	}
+
+	return nil
+}
+
+func (s *Server) ListSecretVersions(ctx context.Context, in *api.ListSecretVersionsRequest) (*api.ListSecretVersionsResponse, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	versions, err := s.storage.ListSecretVersions(ctx, username, in.GetKey())
+	if err != nil {
+		if st := storageStatus(err); st != nil {
+			return nil, st
+		}
+
+		logger.Log.Error(
+			"error listing secret versions",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error listing secret versions")
+	}
+
+	return &api.ListSecretVersionsResponse{
+		Versions: versions,
+	}, nil
+}
+
+func (s *Server) GetSecretVersion(ctx context.Context, in *api.GetSecretVersionRequest) (*api.GetSecretResponse, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	secret, secretMeta, err := s.storage.GetSecretVersion(ctx, username, in.GetKey(), in.GetRevision())
+	if err != nil {
+		if st := storageStatus(err); st != nil {
+			return nil, st
+		}
+
+		logger.Log.Error(
+			"error getting secret version",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error getting secret version")
+	}
+
+	return &api.GetSecretResponse{
+		Secret:     secret,
+		SecretMeta: secretMeta,
+	}, nil
+}
+
+func (s *Server) RestoreSecretVersion(ctx context.Context, in *api.RestoreSecretVersionRequest) (*api.SecretMeta, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	logger.Log.Info(
+		"restoring secret version",
+		zap.String("username", string(username)),
+		zap.Uint64("revision", in.GetRevision()),
+	)
+
+	secretMeta, err := s.storage.RestoreSecretVersion(ctx, username, in)
+	if err != nil {
+		if st := storageStatus(err); st != nil {
+			return nil, st
+		}
+
+		logger.Log.Error(
+			"error restoring secret version",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error restoring secret version")
+	}
+
+	s.notifyMetadataStreams(username)
+
+	return secretMeta, nil
+}
+
+func (s *Server) ListTrash(ctx context.Context, e *emptypb.Empty) (*api.ListSecretsMetaResponse, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	meta, err := s.storage.ListTrash(ctx, username)
+	if err != nil {
+		logger.Log.Error(
+			"error listing trash",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error listing trash")
+	}
+
+	return &api.ListSecretsMetaResponse{
+		SecretsMeta: meta,
+	}, nil
+}
+
+func (s *Server) RestoreSecret(ctx context.Context, in *api.RestoreSecretRequest) (*api.SecretMeta, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	logger.Log.Info(
+		"restoring secret from trash",
+		zap.String("username", string(username)),
+	)
+
+	secretMeta, err := s.storage.RestoreSecret(ctx, username, in.GetKey())
+	if err != nil {
+		if st := storageStatus(err); st != nil {
+			return nil, st
+		}
+
+		logger.Log.Error(
+			"error restoring secret from trash",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error restoring secret")
+	}
+
+	s.notifyMetadataStreams(username)
+
+	return secretMeta, nil
+}
+
+func (s *Server) PurgeSecret(ctx context.Context, in *api.PurgeSecretRequest) (*emptypb.Empty, error) {
+	username := ctx.Value(auth.Username("username")).([]byte)
+
+	logger.Log.Info(
+		"purging secret from trash",
+		zap.String("username", string(username)),
+	)
+
+	if err := s.storage.PurgeSecret(ctx, username, in.GetKey()); err != nil {
+		if st := storageStatus(err); st != nil {
+			return nil, st
+		}
+
+		logger.Log.Error(
+			"error purging secret",
+			zap.String("username", string(username)),
+			zap.Error(err),
+		)
+		return nil, status.Errorf(codes.Internal, "error purging secret")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
 func (s *Server) ListSecretsMeta(ctx context.Context, e *emptypb.Empty) (*api.ListSecretsMetaResponse, error) {
 	username := ctx.Value(auth.Username("username")).([]byte)
 
@@ -230,13 +1001,40 @@ func (s *Server) ListSecretsMeta(ctx context.Context, e *emptypb.Empty) (*api.Li
 	return response, nil
 }
 
+// notifyMetadataStreams wakes up streams of the user, notifications are coalesced while the stream is sending,
+// so the request changing secrets never waits for slow clients
 func (s *Server) notifyMetadataStreams(username []byte) {
 	s.smap.Range(func(key, value any) bool {
 
 		if bytes.Equal(key.(*session).username, username) {
-			value.(chan any) <- struct{}{}
+			select {
+			case value.(chan struct{}) <- struct{}{}:
+			default:
+			}
 		}
 
 		return true
 	})
 }
+
+// storageStatus converts known storage errors to grpc statuses, nil is returned for unknown ones
+func storageStatus(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrSecretExists):
+		return status.Errorf(codes.AlreadyExists, "secret already exists")
+	case errors.Is(err, storage.ErrSecretNotFound), errors.Is(err, badger.ErrKeyNotFound):
+		return status.Errorf(codes.NotFound, "no such secret")
+	case errors.Is(err, storage.ErrVersionNotFound):
+		return status.Errorf(codes.NotFound, "no such secret version")
+	case errors.Is(err, storage.ErrRevisionMismatch):
+		return status.Errorf(codes.Aborted, "secret was changed by another client, fetch it and retry")
+	case errors.Is(err, storage.ErrFileTooLarge):
+		return status.Errorf(codes.ResourceExhausted, "file is too large")
+	case errors.Is(err, storage.ErrFileUpdate):
+		return status.Errorf(codes.InvalidArgument, "file secrets can be written only by upload")
+	case errors.Is(err, secrets.ErrInvalidPreview), errors.Is(err, secrets.ErrInvalidCardExpiry), errors.Is(err, secrets.ErrCardExpired):
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"github.com/renatus-cartesius/nedovault/pkg/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatalf("badger.Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	st := storage.NewBadgerStorage(db)
+	if err = st.CreateAuthMeta(context.Background(), []byte("alice"), &auth.Meta{}); err != nil {
+		t.Fatalf("CreateAuthMeta() error = %v", err)
+	}
+
+	return NewServer(st, nil)
+}
+
+func TestAddSecretCardPreview(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.Username("username"), []byte("alice"))
+	now := time.Now()
+
+	// month and year of the current month shifted by months
+	expiry := func(months int) (uint32, uint32) {
+		at := time.Date(now.Year(), now.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+		return uint32(at.Month()), uint32(at.Year())
+	}
+	preview := func(lastFour, brand string, months int) *api.CardPreview {
+		month, year := expiry(months)
+		return &api.CardPreview{LastFour: lastFour, Brand: brand, ExpiryMonth: month, ExpiryYear: year}
+	}
+
+	tests := []struct {
+		name    string
+		preview *api.CardPreview
+		want    codes.Code
+	}{
+		{name: "valid", preview: preview("1111", "visa", 12)},
+		{name: "expires this month", preview: preview("1111", "visa", 0)},
+		{name: "expired last month", preview: preview("1111", "visa", -1), want: codes.InvalidArgument},
+		{name: "expired years ago", preview: preview("1111", "visa", -36), want: codes.InvalidArgument},
+		{name: "no expiry", preview: &api.CardPreview{LastFour: "1111", Brand: "visa"}, want: codes.InvalidArgument},
+		{name: "full number", preview: preview("4111111111111111", "visa", 12), want: codes.InvalidArgument},
+		{name: "unknown brand", preview: preview("1111", "cvv 123", 12), want: codes.InvalidArgument},
+	}
+
+	s := newTestServer(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.AddSecret(ctx, &api.AddSecretRequest{
+				Key:         []byte(tt.name),
+				Name:        []byte(tt.name),
+				SecretType:  api.SecretType_TYPE_CARD,
+				Secret:      &api.Secret{Secret: &api.Secret_Encrypted{Encrypted: []byte("sealed card")}},
+				CardPreview: tt.preview,
+			})
+			if status.Code(err) != tt.want {
+				t.Errorf("AddSecret() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
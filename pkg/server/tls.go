@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"github.com/renatus-cartesius/metricserv/pkg/logger"
+	"go.uber.org/zap"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoCertificates = errors.New("no certificates found in the CA bundle")
+)
+
+// CertReloader serves the certificate and the client CA bundle from files and reloads them once they are changed,
+// so certificates are renewed without restart
+type CertReloader struct {
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	// stamps are modification times and sizes of the loaded files
+	stamps []fileStamp
+
+	certPath     string
+	keyPath      string
+	clientCAPath string
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewCertReloader loads the certificate and its key. Client certificates are verified against
+// the CA bundle if its path is set, otherwise they are not requested
+func NewCertReloader(certPath, keyPath, clientCAPath string) (*CertReloader, error) {
+	r := &CertReloader{
+		certPath:     certPath,
+		keyPath:      keyPath,
+		clientCAPath: clientCAPath,
+	}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload loads the files if any of them was changed since the last load and reports whether they were reloaded.
+// The previous certificate is kept if the files can't be loaded, e.g. they are being written
+func (r *CertReloader) Reload() (bool, error) {
+	stamps, err := r.stat()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	changed := !equalStamps(stamps, r.stamps)
+	r.mu.RUnlock()
+
+	if !changed {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return false, err
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAPath != "" {
+		if clientCAs, err = loadCertPool(r.clientCAPath); err != nil {
+			return false, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.stamps = stamps
+
+	return true, nil
+}
+
+// Run checks the files for changes every interval
+func (r *CertReloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				logger.Log.Error(
+					"error reloading tls certificate",
+					zap.String("cert_path", r.certPath),
+					zap.Error(err),
+				)
+				continue
+			}
+
+			if reloaded {
+				logger.Log.Info(
+					"tls certificate reloaded",
+					zap.String("cert_path", r.certPath),
+				)
+			}
+		}
+	}
+}
+
+// GetCertificate returns the current certificate, it is used as tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// ServerConfig returns TLS config of the grpc server, client certificates are required if the CA bundle is set
+func (r *CertReloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// config is made on every handshake, so the reloaded CA bundle is used by new connections
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+			}
+
+			if r.clientCAs != nil {
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+				config.ClientCAs = r.clientCAs
+			}
+
+			return config, nil
+		},
+	}
+}
+
+func (r *CertReloader) stat() ([]fileStamp, error) {
+	paths := []string{r.certPath, r.keyPath}
+	if r.clientCAPath != "" {
+		paths = append(paths, r.clientCAPath)
+	}
+
+	stamps := make([]fileStamp, 0, len(paths))
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		stamps = append(stamps, fileStamp{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		})
+	}
+
+	return stamps, nil
+}
+
+func equalStamps(a, b []fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+
+	return true
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	bundle, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, ErrNoCertificates
+	}
+
+	return pool, nil
+}
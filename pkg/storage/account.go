@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// userNamespaces are namespaces deleted with the user, auth metadata is deleted separately
+var userNamespaces = []string{
+	secretsData,
+	secretsMetadata,
+	secretsChunks,
+	secretsHistory,
+	trashData,
+	trashMetadata,
+	trashHistory,
+	authTokens,
+	authSessions,
+	secretsChanges,
+	secretsChangeIndex,
+	secretsSequence,
+}
+
+// DeleteUser removes the user with all secrets, their versions, trash and tokens. Keys are deleted in batches,
+// auth metadata goes last, so the user can log in and retry if the deletion is interrupted
+func (b *BadgerStorage) DeleteUser(ctx context.Context, username []byte) error {
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for _, namespace := range userNamespaces {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			prefix := userNamespacePrefix(username, namespace)
+
+			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+				if err := wb.Delete(it.Item().KeyCopy(nil)); err != nil {
+					return err
+				}
+			}
+		}
+
+		// trash index is global, so entries of the user are found by the username in the key
+		prefix := []byte(trashIndex + "/")
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			indexKey := it.Item().KeyCopy(nil)
+
+			owner, _, err := parseTrashIndex(indexKey)
+			if err != nil || !bytes.Equal(owner, username) {
+				continue
+			}
+
+			if err = wb.Delete(indexKey); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = wb.Delete(dataKeyPath(username)); err != nil {
+		return err
+	}
+
+	if err = wb.Flush(); err != nil {
+		return err
+	}
+
+	// cached data key would be reused by a new user with the same name without being saved
+	b.dataKeys.Delete(string(username))
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(authMetadataPrefix(username))
+	})
+}
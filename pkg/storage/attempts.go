@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"time"
+)
+
+// GetLoginAttempts returns failed login attempts counted by the key or nil if there are none
+func (b *BadgerStorage) GetLoginAttempts(ctx context.Context, key []byte) (*auth.LoginAttempts, error) {
+	var attempts *auth.LoginAttempts
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		var err error
+		attempts, err = getLoginAttempts(txn, key)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+
+	return attempts, err
+}
+
+// AddLoginFailure counts the failed attempt if check of the current attempts passes, both in one transaction.
+// The record expires after the window since the last failure
+func (b *BadgerStorage) AddLoginFailure(ctx context.Context, key []byte, window time.Duration, check func(attempts *auth.LoginAttempts) error) (*auth.LoginAttempts, error) {
+	var attempts *auth.LoginAttempts
+
+	// concurrent failure is counted again, so parallel guesses are not lost
+	err := retryConflicts(func() error {
+		return b.db.Update(func(txn *badger.Txn) error {
+			var err error
+
+			attempts, err = getLoginAttempts(txn, key)
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				attempts, err = &auth.LoginAttempts{}, nil
+			}
+			if err != nil {
+				return err
+			}
+
+			if err = check(attempts); err != nil {
+				return err
+			}
+
+			attempts.Failures++
+			attempts.LastFailure = time.Now()
+
+			attemptsRaw, err := json.Marshal(attempts)
+			if err != nil {
+				return err
+			}
+
+			return txn.SetEntry(badger.NewEntry(loginAttemptsPath(key), attemptsRaw).WithTTL(window))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// RemoveLoginFailure takes back one failure counted by AddLoginFailure, the record keeps its expiration
+func (b *BadgerStorage) RemoveLoginFailure(ctx context.Context, key []byte) error {
+	return retryConflicts(func() error {
+		return b.db.Update(func(txn *badger.Txn) error {
+			item, err := txn.Get(loginAttemptsPath(key))
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			attempts, err := getLoginAttempts(txn, key)
+			if err != nil {
+				return err
+			}
+
+			attempts.Failures--
+			if attempts.Failures <= 0 {
+				return txn.Delete(loginAttemptsPath(key))
+			}
+
+			attemptsRaw, err := json.Marshal(attempts)
+			if err != nil {
+				return err
+			}
+
+			entry := badger.NewEntry(loginAttemptsPath(key), attemptsRaw)
+			if expiresAt := item.ExpiresAt(); expiresAt != 0 {
+				entry.ExpiresAt = expiresAt
+			}
+
+			return txn.SetEntry(entry)
+		})
+	})
+}
+
+// ResetLoginAttempts forgets failed login attempts counted by the key
+func (b *BadgerStorage) ResetLoginAttempts(ctx context.Context, key []byte) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(loginAttemptsPath(key))
+	})
+}
+
+func getLoginAttempts(txn *badger.Txn, key []byte) (*auth.LoginAttempts, error) {
+	item, err := txn.Get(loginAttemptsPath(key))
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := &auth.LoginAttempts{}
+
+	err = item.Value(func(v []byte) error {
+		return json.Unmarshal(v, attempts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
@@ -5,55 +5,95 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/dgraph-io/badger/v4"
+	"github.com/google/uuid"
 	"github.com/renatus-cartesius/metricserv/pkg/logger"
 	"github.com/renatus-cartesius/nedovault/api"
 	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"github.com/renatus-cartesius/nedovault/internal/secrets"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"sync"
+	"time"
 )
 
 var (
 	secretsData     = "secrets_data"
 	secretsMetadata = "secrets_metadata"
 	authMetadata    = "auth_metadata"
+	secretsChunks   = "secrets_chunks"
+	secretsHistory  = "secrets_history"
+	trashData       = "trash_data"
+	trashMetadata   = "trash_metadata"
+	trashHistory    = "trash_history"
+	trashIndex      = "trash_index"
+	dataKeys        = "data_keys"
+	authTokens      = "auth_tokens"
+	loginAttempts   = "login_attempts"
+	signingKeys     = "signing_keys"
+	authSessions    = "auth_sessions"
+	// change log of the secrets, its index by secret key and the last sequence number
+	secretsChanges     = "secrets_changes"
+	secretsChangeIndex = "secrets_change_index"
+	secretsSequence    = "secrets_sequence"
 )
 
-func NewBadgerStorage(db *badger.DB) *BadgerStorage {
-	return &BadgerStorage{
-		db: db,
+var (
+	ErrFileTooLarge     = errors.New("file exceeds maximum allowed size")
+	ErrFileUpdate       = errors.New("file secrets can be written only by upload")
+	ErrSecretExists     = errors.New("secret already exists")
+	ErrSecretNotFound   = errors.New("secret not found")
+	ErrRevisionMismatch = errors.New("secret was changed concurrently")
+	ErrVersionNotFound  = errors.New("secret version not found")
+)
+
+type Option func(b *BadgerStorage)
+
+// WithMaxFileSize limits size of file secrets, zero means no limit
+func WithMaxFileSize(size int64) Option {
+	return func(b *BadgerStorage) {
+		b.maxFileSize = size
 	}
 }
 
-type BadgerStorage struct {
-	db *badger.DB
+// WithHistoryDepth sets how many previous versions of every secret are kept, zero disables history
+func WithHistoryDepth(depth int) Option {
+	return func(b *BadgerStorage) {
+		b.historyDepth = depth
+	}
 }
 
-func (b *BadgerStorage) DeleteSecret(ctx context.Context, username []byte, in *api.DeleteSecretRequest) error {
-	dataPath := []byte(fmt.Sprintf("%s/%s", secretsDataPrefix(username), in.GetKey()))
-	metadataPath := []byte(fmt.Sprintf("%s/%s", secretsMetadataPrefix(username), in.GetKey()))
+// WithKeyring enables envelope encryption of secrets with per-user data keys wrapped by the keyring master keys
+func WithKeyring(keyring *Keyring) Option {
+	return func(b *BadgerStorage) {
+		b.keyring = keyring
+	}
+}
 
-	err := b.db.Update(func(txn *badger.Txn) error {
-		txn = b.db.NewTransaction(true)
+func NewBadgerStorage(db *badger.DB, opts ...Option) *BadgerStorage {
+	b := &BadgerStorage{
+		db: db,
+	}
 
-		if err := txn.Delete(dataPath); err != nil {
-			return err
-		}
+	for _, opt := range opts {
+		opt(b)
+	}
 
-		if err := txn.Delete(metadataPath); err != nil {
-			return err
-		}
+	return b
+}
 
-		if err := txn.Commit(); err != nil {
-			return err
-		}
+type BadgerStorage struct {
+	db *badger.DB
 
-		return nil
-	})
+	maxFileSize  int64
+	historyDepth int
 
-	return err
+	keyring *Keyring
+	// unwrapped data keys of the users
+	dataKeys sync.Map
+	// mutexes serializing changes of the secrets of every user
+	secretLocks sync.Map
 }
 
 // GetAuthMeta getting user`s auth metadata from underlying storage
@@ -130,8 +170,74 @@ func (b *BadgerStorage) AddAuthMeta(ctx context.Context, username []byte, meta *
 	return err
 }
 
-func (b *BadgerStorage) AppendTokens(ctx context.Context, username, token []byte) error {
-	return nil
+// UpdateAuthMeta changes auth metadata of the user by fn in one transaction, so concurrent changes are not lost.
+// Error of fn aborts the update, ErrUserNotFound is returned if there is no such user
+func (b *BadgerStorage) UpdateAuthMeta(ctx context.Context, username []byte, fn func(meta *auth.Meta) error) error {
+	authMetadataPath := authMetadataPrefix(username)
+
+	return retryConflicts(func() error {
+		return b.db.Update(func(txn *badger.Txn) error {
+			item, err := txn.Get(authMetadataPath)
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return auth.ErrUserNotFound
+			}
+			if err != nil {
+				return err
+			}
+
+			meta := &auth.Meta{}
+			if err = item.Value(func(v []byte) error {
+				return json.Unmarshal(v, meta)
+			}); err != nil {
+				return err
+			}
+
+			if err = fn(meta); err != nil {
+				return err
+			}
+
+			aMetaRaw, err := json.Marshal(meta)
+			if err != nil {
+				return err
+			}
+
+			return txn.Set(authMetadataPath, aMetaRaw)
+		})
+	})
+}
+
+// CreateAuthMeta adding auth metadata of the new user to underlying storage
+func (b *BadgerStorage) CreateAuthMeta(ctx context.Context, username []byte, meta *auth.Meta) error {
+	authMetadataPath := authMetadataPrefix(username)
+
+	aMetaRaw, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	err = b.db.Update(func(txn *badger.Txn) error {
+		_, err := txn.Get(authMetadataPath)
+		if err == nil {
+			return auth.ErrUserExists
+		}
+		if !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+
+		// new epoch tells clients synced with the deleted account of the same name to start over
+		if err = putChangeSequence(txn, username, &changeSequence{Epoch: uuid.NewString()}); err != nil {
+			return err
+		}
+
+		return txn.Set(authMetadataPath, aMetaRaw)
+	})
+
+	// concurrent transaction has registered the same user
+	if errors.Is(err, badger.ErrConflict) {
+		return auth.ErrUserExists
+	}
+
+	return err
 }
 
 func (b *BadgerStorage) ListSecretsMeta(ctx context.Context, username []byte) ([]*api.SecretMeta, error) {
@@ -168,14 +274,19 @@ func (b *BadgerStorage) ListSecretsMeta(ctx context.Context, username []byte) ([
 }
 
 func (b *BadgerStorage) AddSecret(ctx context.Context, username []byte, in *api.AddSecretRequest) error {
-	dataPath := []byte(fmt.Sprintf("%s/%s", secretsDataPrefix(username), in.GetKey()))
-	metadataPath := []byte(fmt.Sprintf("%s/%s", secretsMetadataPrefix(username), in.GetKey()))
+	dataPath := secretDataPath(username, in.GetKey())
+	metadataPath := secretMetadataPath(username, in.GetKey())
 
-	err := b.db.Update(func(txn *badger.Txn) error {
-		txn = b.db.NewTransaction(true)
+	if in.GetSecret().GetFile() != nil {
+		return ErrFileUpdate
+	}
 
-		sDataRaw, err := proto.Marshal(in.GetSecret())
-		if err = txn.Set(dataPath, sDataRaw); err != nil {
+	err := b.updateSecrets(username, func(txn *badger.Txn) error {
+		_, err := txn.Get(metadataPath)
+		if err == nil {
+			return ErrSecretExists
+		}
+		if !errors.Is(err, badger.ErrKeyNotFound) {
 			return err
 		}
 
@@ -184,31 +295,155 @@ func (b *BadgerStorage) AddSecret(ctx context.Context, username []byte, in *api.
 			Name:      in.Name,
 			Timestamp: timestamppb.Now(),
 			Type:      in.GetSecretType(),
+			Revision:  1,
 		}
+		setPreview(sMetadata, in.GetSecret(), in.GetCardPreview(), in.GetSshKeyPreview())
+
+		return b.putSecret(txn, username, dataPath, metadataPath, in.GetSecret(), sMetadata)
+	})
+
+	// concurrent transaction has added the same key
+	if errors.Is(err, badger.ErrConflict) {
+		return ErrSecretExists
+	}
+
+	return err
+}
 
-		sMetadataRaw, err := proto.Marshal(sMetadata)
+// UpdateSecret replaces the secret if its revision is still the same as the passed one and returns its new metadata
+func (b *BadgerStorage) UpdateSecret(ctx context.Context, username []byte, in *api.UpdateSecretRequest) (*api.SecretMeta, error) {
+	dataPath := secretDataPath(username, in.GetKey())
+	metadataPath := secretMetadataPath(username, in.GetKey())
+
+	var (
+		sMetadata *api.SecretMeta
+		released  []string
+	)
+
+	err := b.updateSecrets(username, func(txn *badger.Txn) error {
+		oldMeta, err := getSecretMeta(txn, metadataPath)
 		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return ErrSecretNotFound
+			}
 			return err
 		}
 
-		if err = txn.Set(metadataPath, sMetadataRaw); err != nil {
+		if oldMeta.GetRevision() != in.GetRevision() {
+			return ErrRevisionMismatch
+		}
+
+		if in.GetSecret().GetFile() != nil {
+			return ErrFileUpdate
+		}
+
+		// unset name and type are kept, so an update made by an older client doesn't clear them
+		secretType := oldMeta.GetType()
+		if in.SecretType != nil {
+			secretType = in.GetSecretType()
+		}
+
+		if err = secrets.ValidatePreview(secretType, in.GetCardPreview(), in.GetSshKeyPreview(), time.Now()); err != nil {
 			return err
 		}
 
-		if err = txn.Commit(); err != nil {
+		if released, err = b.archiveVersion(txn, username, oldMeta); err != nil {
 			return err
 		}
 
-		return nil
+		sMetadata = proto.Clone(oldMeta).(*api.SecretMeta)
+		if in.Name != nil {
+			sMetadata.Name = in.GetName()
+		}
+		sMetadata.Timestamp = timestamppb.Now()
+		sMetadata.Revision = oldMeta.GetRevision() + 1
+
+		// only name is changed
+		if in.GetSecret() == nil {
+			sMetadataRaw, err := proto.Marshal(sMetadata)
+			if err != nil {
+				return err
+			}
+
+			if err = txn.Set(metadataPath, sMetadataRaw); err != nil {
+				return err
+			}
+
+			return recordUpsert(txn, username, sMetadata)
+		}
+
+		sMetadata.Type = secretType
+		sMetadata.BlobId = ""
+		sMetadata.Size = 0
+		sMetadata.Preview = nil
+		setPreview(sMetadata, in.GetSecret(), in.GetCardPreview(), in.GetSshKeyPreview())
+
+		return b.putSecret(txn, username, dataPath, metadataPath, in.GetSecret(), sMetadata)
 	})
+	if err != nil {
+		if errors.Is(err, badger.ErrConflict) {
+			return nil, ErrRevisionMismatch
+		}
+		return nil, err
+	}
 
-	return err
+	if err = b.releaseBlobs(username, in.GetKey(), released); err != nil {
+		return nil, err
+	}
+
+	return sMetadata, nil
+}
+
+// setPreview makes preview of plaintext secrets, encrypted ones get preview passed by the client
+func setPreview(meta *api.SecretMeta, secret *api.Secret, card *api.CardPreview, sshKey *api.SshKeyPreview) {
+	if secret.GetEncrypted() == nil {
+		secrets.FillPreview(meta, secret)
+		return
+	}
+
+	switch {
+	case card != nil:
+		meta.Preview = &api.SecretMeta_Card{
+			Card: card,
+		}
+	case sshKey != nil:
+		meta.Preview = &api.SecretMeta_SshKey{
+			SshKey: sshKey,
+		}
+	}
+}
+
+// putSecret writes the secret with its metadata and records the change
+func (b *BadgerStorage) putSecret(txn *badger.Txn, username, dataPath, metadataPath []byte, secret *api.Secret, meta *api.SecretMeta) error {
+	sDataRaw, err := proto.Marshal(secret)
+	if err != nil {
+		return err
+	}
+
+	entry, err := b.sealEntry(username, dataPath, sDataRaw)
+	if err != nil {
+		return err
+	}
+
+	if err = txn.SetEntry(entry); err != nil {
+		return err
+	}
+
+	sMetadataRaw, err := proto.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	if err = txn.Set(metadataPath, sMetadataRaw); err != nil {
+		return err
+	}
 
+	return recordUpsert(txn, username, meta)
 }
 
 func (b *BadgerStorage) GetSecret(ctx context.Context, username, key []byte) (*api.Secret, *api.SecretMeta, error) {
-	dataPath := []byte(fmt.Sprintf("%s/%s", secretsDataPrefix(username), key))
-	metadataPath := []byte(fmt.Sprintf("%s/%s", secretsMetadataPrefix(username), key))
+	dataPath := secretDataPath(username, key)
+	metadataPath := secretMetadataPath(username, key)
 
 	secret := &api.Secret{}
 	secretMeta := &api.SecretMeta{}
@@ -226,7 +461,7 @@ func (b *BadgerStorage) GetSecret(ctx context.Context, username, key []byte) (*a
 			return err
 		}
 
-		valCopy, err = secretItem.ValueCopy(nil)
+		valCopy, err = b.openValue(username, secretItem)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/google/uuid"
+	"github.com/renatus-cartesius/nedovault/api"
+	"google.golang.org/protobuf/proto"
+	"sync"
+)
+
+// changeSequence is the last sequence number of the changes of the user and the one up to which
+// deletes were compacted, clients synced before it can't catch up by changes.
+// Epoch is a random id of the account, sequence numbers of the account deleted and registered again start over
+type changeSequence struct {
+	Seq   uint64 `json:"seq"`
+	Floor uint64 `json:"floor"`
+	Epoch string `json:"epoch,omitempty"`
+}
+
+// SyncSecrets returns changes of the secrets committed after the passed sequence number of the epoch. If they are not kept
+// anymore, the epoch differs or since is zero, all secrets are returned as upserts with reset set, so the client replaces its copy
+func (b *BadgerStorage) SyncSecrets(ctx context.Context, username []byte, since uint64, epoch string) (*api.SyncResponse, error) {
+	resp := &api.SyncResponse{
+		Changes: make([]*api.SecretChange, 0),
+	}
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		sequence, err := getChangeSequence(txn, username)
+		if err != nil {
+			return err
+		}
+
+		resp.Seq = sequence.Seq
+		resp.Epoch = sequence.Epoch
+
+		// clients without epoch still notice the account registered again, while its sequence is behind theirs
+		sameEpoch := epoch == "" || epoch == sequence.Epoch
+
+		if since != 0 && sameEpoch && since >= sequence.Floor && since <= sequence.Seq {
+			resp.Changes, err = listChanges(txn, username, since)
+			return err
+		}
+
+		resp.Reset_ = true
+
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := append(secretsMetadataPrefix(username), '/')
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			meta := &api.SecretMeta{}
+			if err = it.Item().Value(func(v []byte) error {
+				return proto.Unmarshal(v, meta)
+			}); err != nil {
+				return err
+			}
+
+			resp.Changes = append(resp.Changes, &api.SecretChange{
+				Kind:       api.ChangeKind_CHANGE_KIND_UPSERT,
+				Key:        meta.GetKey(),
+				SecretMeta: meta,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// updateSecrets runs the transaction changing secrets of the user. Such transactions of the same user
+// are serialized, so changes are committed in the order of their sequence numbers and readers never skip one
+func (b *BadgerStorage) updateSecrets(username []byte, fn func(txn *badger.Txn) error) error {
+	mu, _ := b.secretLocks.LoadOrStore(string(username), &sync.Mutex{})
+
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	return b.db.Update(fn)
+}
+
+func recordUpsert(txn *badger.Txn, username []byte, meta *api.SecretMeta) error {
+	return recordChange(txn, username, &api.SecretChange{
+		Kind:       api.ChangeKind_CHANGE_KIND_UPSERT,
+		Key:        meta.GetKey(),
+		SecretMeta: meta,
+	})
+}
+
+func recordDelete(txn *badger.Txn, username, key []byte) error {
+	return recordChange(txn, username, &api.SecretChange{
+		Kind: api.ChangeKind_CHANGE_KIND_DELETE,
+		Key:  key,
+	})
+}
+
+// recordChange appends the change to the change log of the user, the previous change of the same secret is dropped,
+// so the log keeps one change per secret
+func recordChange(txn *badger.Txn, username []byte, change *api.SecretChange) error {
+	sequence, err := getChangeSequence(txn, username)
+	if err != nil {
+		return err
+	}
+
+	indexPath := secretChangeIndexPath(username, change.GetKey())
+
+	prev, err := getIndexedChange(txn, indexPath)
+	if err != nil {
+		return err
+	}
+	if prev != 0 {
+		if err = txn.Delete(secretChangePath(username, prev)); err != nil {
+			return err
+		}
+	}
+
+	// accounts registered before epochs were introduced get one on the first change
+	if sequence.Epoch == "" {
+		sequence.Epoch = uuid.NewString()
+	}
+
+	sequence.Seq++
+	change.Seq = sequence.Seq
+
+	raw, err := proto.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	if err = txn.Set(secretChangePath(username, change.Seq), raw); err != nil {
+		return err
+	}
+
+	if err = txn.Set(indexPath, binary.BigEndian.AppendUint64(nil, change.Seq)); err != nil {
+		return err
+	}
+
+	return putChangeSequence(txn, username, sequence)
+}
+
+// compactDelete drops the delete of the purged secret from the change log, clients synced before it start over
+func compactDelete(txn *badger.Txn, username, key []byte) error {
+	indexPath := secretChangeIndexPath(username, key)
+
+	seq, err := getIndexedChange(txn, indexPath)
+	if err != nil || seq == 0 {
+		return err
+	}
+
+	item, err := txn.Get(secretChangePath(username, seq))
+	if err != nil {
+		return err
+	}
+
+	change := &api.SecretChange{}
+	if err = item.Value(func(v []byte) error {
+		return proto.Unmarshal(v, change)
+	}); err != nil {
+		return err
+	}
+
+	// secret with the same key was added after the delete
+	if change.GetKind() != api.ChangeKind_CHANGE_KIND_DELETE {
+		return nil
+	}
+
+	sequence, err := getChangeSequence(txn, username)
+	if err != nil {
+		return err
+	}
+
+	sequence.Floor = max(sequence.Floor, seq)
+
+	for _, k := range [][]byte{secretChangePath(username, seq), indexPath} {
+		if err = txn.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return putChangeSequence(txn, username, sequence)
+}
+
+// listChanges returns changes of the user committed after since ordered by sequence number
+func listChanges(txn *badger.Txn, username []byte, since uint64) ([]*api.SecretChange, error) {
+	changes := make([]*api.SecretChange, 0)
+	prefix := secretChangesPrefix(username)
+
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	for it.Seek(secretChangePath(username, since+1)); it.ValidForPrefix(prefix); it.Next() {
+		change := &api.SecretChange{}
+		if err := it.Item().Value(func(v []byte) error {
+			return proto.Unmarshal(v, change)
+		}); err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// getIndexedChange returns sequence number of the last change of the secret, zero if there is none
+func getIndexedChange(txn *badger.Txn, indexPath []byte) (uint64, error) {
+	item, err := txn.Get(indexPath)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var seq uint64
+	err = item.Value(func(v []byte) error {
+		seq = binary.BigEndian.Uint64(v)
+		return nil
+	})
+
+	return seq, err
+}
+
+func getChangeSequence(txn *badger.Txn, username []byte) (*changeSequence, error) {
+	sequence := &changeSequence{}
+
+	item, err := txn.Get(secretsSequencePath(username))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return sequence, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = item.Value(func(v []byte) error {
+		return json.Unmarshal(v, sequence)
+	})
+
+	return sequence, err
+}
+
+func putChangeSequence(txn *badger.Txn, username []byte, sequence *changeSequence) error {
+	raw, err := json.Marshal(sequence)
+	if err != nil {
+		return err
+	}
+
+	return txn.Set(secretsSequencePath(username), raw)
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"slices"
+	"testing"
+)
+
+// change is a short form of the secret change compared by the tests
+type change struct {
+	kind api.ChangeKind
+	key  string
+	seq  uint64
+}
+
+const (
+	upsert = api.ChangeKind_CHANGE_KIND_UPSERT
+	remove = api.ChangeKind_CHANGE_KIND_DELETE
+)
+
+func addTestSecret(t *testing.T, b *BadgerStorage, username []byte, key string) {
+	t.Helper()
+
+	err := b.AddSecret(context.Background(), username, &api.AddSecretRequest{
+		Key:        []byte(key),
+		Name:       []byte(key),
+		SecretType: api.SecretType_TYPE_TEXT,
+		Secret:     &api.Secret{Secret: &api.Secret_Text{Text: &api.Text{Data: key}}},
+	})
+	if err != nil {
+		t.Fatalf("AddSecret(%s) error = %v", key, err)
+	}
+}
+
+func shortChanges(resp *api.SyncResponse) []change {
+	changes := make([]change, 0, len(resp.GetChanges()))
+	for _, ch := range resp.GetChanges() {
+		changes = append(changes, change{kind: ch.GetKind(), key: string(ch.GetKey()), seq: ch.GetSeq()})
+	}
+
+	return changes
+}
+
+func TestSyncSecrets(t *testing.T) {
+	ctx := context.Background()
+	username := []byte("alice")
+
+	b := newTestStorage(t)
+
+	// a and b are added, a is renamed and b is deleted
+	addTestSecret(t, b, username, "a")
+	addTestSecret(t, b, username, "b")
+
+	if _, err := b.UpdateSecret(ctx, username, &api.UpdateSecretRequest{Key: []byte("a"), Name: []byte("renamed"), Revision: 1}); err != nil {
+		t.Fatalf("UpdateSecret() error = %v", err)
+	}
+	if err := b.DeleteSecret(ctx, username, &api.DeleteSecretRequest{Key: []byte("b")}); err != nil {
+		t.Fatalf("DeleteSecret() error = %v", err)
+	}
+
+	current, err := b.SyncSecrets(ctx, username, 0, "")
+	if err != nil {
+		t.Fatalf("SyncSecrets() error = %v", err)
+	}
+	if current.GetSeq() != 4 || current.GetEpoch() == "" {
+		t.Fatalf("SyncSecrets() seq = %d, epoch = %q, want seq 4 and an epoch", current.GetSeq(), current.GetEpoch())
+	}
+
+	epoch := current.GetEpoch()
+
+	tests := []struct {
+		name  string
+		since uint64
+		epoch string
+		reset bool
+		want  []change
+	}{
+		{name: "first sync", since: 0, epoch: "", reset: true, want: []change{{kind: upsert, key: "a"}}},
+		{name: "after adds", since: 2, epoch: epoch, want: []change{{kind: upsert, key: "a", seq: 3}, {kind: remove, key: "b", seq: 4}}},
+		{name: "after rename", since: 3, epoch: epoch, want: []change{{kind: remove, key: "b", seq: 4}}},
+		{name: "up to date", since: 4, epoch: epoch, want: []change{}},
+		{name: "client without epoch", since: 3, epoch: "", want: []change{{kind: remove, key: "b", seq: 4}}},
+		{name: "another epoch", since: 3, epoch: "another", reset: true, want: []change{{kind: upsert, key: "a"}}},
+		{name: "ahead of the vault", since: 5, epoch: "", reset: true, want: []change{{kind: upsert, key: "a"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := b.SyncSecrets(ctx, username, tt.since, tt.epoch)
+			if err != nil {
+				t.Fatalf("SyncSecrets() error = %v", err)
+			}
+
+			if resp.GetReset_() != tt.reset {
+				t.Errorf("SyncSecrets() reset = %v, want %v", resp.GetReset_(), tt.reset)
+			}
+			if resp.GetSeq() != 4 || resp.GetEpoch() != epoch {
+				t.Errorf("SyncSecrets() seq = %d, epoch = %q, want 4, %q", resp.GetSeq(), resp.GetEpoch(), epoch)
+			}
+
+			got := shortChanges(resp)
+			// snapshot changes have no sequence numbers
+			if tt.reset {
+				for i := range got {
+					got[i].seq = 0
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SyncSecrets() changes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncSecretsFloor(t *testing.T) {
+	ctx := context.Background()
+	username := []byte("alice")
+
+	b := newTestStorage(t)
+
+	addTestSecret(t, b, username, "a")
+	addTestSecret(t, b, username, "b")
+
+	if err := b.DeleteSecret(ctx, username, &api.DeleteSecretRequest{Key: []byte("a")}); err != nil {
+		t.Fatalf("DeleteSecret() error = %v", err)
+	}
+	addTestSecret(t, b, username, "c")
+
+	// delete of the purged secret is compacted, clients synced before it can't learn about it
+	if err := b.PurgeSecret(ctx, username, []byte("a")); err != nil {
+		t.Fatalf("PurgeSecret() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		since uint64
+		reset bool
+		want  []change
+	}{
+		{name: "before the compacted delete", since: 2, reset: true, want: []change{{kind: upsert, key: "b"}, {kind: upsert, key: "c"}}},
+		{name: "at the compacted delete", since: 3, want: []change{{kind: upsert, key: "c", seq: 4}}},
+		{name: "up to date", since: 4, want: []change{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := b.SyncSecrets(ctx, username, tt.since, "")
+			if err != nil {
+				t.Fatalf("SyncSecrets() error = %v", err)
+			}
+
+			got := shortChanges(resp)
+			if tt.reset {
+				for i := range got {
+					got[i].seq = 0
+				}
+			}
+
+			if resp.GetReset_() != tt.reset || !slices.Equal(got, tt.want) {
+				t.Errorf("SyncSecrets() = %v, reset %v, want %v, reset %v", got, resp.GetReset_(), tt.want, tt.reset)
+			}
+		})
+	}
+}
+
+func TestSyncSecretsEpoch(t *testing.T) {
+	ctx := context.Background()
+	username := []byte("alice")
+
+	b := newTestStorage(t)
+
+	addTestSecret(t, b, username, "a")
+	addTestSecret(t, b, username, "b")
+
+	before, err := b.SyncSecrets(ctx, username, 0, "")
+	if err != nil {
+		t.Fatalf("SyncSecrets() error = %v", err)
+	}
+
+	// account is registered again and gets as many changes as the client has seen
+	if err = b.DeleteUser(ctx, username); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if err = b.CreateAuthMeta(ctx, username, &auth.Meta{}); err != nil {
+		t.Fatalf("CreateAuthMeta() error = %v", err)
+	}
+
+	addTestSecret(t, b, username, "x")
+	addTestSecret(t, b, username, "y")
+	addTestSecret(t, b, username, "z")
+
+	resp, err := b.SyncSecrets(ctx, username, before.GetSeq(), before.GetEpoch())
+	if err != nil {
+		t.Fatalf("SyncSecrets() error = %v", err)
+	}
+
+	if resp.GetEpoch() == before.GetEpoch() {
+		t.Error("SyncSecrets() epoch is the same after the account is registered again")
+	}
+
+	got := shortChanges(resp)
+	for i := range got {
+		got[i].seq = 0
+	}
+
+	want := []change{{kind: upsert, key: "x"}, {kind: upsert, key: "y"}, {kind: upsert, key: "z"}}
+	if !resp.GetReset_() || !slices.Equal(got, want) {
+		t.Errorf("SyncSecrets() = %v, reset %v, want %v, reset true", got, resp.GetReset_(), want)
+	}
+}
@@ -0,0 +1,331 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"strings"
+)
+
+// KEKSize is a size of the server master key
+const KEKSize = 32
+
+// metaEncrypted marks values sealed by the data key of the user, values without it were written before envelope encryption
+const metaEncrypted byte = 1 << 0
+
+// rewrapBatchSize limits how many data keys are rewrapped in one transaction
+const rewrapBatchSize = 1000
+
+var (
+	ErrInvalidKEK = errors.New("master key must be 32 bytes long")
+	ErrUnknownKEK = errors.New("data key is wrapped by unknown master key")
+	ErrNoKeyring  = errors.New("value is encrypted, but storage has no master keys")
+	ErrDecrypt    = errors.New("error decrypting value")
+)
+
+// Keyring holds server master keys (KEK) wrapping per-user data keys (DEK).
+// New data keys are wrapped by the current master key, the others are kept to unwrap data keys until they are rewrapped
+type Keyring struct {
+	current string
+	keks    map[string]cipher.AEAD
+}
+
+// NewKeyring makes keyring with the current master key and the previous ones
+func NewKeyring(current []byte, previous ...[]byte) (*Keyring, error) {
+	k := &Keyring{
+		current: KEKID(current),
+		keks:    make(map[string]cipher.AEAD),
+	}
+
+	for _, key := range append([][]byte{current}, previous...) {
+		if len(key) != KEKSize {
+			return nil, ErrInvalidKEK
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+
+		k.keks[KEKID(key)] = aead
+	}
+
+	return k, nil
+}
+
+// KEKID identifies master key without revealing it
+func KEKID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// GenerateKEK makes a new random master key
+func GenerateKEK() ([]byte, error) {
+	key := make([]byte, KEKSize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// wrappedKey is a data key of the user sealed by the master key
+type wrappedKey struct {
+	KEKID string `json:"kek_id"`
+	Key   []byte `json:"key"`
+}
+
+func (k *Keyring) wrap(username, dek []byte) (*wrappedKey, error) {
+	sealed, err := seal(k.keks[k.current], dek, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wrappedKey{
+		KEKID: k.current,
+		Key:   sealed,
+	}, nil
+}
+
+func (k *Keyring) unwrap(username []byte, wrapped *wrappedKey) ([]byte, error) {
+	kek, ok := k.keks[wrapped.KEKID]
+	if !ok {
+		return nil, ErrUnknownKEK
+	}
+
+	return open(kek, wrapped.Key, username)
+}
+
+// dataKey returns cipher of the user data key, the key is generated on the first use
+func (b *BadgerStorage) dataKey(username []byte) (cipher.AEAD, error) {
+	if aead, ok := b.dataKeys.Load(string(username)); ok {
+		return aead.(cipher.AEAD), nil
+	}
+
+	var dek []byte
+
+	err := b.db.Update(func(txn *badger.Txn) error {
+		item, err := txn.Get(dataKeyPath(username))
+		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+
+		if err == nil {
+			wrapped := &wrappedKey{}
+			err = item.Value(func(v []byte) error {
+				return json.Unmarshal(v, wrapped)
+			})
+			if err != nil {
+				return err
+			}
+
+			dek, err = b.keyring.unwrap(username, wrapped)
+			return err
+		}
+
+		dek = make([]byte, KEKSize)
+		if _, err = rand.Read(dek); err != nil {
+			return err
+		}
+
+		wrapped, err := b.keyring.wrap(username, dek)
+		if err != nil {
+			return err
+		}
+
+		wrappedRaw, err := json.Marshal(wrapped)
+		if err != nil {
+			return err
+		}
+
+		return txn.Set(dataKeyPath(username), wrappedRaw)
+	})
+	if err != nil {
+		// data key was generated by concurrent request
+		if errors.Is(err, badger.ErrConflict) {
+			return b.dataKey(username)
+		}
+		return nil, err
+	}
+
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+
+	b.dataKeys.Store(string(username), aead)
+
+	return aead, nil
+}
+
+// sealEntry makes entry with the value encrypted by the user data key
+func (b *BadgerStorage) sealEntry(username, key, value []byte) (*badger.Entry, error) {
+	if b.keyring == nil {
+		return badger.NewEntry(key, value), nil
+	}
+
+	aead, err := b.dataKey(username)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := seal(aead, value, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return badger.NewEntry(key, sealed).WithMeta(metaEncrypted), nil
+}
+
+// openValue returns decrypted value of the item
+func (b *BadgerStorage) openValue(username []byte, item *badger.Item) ([]byte, error) {
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.UserMeta()&metaEncrypted == 0 {
+		return value, nil
+	}
+
+	if b.keyring == nil {
+		return nil, ErrNoKeyring
+	}
+
+	aead, err := b.dataKey(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(aead, value, username)
+}
+
+// RewrapDataKeys wraps data keys of all users by the current master key and returns how many keys were rewrapped.
+// Secrets are not re-encrypted, as the data keys themselves stay the same
+func (b *BadgerStorage) RewrapDataKeys(ctx context.Context) (int, error) {
+	prefix := []byte(dataKeys + "/")
+	stale := make([][]byte, 0)
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			wrapped := &wrappedKey{}
+			err := it.Item().Value(func(v []byte) error {
+				return json.Unmarshal(v, wrapped)
+			})
+			if err != nil {
+				return err
+			}
+
+			if wrapped.KEKID != b.keyring.current {
+				stale = append(stale, it.Item().KeyCopy(nil))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	rewrapped := 0
+
+	for start := 0; start < len(stale); start += rewrapBatchSize {
+		if err = ctx.Err(); err != nil {
+			return rewrapped, err
+		}
+
+		batch := stale[start:min(start+rewrapBatchSize, len(stale))]
+
+		err = b.db.Update(func(txn *badger.Txn) error {
+			for _, path := range batch {
+				if err := b.rewrapDataKey(txn, path); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return rewrapped, err
+		}
+
+		rewrapped += len(batch)
+	}
+
+	return rewrapped, nil
+}
+
+func (b *BadgerStorage) rewrapDataKey(txn *badger.Txn, path []byte) error {
+	username, err := hex.DecodeString(strings.TrimPrefix(string(path), dataKeys+"/"))
+	if err != nil {
+		return err
+	}
+
+	item, err := txn.Get(path)
+	if err != nil {
+		return err
+	}
+
+	wrapped := &wrappedKey{}
+	err = item.Value(func(v []byte) error {
+		return json.Unmarshal(v, wrapped)
+	})
+	if err != nil {
+		return err
+	}
+
+	dek, err := b.keyring.unwrap(username, wrapped)
+	if err != nil {
+		return err
+	}
+
+	if wrapped, err = b.keyring.wrap(username, dek); err != nil {
+		return err
+	}
+
+	wrappedRaw, err := json.Marshal(wrapped)
+	if err != nil {
+		return err
+	}
+
+	return txn.Set(path, wrappedRaw)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext, ad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, ad), nil
+}
+
+func open(aead cipher.AEAD, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], ad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
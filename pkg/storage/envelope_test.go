@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/nedovault/api"
+	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"testing"
+)
+
+func openTestDB(t *testing.T) *badger.DB {
+	t.Helper()
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatalf("badger.Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+func newTestStorage(t *testing.T, opts ...Option) *BadgerStorage {
+	t.Helper()
+
+	b := NewBadgerStorage(openTestDB(t), opts...)
+	if err := b.CreateAuthMeta(context.Background(), []byte("alice"), &auth.Meta{}); err != nil {
+		t.Fatalf("CreateAuthMeta() error = %v", err)
+	}
+
+	return b
+}
+
+func newTestKEK(t *testing.T) []byte {
+	t.Helper()
+
+	kek, err := GenerateKEK()
+	if err != nil {
+		t.Fatalf("GenerateKEK() error = %v", err)
+	}
+
+	return kek
+}
+
+func newTestKeyring(t *testing.T, current []byte, previous ...[]byte) *Keyring {
+	t.Helper()
+
+	keyring, err := NewKeyring(current, previous...)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	return keyring
+}
+
+func TestNewKeyring(t *testing.T) {
+	kek := make([]byte, KEKSize)
+
+	tests := []struct {
+		name     string
+		current  []byte
+		previous [][]byte
+		want     error
+	}{
+		{name: "current only", current: kek},
+		{name: "with previous", current: kek, previous: [][]byte{bytes.Repeat([]byte{1}, KEKSize)}},
+		{name: "short current", current: kek[:16], want: ErrInvalidKEK},
+		{name: "short previous", current: kek, previous: [][]byte{kek[:31]}, want: ErrInvalidKEK},
+		{name: "empty", want: ErrInvalidKEK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.current, tt.previous...); !errors.Is(err, tt.want) {
+				t.Errorf("NewKeyring() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestKEKRotation(t *testing.T) {
+	ctx := context.Background()
+	username := []byte("alice")
+	key := []byte("key")
+	content := []byte("sealed by the client")
+
+	oldKEK, newKEK := newTestKEK(t), newTestKEK(t)
+
+	b := newTestStorage(t, WithKeyring(newTestKeyring(t, oldKEK)))
+
+	err := b.AddSecret(ctx, username, &api.AddSecretRequest{
+		Key:        key,
+		Name:       []byte("name"),
+		SecretType: api.SecretType_TYPE_TEXT,
+		Secret:     &api.Secret{Secret: &api.Secret_Encrypted{Encrypted: content}},
+	})
+	if err != nil {
+		t.Fatalf("AddSecret() error = %v", err)
+	}
+
+	// values are encrypted at rest
+	err = b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			value, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if bytes.Contains(value, content) {
+				t.Errorf("value of %q is stored in plaintext", it.Item().Key())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// restarted storage has no cached data keys
+	restart := func(keyring *Keyring) *BadgerStorage {
+		if keyring == nil {
+			return NewBadgerStorage(b.db)
+		}
+		return NewBadgerStorage(b.db, WithKeyring(keyring))
+	}
+
+	// master key is rotated, the old one is kept until data keys are rewrapped
+	rotated := restart(newTestKeyring(t, newKEK, oldKEK))
+
+	rewrapped, err := rotated.RewrapDataKeys(ctx)
+	if err != nil || rewrapped != 1 {
+		t.Fatalf("RewrapDataKeys() = %d, %v, want 1 key rewrapped", rewrapped, err)
+	}
+
+	if rewrapped, err = rotated.RewrapDataKeys(ctx); err != nil || rewrapped != 0 {
+		t.Fatalf("RewrapDataKeys() again = %d, %v, want nothing to rewrap", rewrapped, err)
+	}
+
+	tests := []struct {
+		name    string
+		keyring *Keyring
+		want    error
+	}{
+		{name: "new and old master keys", keyring: newTestKeyring(t, newKEK, oldKEK)},
+		{name: "old master key dropped", keyring: newTestKeyring(t, newKEK)},
+		{name: "old master key only", keyring: newTestKeyring(t, oldKEK), want: ErrUnknownKEK},
+		{name: "unrelated master key", keyring: newTestKeyring(t, newTestKEK(t)), want: ErrUnknownKEK},
+		{name: "no keyring", want: ErrNoKeyring},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, _, err := restart(tt.keyring).GetSecret(ctx, username, key)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("GetSecret() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && !bytes.Equal(secret.GetEncrypted(), content) {
+				t.Errorf("GetSecret() = %q, want %q", secret.GetEncrypted(), content)
+			}
+		})
+	}
+}
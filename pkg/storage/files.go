@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/google/uuid"
+	"github.com/renatus-cartesius/metricserv/pkg/logger"
+	"github.com/renatus-cartesius/nedovault/api"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+)
+
+// ChunkFunc returns next chunk of the uploading file and io.EOF when there are no chunks left
+type ChunkFunc func() ([]byte, error)
+
+// UploadSecret writes file secret chunk by chunk, so the whole file is never kept in memory.
+// Chunks are stored under a new blob and the secret starts pointing to it only after the last chunk is written
+func (b *BadgerStorage) UploadSecret(ctx context.Context, username []byte, header *api.UploadSecretHeader, next ChunkFunc) error {
+	blobID := uuid.NewString()
+	chunksPrefix := secretsChunksPrefix(username, blobID)
+
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	var (
+		size  int64
+		index int
+	)
+
+	for {
+		chunk, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return b.abortUpload(username, blobID, err)
+		}
+
+		size += int64(len(chunk))
+		if b.maxFileSize > 0 && size > b.maxFileSize {
+			return b.abortUpload(username, blobID, ErrFileTooLarge)
+		}
+
+		entry, err := b.sealEntry(username, []byte(fmt.Sprintf("%s%016d", chunksPrefix, index)), chunk)
+		if err != nil {
+			return b.abortUpload(username, blobID, err)
+		}
+
+		if err = wb.SetEntry(entry); err != nil {
+			return b.abortUpload(username, blobID, err)
+		}
+		index++
+	}
+
+	if err := wb.Flush(); err != nil {
+		return b.abortUpload(username, blobID, err)
+	}
+
+	dataPath := secretDataPath(username, header.GetKey())
+	metadataPath := secretMetadataPath(username, header.GetKey())
+
+	var released []string
+
+	err := b.updateSecrets(username, func(txn *badger.Txn) error {
+		oldMeta, err := getSecretMeta(txn, metadataPath)
+		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+
+		switch {
+		case oldMeta == nil && header.GetRevision() != 0:
+			return ErrSecretNotFound
+		case oldMeta != nil && header.GetRevision() == 0:
+			return ErrSecretExists
+		case oldMeta != nil && oldMeta.GetRevision() != header.GetRevision():
+			return ErrRevisionMismatch
+		}
+
+		if oldMeta != nil {
+			if released, err = b.archiveVersion(txn, username, oldMeta); err != nil {
+				return err
+			}
+		}
+
+		secret := &api.Secret{
+			Secret: &api.Secret_File{
+				File: &api.File{
+					Filename:  header.GetFilename(),
+					Size:      size,
+					Encrypted: header.GetEncrypted(),
+				},
+			},
+		}
+
+		sMetadata := &api.SecretMeta{
+			Key:       header.GetKey(),
+			Name:      header.GetName(),
+			Timestamp: timestamppb.Now(),
+			Type:      api.SecretType_TYPE_FILE,
+			BlobId:    blobID,
+			Size:      size,
+			Revision:  oldMeta.GetRevision() + 1,
+		}
+
+		return b.putSecret(txn, username, dataPath, metadataPath, secret, sMetadata)
+	})
+	if err != nil {
+		if errors.Is(err, badger.ErrConflict) {
+			err = ErrRevisionMismatch
+		}
+		return b.abortUpload(username, blobID, err)
+	}
+
+	return b.releaseBlobs(username, header.GetKey(), released)
+}
+
+// ReadSecretChunks passes stored chunks of the file secret to send in the order they were uploaded
+func (b *BadgerStorage) ReadSecretChunks(ctx context.Context, username []byte, meta *api.SecretMeta, send func(chunk []byte) error) error {
+	prefix := secretsChunksPrefix(username, meta.GetBlobId())
+
+	return b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			chunk, err := b.openValue(username, it.Item())
+			if err != nil {
+				return err
+			}
+
+			if err = send(chunk); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (b *BadgerStorage) abortUpload(username []byte, blobID string, cause error) error {
+	if err := b.deleteBlob(username, blobID); err != nil {
+		logger.Log.Error(
+			"error cleaning up aborted upload",
+			zap.String("blob_id", blobID),
+			zap.Error(err),
+		)
+	}
+
+	return cause
+}
+
+func (b *BadgerStorage) deleteBlob(username []byte, blobID string) error {
+	prefix := secretsChunksPrefix(username, blobID)
+
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			if err := wb.Delete(it.Item().KeyCopy(nil)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return wb.Flush()
+}
+
+func getSecretMeta(txn *badger.Txn, metadataPath []byte) (*api.SecretMeta, error) {
+	item, err := txn.Get(metadataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	secretMeta := &api.SecretMeta{}
+
+	err = item.Value(func(v []byte) error {
+		return proto.Unmarshal(v, secretMeta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return secretMeta, nil
+}
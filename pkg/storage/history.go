@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/nedovault/api"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ListSecretVersions returns metadata of the stored previous versions of the secret, the newest first
+func (b *BadgerStorage) ListSecretVersions(ctx context.Context, username, key []byte) ([]*api.SecretMeta, error) {
+	versions := make([]*api.SecretMeta, 0)
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		if _, err := txn.Get(secretMetadataPath(username, key)); err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return ErrSecretNotFound
+			}
+			return err
+		}
+
+		return iterateVersions(txn, secretVersionsPrefix(username, key), true, func(item *badger.Item) error {
+			version, err := b.unmarshalVersion(username, item)
+			if err != nil {
+				return err
+			}
+
+			versions = append(versions, version.GetSecretMeta())
+			return nil
+		})
+	})
+
+	return versions, err
+}
+
+// GetSecretVersion returns the previous version of the secret with passed revision
+func (b *BadgerStorage) GetSecretVersion(ctx context.Context, username, key []byte, revision uint64) (*api.Secret, *api.SecretMeta, error) {
+	var version *api.GetSecretResponse
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(secretVersionPath(username, key, revision))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return ErrVersionNotFound
+			}
+			return err
+		}
+
+		version, err = b.unmarshalVersion(username, item)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return version.GetSecret(), version.GetSecretMeta(), nil
+}
+
+// RestoreSecretVersion makes a new revision of the secret with content of the previous version
+func (b *BadgerStorage) RestoreSecretVersion(ctx context.Context, username []byte, in *api.RestoreSecretVersionRequest) (*api.SecretMeta, error) {
+	dataPath := secretDataPath(username, in.GetKey())
+	metadataPath := secretMetadataPath(username, in.GetKey())
+
+	var (
+		sMetadata *api.SecretMeta
+		released  []string
+	)
+
+	err := b.updateSecrets(username, func(txn *badger.Txn) error {
+		currentMeta, err := getSecretMeta(txn, metadataPath)
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return ErrSecretNotFound
+			}
+			return err
+		}
+
+		if in.GetCurrentRevision() != 0 && currentMeta.GetRevision() != in.GetCurrentRevision() {
+			return ErrRevisionMismatch
+		}
+
+		item, err := txn.Get(secretVersionPath(username, in.GetKey(), in.GetRevision()))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return ErrVersionNotFound
+			}
+			return err
+		}
+
+		version, err := b.unmarshalVersion(username, item)
+		if err != nil {
+			return err
+		}
+
+		if released, err = b.archiveVersion(txn, username, currentMeta); err != nil {
+			return err
+		}
+
+		sMetadata = proto.Clone(version.GetSecretMeta()).(*api.SecretMeta)
+		sMetadata.Timestamp = timestamppb.Now()
+		sMetadata.Revision = currentMeta.GetRevision() + 1
+
+		return b.putSecret(txn, username, dataPath, metadataPath, version.GetSecret(), sMetadata)
+	})
+	if err != nil {
+		if errors.Is(err, badger.ErrConflict) {
+			return nil, ErrRevisionMismatch
+		}
+		return nil, err
+	}
+
+	if err = b.releaseBlobs(username, in.GetKey(), released); err != nil {
+		return nil, err
+	}
+
+	return sMetadata, nil
+}
+
+// archiveVersion saves the current state of the secret to its history and evicts versions exceeding history depth.
+// Returned blobs may become unused and should be released after the transaction is committed
+func (b *BadgerStorage) archiveVersion(txn *badger.Txn, username []byte, meta *api.SecretMeta) ([]string, error) {
+	released := make([]string, 0)
+	if meta.GetBlobId() != "" {
+		released = append(released, meta.GetBlobId())
+	}
+
+	if b.historyDepth <= 0 {
+		return released, nil
+	}
+
+	item, err := txn.Get(secretDataPath(username, meta.GetKey()))
+	if err != nil {
+		return nil, err
+	}
+
+	sDataRaw, err := b.openValue(username, item)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &api.Secret{}
+	if err = proto.Unmarshal(sDataRaw, secret); err != nil {
+		return nil, err
+	}
+
+	versionRaw, err := proto.Marshal(&api.GetSecretResponse{
+		Secret:     secret,
+		SecretMeta: meta,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := b.sealEntry(username, secretVersionPath(username, meta.GetKey(), meta.GetRevision()), versionRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = txn.SetEntry(entry); err != nil {
+		return nil, err
+	}
+
+	// newest versions go first, so everything after the depth is evicted
+	kept := 0
+	evicted := make([][]byte, 0)
+
+	err = iterateVersions(txn, secretVersionsPrefix(username, meta.GetKey()), true, func(item *badger.Item) error {
+		kept++
+		if kept <= b.historyDepth {
+			return nil
+		}
+
+		version, err := b.unmarshalVersion(username, item)
+		if err != nil {
+			return err
+		}
+
+		if blobID := version.GetSecretMeta().GetBlobId(); blobID != "" {
+			released = append(released, blobID)
+		}
+
+		evicted = append(evicted, item.KeyCopy(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range evicted {
+		if err = txn.Delete(k); err != nil {
+			return nil, err
+		}
+	}
+
+	return released, nil
+}
+
+// releaseBlobs deletes chunks of the blobs no longer referenced by the secret or its versions
+func (b *BadgerStorage) releaseBlobs(username, key []byte, blobIDs []string) error {
+	if len(blobIDs) == 0 {
+		return nil
+	}
+
+	unused := make(map[string]struct{}, len(blobIDs))
+	for _, blobID := range blobIDs {
+		unused[blobID] = struct{}{}
+	}
+
+	// blob can be referenced by the secret, its versions and the same ones in the trash
+	err := b.db.View(func(txn *badger.Txn) error {
+		for _, metadataPath := range [][]byte{secretMetadataPath(username, key), trashMetadataPath(username, key)} {
+			meta, err := getSecretMeta(txn, metadataPath)
+			if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+				return err
+			}
+			delete(unused, meta.GetBlobId())
+		}
+
+		for _, prefix := range [][]byte{secretVersionsPrefix(username, key), trashVersionsPrefix(username, key)} {
+			err := iterateVersions(txn, prefix, false, func(item *badger.Item) error {
+				version, err := b.unmarshalVersion(username, item)
+				if err != nil {
+					return err
+				}
+
+				delete(unused, version.GetSecretMeta().GetBlobId())
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for blobID := range unused {
+		if err = b.deleteBlob(username, blobID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// iterateVersions calls fn for every version of the secret stored under the prefix
+func iterateVersions(txn *badger.Txn, prefix []byte, newestFirst bool, fn func(item *badger.Item) error) error {
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = prefix
+	opts.Reverse = newestFirst
+
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	seek := prefix
+	if newestFirst {
+		seek = append(append([]byte{}, prefix...), 0xff)
+	}
+
+	for it.Seek(seek); it.ValidForPrefix(prefix); it.Next() {
+		// skip versions of the secrets which keys start with the key of this one
+		if len(it.Item().Key()) != len(prefix)+revisionWidth {
+			continue
+		}
+
+		if err := fn(it.Item()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *BadgerStorage) unmarshalVersion(username []byte, item *badger.Item) (*api.GetSecretResponse, error) {
+	versionRaw, err := b.openValue(username, item)
+	if err != nil {
+		return nil, err
+	}
+
+	version := &api.GetSecretResponse{}
+	if err = proto.Unmarshal(versionRaw, version); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
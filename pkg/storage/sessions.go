@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"time"
+)
+
+// PutSession saves the session, the record expires together with the last refresh token of the session.
+// Nothing is saved if all tokens of the session were revoked
+func (b *BadgerStorage) PutSession(ctx context.Context, username []byte, session *auth.Session) error {
+	return retryConflicts(func() error {
+		return b.db.Update(func(txn *badger.Txn) error {
+			// the session revoked concurrently is not started again, its tokens are checked in the same transaction
+			tokens, err := getTokens(txn, username)
+			if err != nil {
+				return err
+			}
+
+			for _, token := range tokens {
+				if token.Family == session.ID && !token.Revoked {
+					return putSession(txn, username, session)
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+// TouchSession replaces record of the existing session. Nothing is saved if the session was revoked
+// or changed by concurrent request, as the record is updated at most once a minute anyway
+func (b *BadgerStorage) TouchSession(ctx context.Context, username []byte, session *auth.Session) error {
+	err := b.db.Update(func(txn *badger.Txn) error {
+		if _, err := getSession(txn, username, session.ID); err != nil {
+			return err
+		}
+
+		return putSession(txn, username, session)
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) || errors.Is(err, badger.ErrConflict) {
+		return nil
+	}
+
+	return err
+}
+
+// GetSession returns the session or nil if there is no such active session
+func (b *BadgerStorage) GetSession(ctx context.Context, username []byte, id string) (*auth.Session, error) {
+	var session *auth.Session
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		var err error
+		session, err = getSession(txn, username, id)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+
+	return session, err
+}
+
+// ListSessions returns active sessions of the user, expired ones are dropped by badger
+func (b *BadgerStorage) ListSessions(ctx context.Context, username []byte) ([]*auth.Session, error) {
+	prefix := authSessionsPrefix(username)
+	sessions := make([]*auth.Session, 0)
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			session := &auth.Session{}
+
+			err := it.Item().Value(func(v []byte) error {
+				return json.Unmarshal(v, session)
+			})
+			if err != nil {
+				return err
+			}
+
+			sessions = append(sessions, session)
+		}
+
+		return nil
+	})
+
+	return sessions, err
+}
+
+// deleteSessions drops all sessions of the user within the transaction
+func deleteSessions(txn *badger.Txn, username []byte) error {
+	prefix := authSessionsPrefix(username)
+	keys := make([][]byte, 0)
+
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+
+	it := txn.NewIterator(opts)
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		keys = append(keys, it.Item().KeyCopy(nil))
+	}
+	it.Close()
+
+	for _, key := range keys {
+		if err := txn.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getSession(txn *badger.Txn, username []byte, id string) (*auth.Session, error) {
+	item, err := txn.Get(authSessionPath(username, id))
+	if err != nil {
+		return nil, err
+	}
+
+	session := &auth.Session{}
+
+	err = item.Value(func(v []byte) error {
+		return json.Unmarshal(v, session)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func putSession(txn *badger.Txn, username []byte, session *auth.Session) error {
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	sessionRaw, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	return txn.SetEntry(badger.NewEntry(authSessionPath(username, session.ID), sessionRaw).WithTTL(ttl))
+}
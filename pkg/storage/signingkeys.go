@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/nedovault/internal/auth"
+)
+
+// signingKeyRecord is a signing key saved with the private key wrapped by the master key.
+// Keys saved before or without the keyring keep the private key as is
+type signingKeyRecord struct {
+	auth.SigningKey
+	WrappedPrivate *wrappedKey `json:"wrapped_private,omitempty"`
+}
+
+// ListSigningKeys returns all token signing keys, including the retired ones
+func (b *BadgerStorage) ListSigningKeys(ctx context.Context) ([]*auth.SigningKey, error) {
+	prefix := []byte(signingKeys + "/")
+	keys := make([]*auth.SigningKey, 0)
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			record, err := getSigningKeyRecord(it.Item())
+			if err != nil {
+				return err
+			}
+
+			if record.WrappedPrivate != nil {
+				if b.keyring == nil {
+					return ErrNoKeyring
+				}
+
+				if record.Private, err = b.keyring.unwrap(signingKeyPath(record.ID), record.WrappedPrivate); err != nil {
+					return err
+				}
+			}
+
+			keys = append(keys, &record.SigningKey)
+		}
+
+		return nil
+	})
+
+	return keys, err
+}
+
+// PutSigningKey saves the token signing key, the private key is wrapped by the current master key
+func (b *BadgerStorage) PutSigningKey(ctx context.Context, key *auth.SigningKey) error {
+	record, err := b.wrapSigningKey(key)
+	if err != nil {
+		return err
+	}
+
+	keyRaw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(signingKeyPath(key.ID), keyRaw)
+	})
+}
+
+func (b *BadgerStorage) DeleteSigningKey(ctx context.Context, id string) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(signingKeyPath(id))
+	})
+}
+
+// RewrapSigningKeys wraps private keys of the token signing keys by the current master key, including the keys
+// saved before they were wrapped, and returns how many keys were rewrapped
+func (b *BadgerStorage) RewrapSigningKeys(ctx context.Context) (int, error) {
+	if b.keyring == nil {
+		return 0, ErrNoKeyring
+	}
+
+	rewrapped := 0
+
+	err := retryConflicts(func() error {
+		rewrapped = 0
+
+		return b.db.Update(func(txn *badger.Txn) error {
+			stale, err := b.staleSigningKeys(txn)
+			if err != nil {
+				return err
+			}
+
+			for _, record := range stale {
+				if record.WrappedPrivate != nil {
+					private, err := b.keyring.unwrap(signingKeyPath(record.ID), record.WrappedPrivate)
+					if err != nil {
+						return err
+					}
+					record.Private = private
+				}
+
+				rewrappedRecord, err := b.wrapSigningKey(&record.SigningKey)
+				if err != nil {
+					return err
+				}
+
+				keyRaw, err := json.Marshal(rewrappedRecord)
+				if err != nil {
+					return err
+				}
+
+				if err = txn.Set(signingKeyPath(record.ID), keyRaw); err != nil {
+					return err
+				}
+
+				rewrapped++
+			}
+
+			return nil
+		})
+	})
+
+	return rewrapped, err
+}
+
+// staleSigningKeys returns records of the signing keys not wrapped by the current master key
+func (b *BadgerStorage) staleSigningKeys(txn *badger.Txn) ([]*signingKeyRecord, error) {
+	prefix := []byte(signingKeys + "/")
+	stale := make([]*signingKeyRecord, 0)
+
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		record, err := getSigningKeyRecord(it.Item())
+		if err != nil {
+			return nil, err
+		}
+
+		if record.WrappedPrivate == nil || record.WrappedPrivate.KEKID != b.keyring.current {
+			stale = append(stale, record)
+		}
+	}
+
+	return stale, nil
+}
+
+// wrapSigningKey makes record of the signing key, the private key is bound to the path of the record
+func (b *BadgerStorage) wrapSigningKey(key *auth.SigningKey) (*signingKeyRecord, error) {
+	record := &signingKeyRecord{
+		SigningKey: *key,
+	}
+
+	if b.keyring == nil {
+		return record, nil
+	}
+
+	wrapped, err := b.keyring.wrap(signingKeyPath(key.ID), key.Private)
+	if err != nil {
+		return nil, err
+	}
+
+	record.Private = nil
+	record.WrappedPrivate = wrapped
+
+	return record, nil
+}
+
+func getSigningKeyRecord(item *badger.Item) (*signingKeyRecord, error) {
+	record := &signingKeyRecord{}
+
+	err := item.Value(func(v []byte) error {
+		return json.Unmarshal(v, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
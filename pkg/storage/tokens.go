@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"time"
+)
+
+// AppendTokens saves records of the issued tokens and drops records of the expired ones
+func (b *BadgerStorage) AppendTokens(ctx context.Context, username []byte, tokens ...*auth.TokenMeta) error {
+	expired, err := b.expiredTokens(username, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		for _, path := range expired {
+			if err := txn.Delete(path); err != nil {
+				return err
+			}
+		}
+
+		for _, token := range tokens {
+			if err := putToken(txn, username, token); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetToken returns record of the issued token or nil if there is no such token
+func (b *BadgerStorage) GetToken(ctx context.Context, username []byte, id string) (*auth.TokenMeta, error) {
+	var token *auth.TokenMeta
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		var err error
+		token, err = getToken(txn, username, id)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+
+	return token, err
+}
+
+// RevokeToken marks the token revoked, the record is kept until the token expires
+func (b *BadgerStorage) RevokeToken(ctx context.Context, username []byte, id string) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		token, err := getToken(txn, username, id)
+		if err != nil {
+			return err
+		}
+
+		token.Revoked = true
+
+		return putToken(txn, username, token)
+	})
+}
+
+// RevokeAllTokens marks all tokens of the user revoked and drops all sessions.
+// Tokens are listed in the same transaction, so the token rotated concurrently is revoked too
+func (b *BadgerStorage) RevokeAllTokens(ctx context.Context, username []byte) error {
+	return retryConflicts(func() error {
+		return b.db.Update(func(txn *badger.Txn) error {
+			if err := deleteSessions(txn, username); err != nil {
+				return err
+			}
+
+			return revokeTokens(txn, username, func(token *auth.TokenMeta) bool {
+				return true
+			})
+		})
+	})
+}
+
+// RevokeFamily revokes all tokens issued within one login session and drops the session.
+// Tokens are listed in the same transaction, so the token rotated concurrently is revoked too
+func (b *BadgerStorage) RevokeFamily(ctx context.Context, username []byte, family string) error {
+	return retryConflicts(func() error {
+		return b.db.Update(func(txn *badger.Txn) error {
+			if err := txn.Delete(authSessionPath(username, family)); err != nil {
+				return err
+			}
+
+			return revokeTokens(txn, username, func(token *auth.TokenMeta) bool {
+				return token.Family == family
+			})
+		})
+	})
+}
+
+// RotateToken marks the refresh token used and saves records of the tokens issued instead of it.
+// Records of the expired tokens and of the refresh tokens rotated before in the same session are dropped,
+// so the records of a long session don't pile up between logins
+func (b *BadgerStorage) RotateToken(ctx context.Context, username []byte, id string, tokens ...*auth.TokenMeta) error {
+	// records are listed outside of the transaction, reading them all would make concurrent rotations of other sessions conflict
+	stale, err := b.staleTokens(username, id, time.Now())
+	if err != nil {
+		return err
+	}
+
+	err = b.db.Update(func(txn *badger.Txn) error {
+		used, err := getToken(txn, username, id)
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return auth.ErrRevokedToken
+			}
+			return err
+		}
+
+		if used.Revoked {
+			return auth.ErrTokenReused
+		}
+
+		used.Revoked = true
+
+		if err = putToken(txn, username, used); err != nil {
+			return err
+		}
+
+		for _, path := range stale {
+			if err = txn.Delete(path); err != nil {
+				return err
+			}
+		}
+
+		for _, token := range tokens {
+			if err = putToken(txn, username, token); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	// the token was used by concurrent request, so it is reused
+	if errors.Is(err, badger.ErrConflict) {
+		return auth.ErrTokenReused
+	}
+
+	return err
+}
+
+func (b *BadgerStorage) listTokens(username []byte) ([]*auth.TokenMeta, error) {
+	var tokens []*auth.TokenMeta
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		var err error
+		tokens, err = getTokens(txn, username)
+		return err
+	})
+
+	return tokens, err
+}
+
+// revokeTokens marks revoked the tokens of the user matched by filter
+func revokeTokens(txn *badger.Txn, username []byte, filter func(token *auth.TokenMeta) bool) error {
+	tokens, err := getTokens(txn, username)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if token.Revoked || !filter(token) {
+			continue
+		}
+
+		token.Revoked = true
+
+		if err = putToken(txn, username, token); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getTokens(txn *badger.Txn, username []byte) ([]*auth.TokenMeta, error) {
+	prefix := authTokensPrefix(username)
+	tokens := make([]*auth.TokenMeta, 0)
+
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		token := &auth.TokenMeta{}
+
+		err := it.Item().Value(func(v []byte) error {
+			return json.Unmarshal(v, token)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+func (b *BadgerStorage) expiredTokens(username []byte, now time.Time) ([][]byte, error) {
+	tokens, err := b.listTokens(username)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := make([][]byte, 0)
+	for _, token := range tokens {
+		if now.After(token.ExpiresAt) {
+			expired = append(expired, authTokenPath(username, token.ID))
+		}
+	}
+
+	return expired, nil
+}
+
+// staleTokens returns paths of the expired token records and of the refresh tokens rotated in the session of the token id.
+// Reuse of the dropped refresh token is still detected by the session in its claims
+func (b *BadgerStorage) staleTokens(username []byte, id string, now time.Time) ([][]byte, error) {
+	tokens, err := b.listTokens(username)
+	if err != nil {
+		return nil, err
+	}
+
+	family := ""
+	for _, token := range tokens {
+		if token.ID == id {
+			family = token.Family
+		}
+	}
+
+	stale := make([][]byte, 0)
+	for _, token := range tokens {
+		rotated := family != "" && token.Family == family && token.Type == auth.TokenRefresh && token.Revoked && token.ID != id
+		if rotated || now.After(token.ExpiresAt) {
+			stale = append(stale, authTokenPath(username, token.ID))
+		}
+	}
+
+	return stale, nil
+}
+
+func getToken(txn *badger.Txn, username []byte, id string) (*auth.TokenMeta, error) {
+	item, err := txn.Get(authTokenPath(username, id))
+	if err != nil {
+		return nil, err
+	}
+
+	token := &auth.TokenMeta{}
+
+	err = item.Value(func(v []byte) error {
+		return json.Unmarshal(v, token)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func putToken(txn *badger.Txn, username []byte, token *auth.TokenMeta) error {
+	tokenRaw, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	return txn.Set(authTokenPath(username, token.ID), tokenRaw)
+}
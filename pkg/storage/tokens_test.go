@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/renatus-cartesius/nedovault/internal/auth"
+	"testing"
+	"time"
+)
+
+func testToken(id, family, tokenType string, ttl time.Duration) *auth.TokenMeta {
+	now := time.Now()
+
+	return &auth.TokenMeta{
+		ID:        id,
+		Family:    family,
+		Type:      tokenType,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
+
+func TestRotateTokenPrunes(t *testing.T) {
+	ctx := context.Background()
+	username := []byte("alice")
+
+	b := newTestStorage(t)
+
+	// other session of the user with a rotated refresh token, its records are kept
+	other := []*auth.TokenMeta{
+		testToken("other-used", "other", auth.TokenRefresh, time.Hour),
+		testToken("other-refresh", "other", auth.TokenRefresh, time.Hour),
+	}
+	if err := b.AppendTokens(ctx, username, other...); err != nil {
+		t.Fatalf("AppendTokens() error = %v", err)
+	}
+	if err := b.RotateToken(ctx, username, "other-used"); err != nil {
+		t.Fatalf("RotateToken() error = %v", err)
+	}
+
+	if err := b.AppendTokens(ctx, username, testToken("refresh-0", "session", auth.TokenRefresh, time.Hour)); err != nil {
+		t.Fatalf("AppendTokens() error = %v", err)
+	}
+
+	const refreshes = 50
+
+	for i := 1; i <= refreshes; i++ {
+		// access token is expired by the next refresh, as the client refreshes once it expires
+		issued := []*auth.TokenMeta{
+			testToken(fmt.Sprintf("access-%d", i), "session", auth.TokenAccess, 0),
+			testToken(fmt.Sprintf("refresh-%d", i), "session", auth.TokenRefresh, time.Hour),
+		}
+
+		if err := b.RotateToken(ctx, username, fmt.Sprintf("refresh-%d", i-1), issued...); err != nil {
+			t.Fatalf("refresh %d: RotateToken() error = %v", i, err)
+		}
+
+		tokens, err := b.listTokens(username)
+		if err != nil {
+			t.Fatalf("listTokens() error = %v", err)
+		}
+
+		// the used refresh token and the issued pair of the session, two records of the other session
+		if len(tokens) > 5 {
+			t.Fatalf("refresh %d: %d token records are kept, want at most 5", i, len(tokens))
+		}
+	}
+
+	// the refresh token used last is kept to detect its reuse
+	last := fmt.Sprintf("refresh-%d", refreshes-1)
+	if err := b.RotateToken(ctx, username, last); !errors.Is(err, auth.ErrTokenReused) {
+		t.Errorf("RotateToken(%s) error = %v, want %v", last, err, auth.ErrTokenReused)
+	}
+
+	for _, id := range []string{"other-used", "other-refresh"} {
+		token, err := b.GetToken(ctx, username, id)
+		if err != nil || token == nil {
+			t.Errorf("GetToken(%s) = %v, %v, record of the other session is dropped", id, token, err)
+		}
+	}
+}
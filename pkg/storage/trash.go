@@ -0,0 +1,418 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/renatus-cartesius/metricserv/pkg/logger"
+	"github.com/renatus-cartesius/nedovault/api"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// DeleteSecret moves the secret with its history to the trash, where it is kept until restored or purged.
+// The revision is checked unless it is zero. Previously trashed secret with the same key is purged
+func (b *BadgerStorage) DeleteSecret(ctx context.Context, username []byte, in *api.DeleteSecretRequest) error {
+	key := in.GetKey()
+
+	var released []string
+
+	err := b.updateSecrets(username, func(txn *badger.Txn) error {
+		meta, err := getSecretMeta(txn, secretMetadataPath(username, key))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return ErrSecretNotFound
+			}
+			return err
+		}
+
+		if in.GetRevision() != 0 && meta.GetRevision() != in.GetRevision() {
+			return ErrRevisionMismatch
+		}
+
+		if released, err = b.purgeTrashed(txn, username, key); err != nil && !errors.Is(err, ErrSecretNotFound) {
+			return err
+		}
+
+		now := time.Now()
+		meta.DeletedAt = timestamppb.New(now)
+
+		if err = moveSecret(txn, secretDataPath(username, key), trashDataPath(username, key), trashMetadataPath(username, key), meta); err != nil {
+			return err
+		}
+		if err = txn.Delete(secretMetadataPath(username, key)); err != nil {
+			return err
+		}
+
+		if err = moveVersions(txn, secretVersionsPrefix(username, key), trashVersionsPrefix(username, key)); err != nil {
+			return err
+		}
+
+		if err = recordDelete(txn, username, key); err != nil {
+			return err
+		}
+
+		return txn.Set(trashIndexPath(username, key, now), nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	return b.releaseBlobs(username, key, released)
+}
+
+// RestoreSecret moves the secret with its history back from the trash and returns its new metadata
+func (b *BadgerStorage) RestoreSecret(ctx context.Context, username, key []byte) (*api.SecretMeta, error) {
+	var sMetadata *api.SecretMeta
+
+	err := b.updateSecrets(username, func(txn *badger.Txn) error {
+		meta, err := getSecretMeta(txn, trashMetadataPath(username, key))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return ErrSecretNotFound
+			}
+			return err
+		}
+
+		_, err = txn.Get(secretMetadataPath(username, key))
+		if err == nil {
+			return ErrSecretExists
+		}
+		if !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+
+		deletedAt := meta.GetDeletedAt().AsTime()
+
+		sMetadata = meta
+		sMetadata.DeletedAt = nil
+		sMetadata.Timestamp = timestamppb.Now()
+		sMetadata.Revision = meta.GetRevision() + 1
+
+		if err = moveSecret(txn, trashDataPath(username, key), secretDataPath(username, key), secretMetadataPath(username, key), sMetadata); err != nil {
+			return err
+		}
+		if err = txn.Delete(trashMetadataPath(username, key)); err != nil {
+			return err
+		}
+
+		if err = moveVersions(txn, trashVersionsPrefix(username, key), secretVersionsPrefix(username, key)); err != nil {
+			return err
+		}
+
+		if err = recordUpsert(txn, username, sMetadata); err != nil {
+			return err
+		}
+
+		return txn.Delete(trashIndexPath(username, key, deletedAt))
+	})
+	if err != nil {
+		if errors.Is(err, badger.ErrConflict) {
+			return nil, ErrRevisionMismatch
+		}
+		return nil, err
+	}
+
+	return sMetadata, nil
+}
+
+// PurgeSecret permanently deletes the trashed secret with its history
+func (b *BadgerStorage) PurgeSecret(ctx context.Context, username, key []byte) error {
+	var released []string
+
+	err := b.updateSecrets(username, func(txn *badger.Txn) error {
+		var err error
+		released, err = b.purgeTrashed(txn, username, key)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return b.releaseBlobs(username, key, released)
+}
+
+// ListTrash returns metadata of the trashed secrets
+func (b *BadgerStorage) ListTrash(ctx context.Context, username []byte) ([]*api.SecretMeta, error) {
+	prefix := trashMetadataPrefix(username)
+	trashed := make([]*api.SecretMeta, 0)
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			meta := &api.SecretMeta{}
+
+			err := it.Item().Value(func(v []byte) error {
+				return proto.Unmarshal(v, meta)
+			})
+			if err != nil {
+				return err
+			}
+
+			trashed = append(trashed, meta)
+		}
+
+		return nil
+	})
+
+	return trashed, err
+}
+
+// PurgeTrash permanently deletes secrets of all users trashed before the passed time and returns how many were purged
+func (b *BadgerStorage) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	prefix := []byte(trashIndex + "/")
+	until := trashIndexPath(nil, nil, before)
+
+	expired := make([][]byte, 0)
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			indexKey := it.Item().KeyCopy(nil)
+			if bytes.Compare(indexKey, until) >= 0 {
+				break
+			}
+
+			expired = append(expired, indexKey)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, indexKey := range expired {
+		if err = ctx.Err(); err != nil {
+			return purged, err
+		}
+
+		ok, err := b.purgeExpired(indexKey, before)
+		if err != nil {
+			logger.Log.Error(
+				"error purging trashed secret",
+				zap.ByteString("index", indexKey),
+				zap.Error(err),
+			)
+			continue
+		}
+		if ok {
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// RunTrashPurger periodically purges secrets kept in the trash longer than retention until ctx is done
+func (b *BadgerStorage) RunTrashPurger(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := b.PurgeTrash(ctx, time.Now().Add(-retention))
+		if err != nil {
+			logger.Log.Error(
+				"error purging trash",
+				zap.Error(err),
+			)
+		}
+		if purged > 0 {
+			logger.Log.Info(
+				"purged expired secrets from trash",
+				zap.Int("count", purged),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeExpired purges the secret referenced by the trash index entry if it is still trashed before the passed time.
+// Index entries without trashed secret are just deleted
+func (b *BadgerStorage) purgeExpired(indexKey []byte, before time.Time) (bool, error) {
+	username, key, err := parseTrashIndex(indexKey)
+	if err != nil {
+		return false, b.db.Update(func(txn *badger.Txn) error {
+			return txn.Delete(indexKey)
+		})
+	}
+
+	var (
+		purged   bool
+		released []string
+	)
+
+	err = b.updateSecrets(username, func(txn *badger.Txn) error {
+		meta, err := getSecretMeta(txn, trashMetadataPath(username, key))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return txn.Delete(indexKey)
+			}
+			return err
+		}
+
+		// the secret was restored and trashed again after the index was read
+		if !meta.GetDeletedAt().AsTime().Before(before) {
+			return nil
+		}
+
+		purged = true
+		released, err = b.purgeTrashed(txn, username, key)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return purged, b.releaseBlobs(username, key, released)
+}
+
+// purgeTrashed deletes the trashed secret, its history, index entry and the change deleting it.
+// Returned blobs may become unused and should be released after the transaction is committed
+func (b *BadgerStorage) purgeTrashed(txn *badger.Txn, username, key []byte) ([]string, error) {
+	meta, err := getSecretMeta(txn, trashMetadataPath(username, key))
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil, ErrSecretNotFound
+		}
+		return nil, err
+	}
+
+	released := make([]string, 0)
+	if meta.GetBlobId() != "" {
+		released = append(released, meta.GetBlobId())
+	}
+
+	versions := make([][]byte, 0)
+
+	err = iterateVersions(txn, trashVersionsPrefix(username, key), false, func(item *badger.Item) error {
+		version, err := b.unmarshalVersion(username, item)
+		if err != nil {
+			return err
+		}
+
+		if blobID := version.GetSecretMeta().GetBlobId(); blobID != "" {
+			released = append(released, blobID)
+		}
+
+		versions = append(versions, item.KeyCopy(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range append(versions,
+		trashDataPath(username, key),
+		trashMetadataPath(username, key),
+		trashIndexPath(username, key, meta.GetDeletedAt().AsTime()),
+	) {
+		if err = txn.Delete(k); err != nil {
+			return nil, err
+		}
+	}
+
+	return released, compactDelete(txn, username, key)
+}
+
+// moveSecret moves secret data to dataPath and writes its metadata to metadataPath
+func moveSecret(txn *badger.Txn, fromDataPath, dataPath, metadataPath []byte, meta *api.SecretMeta) error {
+	item, err := txn.Get(fromDataPath)
+	if err != nil {
+		return err
+	}
+
+	sDataRaw, err := item.ValueCopy(nil)
+	if err != nil {
+		return err
+	}
+
+	if err = txn.Delete(fromDataPath); err != nil {
+		return err
+	}
+
+	// value is moved as is, so it stays encrypted by the same data key
+	if err = txn.SetEntry(badger.NewEntry(dataPath, sDataRaw).WithMeta(item.UserMeta())); err != nil {
+		return err
+	}
+
+	sMetadataRaw, err := proto.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	return txn.Set(metadataPath, sMetadataRaw)
+}
+
+// moveVersions moves all versions of the secret from one history prefix to another
+func moveVersions(txn *badger.Txn, from, to []byte) error {
+	type version struct {
+		key, value []byte
+		userMeta   byte
+	}
+
+	versions := make([]version, 0)
+
+	err := iterateVersions(txn, from, false, func(item *badger.Item) error {
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+
+		versions = append(versions, version{key: item.KeyCopy(nil), value: value, userMeta: item.UserMeta()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		if err = txn.Delete(v.key); err != nil {
+			return err
+		}
+
+		entry := badger.NewEntry(append(append([]byte{}, to...), v.key[len(from):]...), v.value).WithMeta(v.userMeta)
+		if err = txn.SetEntry(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseTrashIndex(indexKey []byte) ([]byte, []byte, error) {
+	parts := bytes.Split(indexKey, []byte("/"))
+	if len(parts) != 4 {
+		return nil, nil, fmt.Errorf("unexpected trash index format")
+	}
+
+	username, err := hex.DecodeString(string(parts[2]))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := hex.DecodeString(string(parts[3]))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return username, key, nil
+}
@@ -1,6 +1,30 @@
 package storage
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"github.com/dgraph-io/badger/v4"
+	"time"
+)
+
+// revisionWidth is a length of zero padded revision in keys, so they are sorted by revision
+const revisionWidth = 20
+
+// conflictRetries is how many times a transaction conflicting with a concurrent one is retried
+const conflictRetries = 16
+
+// retryConflicts runs the transaction again while it conflicts with concurrent ones, up to conflictRetries times
+func retryConflicts(fn func() error) error {
+	var err error
+
+	for range conflictRetries {
+		if err = fn(); !errors.Is(err, badger.ErrConflict) {
+			return err
+		}
+	}
+
+	return err
+}
 
 func secretsDataPrefix(username []byte) []byte {
 	return []byte(fmt.Sprintf("%s/%s", username, secretsData))
@@ -13,3 +37,97 @@ func secretsMetadataPrefix(username []byte) []byte {
 func authMetadataPrefix(username []byte) []byte {
 	return []byte(fmt.Sprintf("%s/%s", username, authMetadata))
 }
+
+func secretsChunksPrefix(username []byte, blobID string) []byte {
+	return []byte(fmt.Sprintf("%s/%s/%s/", username, secretsChunks, blobID))
+}
+
+func secretDataPath(username, key []byte) []byte {
+	return []byte(fmt.Sprintf("%s/%s", secretsDataPrefix(username), key))
+}
+
+func secretMetadataPath(username, key []byte) []byte {
+	return []byte(fmt.Sprintf("%s/%s", secretsMetadataPrefix(username), key))
+}
+
+func secretVersionsPrefix(username, key []byte) []byte {
+	return []byte(fmt.Sprintf("%s/%s/%s/", username, secretsHistory, key))
+}
+
+func secretVersionPath(username, key []byte, revision uint64) []byte {
+	return []byte(fmt.Sprintf("%s%0*d", secretVersionsPrefix(username, key), revisionWidth, revision))
+}
+
+func trashDataPath(username, key []byte) []byte {
+	return []byte(fmt.Sprintf("%s/%s/%s", username, trashData, key))
+}
+
+func trashMetadataPrefix(username []byte) []byte {
+	return []byte(fmt.Sprintf("%s/%s/", username, trashMetadata))
+}
+
+func trashMetadataPath(username, key []byte) []byte {
+	return []byte(fmt.Sprintf("%s%s", trashMetadataPrefix(username), key))
+}
+
+func trashVersionsPrefix(username, key []byte) []byte {
+	return []byte(fmt.Sprintf("%s/%s/%s/", username, trashHistory, key))
+}
+
+// dataKeyPath is a global key, so data keys of all users can be rewrapped without scanning their secrets
+func dataKeyPath(username []byte) []byte {
+	return []byte(fmt.Sprintf("%s/%x", dataKeys, username))
+}
+
+// trashIndexPath is a global key ordered by deletion time, so the purger finds expired secrets of all users.
+// Username and key are hex encoded to be parsed back unambiguously
+func trashIndexPath(username, key []byte, deletedAt time.Time) []byte {
+	return []byte(fmt.Sprintf("%s/%020d/%x/%x", trashIndex, deletedAt.UnixNano(), username, key))
+}
+
+func authTokensPrefix(username []byte) []byte {
+	return []byte(fmt.Sprintf("%s/%s/", username, authTokens))
+}
+
+func authTokenPath(username []byte, id string) []byte {
+	return []byte(fmt.Sprintf("%s%s", authTokensPrefix(username), id))
+}
+
+func authSessionsPrefix(username []byte) []byte {
+	return []byte(fmt.Sprintf("%s/%s/", username, authSessions))
+}
+
+func authSessionPath(username []byte, id string) []byte {
+	return []byte(fmt.Sprintf("%s%s", authSessionsPrefix(username), id))
+}
+
+// loginAttemptsPath is a global key, as attempts are counted for peer addresses as well as usernames
+func loginAttemptsPath(key []byte) []byte {
+	return []byte(fmt.Sprintf("%s/%x", loginAttempts, key))
+}
+
+func secretChangesPrefix(username []byte) []byte {
+	return userNamespacePrefix(username, secretsChanges)
+}
+
+// secretChangePath is ordered by the sequence number of the change
+func secretChangePath(username []byte, seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s%0*d", secretChangesPrefix(username), revisionWidth, seq))
+}
+
+func secretChangeIndexPath(username, key []byte) []byte {
+	return []byte(fmt.Sprintf("%s%s", userNamespacePrefix(username, secretsChangeIndex), key))
+}
+
+func secretsSequencePath(username []byte) []byte {
+	return userNamespacePrefix(username, secretsSequence)
+}
+
+func signingKeyPath(id string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", signingKeys, id))
+}
+
+// userNamespacePrefix is a prefix of all keys of the namespace owned by the user
+func userNamespacePrefix(username []byte, namespace string) []byte {
+	return []byte(fmt.Sprintf("%s/%s/", username, namespace))
+}